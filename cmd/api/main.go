@@ -1,20 +1,38 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"expvar"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+	_ "time/tzdata" // embed the zone database so ?tz works on images without /usr/share/zoneinfo
 
+	"github.com/cliffdoyle/task-api/internal/config"
+	"github.com/cliffdoyle/task-api/internal/database"
 	"github.com/cliffdoyle/task-api/internal/handlers"
+	"github.com/cliffdoyle/task-api/internal/jobs"
+	"github.com/cliffdoyle/task-api/internal/middleware"
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/cliffdoyle/task-api/internal/redact"
 	"github.com/cliffdoyle/task-api/internal/repository"
 	"github.com/cliffdoyle/task-api/internal/service"
+	"github.com/cliffdoyle/task-api/internal/version"
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 )
 
 func main() {
+	startedAt := time.Now()
+
 	// Load environment variables from .env file
 	// This is useful for local development; in production, variables are typically set directly.
 	err := godotenv.Load()
@@ -22,16 +40,107 @@ func main() {
 		log.Println("No .env file found, relying on environment variables.")
 	}
 
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+
+	trustedProxies, err := middleware.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Error parsing TRUSTED_PROXIES: %v", err)
+	}
+
+	timeFormat, err := handlers.ParseTimeFormat(cfg.TimeFormat)
+	if err != nil {
+		log.Fatalf("Error parsing TIME_FORMAT: %v", err)
+	}
+
+	errorFormat, err := handlers.ParseErrorFormat(cfg.ErrorFormat)
+	if err != nil {
+		log.Fatalf("Error parsing ERROR_FORMAT: %v", err)
+	}
+
+	idMode, err := handlers.ParseIDMode(cfg.IDMode)
+	if err != nil {
+		log.Fatalf("Error parsing ID_MODE: %v", err)
+	}
+
+	idFormat, err := handlers.ParseIDFormat(cfg.IDFormat)
+	if err != nil {
+		log.Fatalf("Error parsing ID_FORMAT: %v", err)
+	}
+
+	listOrder, err := models.ParseSortOrder(cfg.ListOrder)
+	if err != nil {
+		log.Fatalf("Error parsing LIST_ORDER: %v", err)
+	}
+	sortDirections, err := models.ParseSortDirections(cfg.SortDirections)
+	if err != nil {
+		log.Fatalf("Error parsing SORT_DIRECTIONS: %v", err)
+	}
+
+	eventMode, err := service.ParseEventMode(cfg.EventMode)
+	if err != nil {
+		log.Fatalf("Error parsing EVENT_MODE: %v", err)
+	}
+
+	trailingSlash, err := middleware.ParseTrailingSlashMode(cfg.TrailingSlash)
+	if err != nil {
+		log.Fatalf("Error parsing TRAILING_SLASH: %v", err)
+	}
+
+	assigneeFormat, err := service.ParseAssigneeFormat(cfg.AssigneeFormat)
+	if err != nil {
+		log.Fatalf("Error parsing ASSIGNEE_FORMAT: %v", err)
+	}
+
+	escalationStatuses, err := service.ParseEscalationStatuses(cfg.PriorityEscalationStatuses)
+	if err != nil {
+		log.Fatalf("Error parsing PRIORITY_ESCALATION_STATUSES: %v", err)
+	}
+
+	transitions := service.DefaultTransitions()
+	if cfg.StatusTransitionsFile != "" {
+		if transitions, err = service.LoadTransitions(cfg.StatusTransitionsFile); err != nil {
+			log.Fatalf("Error loading STATUS_TRANSITIONS_FILE: %v", err)
+		}
+		log.Printf("Loaded status transitions from %s", cfg.StatusTransitionsFile)
+	}
+	if err := transitions.Validate(); err != nil {
+		log.Fatalf("Invalid status transitions: %v", err)
+	}
+	for _, warning := range transitions.Warnings() {
+		log.Printf("Warning: status transitions: %s", warning)
+	}
+
+	statusLabels, err := handlers.LoadStatusLabels(cfg.StatusLabelsDir)
+	if err != nil {
+		log.Fatalf("Error loading STATUS_LABELS_DIR: %v", err)
+	}
+
+	var titleBlocklist []string
+	if cfg.TitleBlocklistFile != "" {
+		if titleBlocklist, err = service.LoadTitleBlocklist(cfg.TitleBlocklistFile); err != nil {
+			log.Fatalf("Error loading TITLE_BLOCKLIST_FILE: %v", err)
+		}
+		log.Printf("Loaded %d blocked title terms from %s", len(titleBlocklist), cfg.TitleBlocklistFile)
+	}
+	titlePolicy, err := service.NewTitlePolicy(cfg.TitleMinLength, titleBlocklist)
+	if err != nil {
+		log.Fatalf("Invalid title policy: %v", err)
+	}
+
 	// --- Database Connection ---
 	// The DATABASE_URL environment variable will be used to connect to PostgreSQL.
 	// For local development, this will point to our Dockerized PostgreSQL.
 	// For Azure, it will point to Azure SQL Database.
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		log.Fatal("DATABASE_URL environment variable not set")
+	if err := database.ValidateURL(cfg.DatabaseURL); err != nil {
+		log.Fatalf("Invalid database configuration: %v", err)
 	}
-
-	db, err := sql.Open("postgres", dbURL)
+	if err := repository.ValidateTableName(cfg.TasksTable); err != nil {
+		log.Fatalf("Invalid TASKS_TABLE: %v", err)
+	}
+	db, err := sql.Open("postgres", cfg.DatabaseURL)
 	if err != nil {
 		log.Fatalf("Error connecting to database: %v", err)
 	}
@@ -41,39 +150,226 @@ func main() {
 		}
 	}()
 
-	// Ping the database to verify the connection
-	err = db.Ping()
-	if err != nil {
-		log.Fatalf("Error pinging database: %v", err)
-	}
-	log.Println("Successfully connected to the database!")
+	log.Printf("Build %s (commit %s, built %s)", version.Version, version.Commit, version.BuildTime)
+
+	// ready is set once the database checks pass; until then /api requests get 503
+	var ready atomic.Bool
 
 	// --- Initialize Application Layers ---
-	taskRepo := repository.NewTaskRepository(db)
-	taskService := service.NewTaskService(taskRepo)
-	taskHandler := handlers.NewTaskHandler(taskService)
+	// The optional GetByID cache wraps the SQL repository; it is a no-op when TASK_CACHE_SIZE is 0
+	repoOpts := []repository.Option{repository.WithTableName(cfg.TasksTable)}
+	// redacted fields are masked in both debug logs below
+	redacted := redact.New(cfg.LogRedactFields)
+	if cfg.DebugQueries {
+		log.Printf("WARNING: DEBUG_QUERIES is enabled; every SQL statement will be logged")
+		repoOpts = append(repoOpts, repository.WithQueryHook(repository.LogQueries(log.Default(), cfg.DebugQueryArgs, redacted)))
+	}
+	taskRepo := repository.NewCachedTaskRepository(repository.NewTaskRepository(db, repoOpts...), cfg.TaskCacheSize, cfg.TaskCacheTTL)
+	defer func() {
+		// Registered after the db.Close defer so statements are released before the pool closes
+		if cerr := taskRepo.Close(); cerr != nil {
+			log.Printf("Error closing task repository: %v", cerr)
+		}
+	}()
+	serviceOpts := []service.Option{
+		service.WithLeaseDuration(cfg.ClaimLeaseDuration),
+		service.WithReopenStatus(cfg.ReopenStatus),
+		service.WithTransitions(transitions),
+		service.WithBatchLimits(cfg.MaxBatchIDs, cfg.RejectDuplicateBatchIDs),
+		service.WithAssigneeFormat(assigneeFormat),
+		service.WithRecentLimits(cfg.RecentTasksLimit, cfg.RecentTasksMaxLimit),
+		service.WithReviveOnUpdate(cfg.ReviveOnUpdate),
+		service.WithSkipNoopUpdates(cfg.SkipNoopUpdates),
+		service.WithLockCompleted(cfg.LockCompleted),
+		service.WithCaseInsensitiveStatus(cfg.StatusCaseInsensitive),
+		service.WithRequireDescription(cfg.RequireDescription),
+		service.WithTitlePolicy(titlePolicy),
+		service.WithDefaultDueDays(cfg.DefaultDueDays),
+		service.WithListMaxAge(cfg.ListMaxAge),
+		service.WithDefaultListOrder(listOrder),
+		service.WithSortDirections(sortDirections),
+		service.WithRandomSampling(cfg.RandomTaskSample),
+		service.WithMaxConcurrentTransactions(cfg.MaxConcurrentTransactions, cfg.TransactionQueueWait),
+		service.WithEscalation(escalationStatuses, cfg.PriorityEscalationAfterDays),
+		service.WithMaintenance(service.Maintenance{
+			Analyze: cfg.MaintenanceAnalyze,
+			Reindex: cfg.MaintenanceReindex,
+			Timeout: cfg.MaintenanceTimeout,
+		}),
+	}
+	var webhook *jobs.Webhook
+	if cfg.WebhookURL != "" {
+		webhook = jobs.NewWebhook(cfg.WebhookURL, cfg.WebhookTimeout)
+		serviceOpts = append(serviceOpts, service.WithEventPublisher(webhook), service.WithEventMode(eventMode))
+		log.Printf("Sending task events to %s", cfg.WebhookURL)
+	}
+	taskService := service.NewTaskService(taskRepo, serviceOpts...)
+	// Created before the handler, which resolves _links from the router's named routes
+	r := mux.NewRouter()
+
+	taskHandler := handlers.NewTaskHandler(taskService,
+		handlers.WithDecodeLimits(handlers.DecodeLimits{
+			MaxBodyBytes: cfg.MaxBodyBytes,
+			MaxDepth:     cfg.JSONMaxDepth,
+			MaxTokens:    cfg.JSONMaxTokens,
+		}),
+		handlers.WithEmptyListNoContent(cfg.EmptyListNoContent),
+		handlers.WithSummaryList(cfg.ListSummary),
+		handlers.WithCaseInsensitiveStatus(cfg.StatusCaseInsensitive),
+		handlers.WithListLimits(cfg.ListLimit, cfg.ListMaxLimit),
+		handlers.WithPutCreates(cfg.PutCreates),
+		handlers.WithAdminToken(cfg.AdminToken),
+		handlers.WithStrictParams(cfg.StrictQueryParams),
+		handlers.WithStrictBoolParams(cfg.StrictBoolParams),
+		handlers.WithStrictContentType(cfg.StrictContentType),
+		handlers.WithTimeFormat(timeFormat),
+		handlers.WithErrorFormat(errorFormat),
+		handlers.WithStatusLabels(statusLabels),
+		handlers.WithIDMode(idMode),
+		handlers.WithIDFormat(idFormat),
+		handlers.WithLinks(handlers.Links{Router: r, Default: cfg.Links, BaseURL: cfg.PublicBaseURL}),
+		handlers.WithCacheControl(handlers.CacheControl{Task: cfg.CacheControlTask, List: cfg.CacheControlList}),
+	)
 
 	// --- Setup Routes ---
-	r := mux.NewRouter()
+
+	// Tag each request with an ID (echoed as X-Request-ID) for tracing and logs
+	r.Use(middleware.RequestID)
+	// Resolve the real client IP (honouring X-Forwarded-For only from trusted proxies)
+	r.Use(middleware.ClientIP(trustedProxies))
+	// Shed load with 503s once MAX_CONCURRENT_REQUESTS are in flight; probes and metrics are exempt
+	r.Use(middleware.ConcurrencyLimit(cfg.MaxConcurrentRequests, "/health", "/debug/vars"))
+	// Per-client token bucket; after ClientIP so clients behind a trusted proxy are told apart
+	r.Use(middleware.RateLimit(cfg.RateLimitPerMinute, cfg.RateLimitBurst, "/health", "/debug/vars"))
+	if cfg.DebugBodies {
+		log.Printf("WARNING: DEBUG_BODIES is enabled; request and response bodies will be logged")
+		r.Use(middleware.DebugBodies(cfg.DebugBodiesMaxBytes, log.Default(), redacted))
+	}
+	// Reject a malformed ?links (under STRICT_BOOL_PARAMS) before a handler writes anything
+	r.Use(taskHandler.ValidateLinks)
 
 	// Task API routes
 	r.HandleFunc("/api/tasks", taskHandler.CreateTask).Methods("POST")
 	r.HandleFunc("/api/tasks", taskHandler.GetAllTasks).Methods("GET")
-	r.HandleFunc("/api/tasks/{id}", taskHandler.GetTask).Methods("GET")
+	r.HandleFunc("/api/tasks/batch-get", taskHandler.BatchGetTasks).Methods("POST")
+	r.HandleFunc("/api/tasks/changes", taskHandler.GetChanges).Methods("GET")
+	r.HandleFunc("/api/tasks/recent", taskHandler.GetRecentTasks).Methods("GET")
+	r.HandleFunc("/api/tasks/oldest-pending", taskHandler.GetOldestPendingTask).Methods("GET")
+	r.HandleFunc("/api/tasks/random", taskHandler.GetRandomTask).Methods("GET")
+	r.HandleFunc("/api/tasks/metrics/daily", taskHandler.GetDailyMetrics).Methods("GET")
+	r.HandleFunc("/api/tasks/workload", taskHandler.GetWorkload).Methods("GET")
+	r.HandleFunc("/api/tasks/calendar.ics", taskHandler.GetCalendar).Methods("GET")
+	r.HandleFunc("/api/tasks/statuses", taskHandler.GetStatuses).Methods("GET")
+	r.HandleFunc("/api/tasks/{id}", taskHandler.GetTask).Methods("GET").Name(handlers.RouteTask)
 	r.HandleFunc("/api/tasks/{id}", taskHandler.UpdateTask).Methods("PUT")
+	r.HandleFunc("/api/tasks/{id}", taskHandler.PatchTask).Methods("PATCH")
 	r.HandleFunc("/api/tasks/{id}", taskHandler.DeleteTask).Methods("DELETE")
 
-	// Health check endpoint
+	// Work-queue routes
+	r.HandleFunc("/api/tasks/claim", taskHandler.ClaimTask).Methods("POST")
+	r.HandleFunc("/api/tasks/{id}/release", taskHandler.ReleaseTask).Methods("POST")
+	r.HandleFunc("/api/tasks/{id}/reopen", taskHandler.ReopenTask).Methods("POST")
+	r.HandleFunc("/api/tasks/{id}/assign", taskHandler.AssignTask).Methods("POST")
+	r.HandleFunc("/api/tasks/reassign", taskHandler.ReassignTasks).Methods("POST")
+	r.HandleFunc("/api/tasks/{id}/transition", taskHandler.TransitionTask).Methods("POST")
+	r.HandleFunc("/api/tasks/{id}/snooze", taskHandler.SnoozeTask).Methods("POST")
+	r.HandleFunc("/api/tasks/{id}/next-allowed-transitions", taskHandler.GetNextTransitions).Methods("GET").Name(handlers.RouteTaskTransitions)
+
+	// Template routes
+	r.HandleFunc("/api/templates", taskHandler.CreateTemplate).Methods("POST")
+	r.HandleFunc("/api/templates", taskHandler.ListTemplates).Methods("GET")
+	r.HandleFunc("/api/templates/{id}", taskHandler.GetTemplate).Methods("GET")
+	r.HandleFunc("/api/templates/{id}", taskHandler.DeleteTemplate).Methods("DELETE")
+	r.HandleFunc("/api/tasks/from-template/{templateId}", taskHandler.CreateTaskFromTemplate).Methods("POST")
+
+	// Admin routes, for the ADMIN_TOKEN bearer only
+	r.HandleFunc("/admin/maintenance", taskHandler.RunMaintenance).Methods("POST")
+
+	// Health check endpoints
+	healthHandler := handlers.NewHealthHandler(startedAt)
 	r.HandleFunc("/health", healthCheck).Methods("GET")
+	r.HandleFunc("/health/live", healthCheck).Methods("GET")
+	r.HandleFunc("/health/ready", handlers.ReadyCheck(&ready, cfg.ReadinessRetryAfter)).Methods("GET")
+	r.HandleFunc("/health/info", healthHandler.Info).Methods("GET")
+
+	// Runtime and application counters (expvar JSON)
+	r.Handle("/debug/vars", expvar.Handler()).Methods("GET")
+
+	// JSON errors for unknown routes and unsupported methods
+	r.NotFoundHandler = handlers.NotFoundHandler()
+	r.MethodNotAllowedHandler = handlers.MethodNotAllowedHandler(r)
 
 	// --- Start HTTP Server ---
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080" // Default port if not specified in environment
+	// Trailing slashes and readiness are handled before routing, so these wrap the router rather
+	// than using r.Use. /api and /admin answer 503 until the database checks below pass.
+	handler := middleware.RequireReady(&ready, cfg.ReadinessRetryAfter, "/api", "/admin")(r)
+	srv := &http.Server{Addr: ":" + cfg.Port, Handler: middleware.TrailingSlash(trailingSlash)(handler)}
+
+	// Stop on SIGINT/SIGTERM so the deferred cleanup above actually runs
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		log.Printf("Server starting on port %s...", cfg.Port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Server error: %v", err)
+			stop()
+		}
+	}()
+
+	// Wait for the database, which may still be starting when the container comes up
+	if err := database.WaitForDB(ctx, db, cfg.DBConnectAttempts, cfg.DBConnectBackoff, log.Default()); err != nil {
+		log.Fatalf("Error pinging database: %v", err)
+	}
+	log.Println("Successfully connected to the database!")
+
+	// Refuse to serve traffic against an unmigrated database
+	if cfg.SchemaCheck {
+		if err := database.CheckSchema(ctx, db, cfg.TasksTable); err != nil {
+			log.Fatalf("Schema check failed: %v", err)
+		}
+		log.Println("Schema check passed")
+	}
+	ready.Store(true)
+	log.Println("Server is ready")
+
+	// --- Background Jobs ---
+	var jobsDone sync.WaitGroup
+	if cfg.LeaseReaperInterval > 0 {
+		reaper := jobs.NewLeaseReaper(taskService, cfg.LeaseReaperInterval)
+		jobsDone.Add(1)
+		go func() {
+			defer jobsDone.Done()
+			reaper.Run(ctx)
+		}()
+	}
+	if cfg.PriorityEscalationInterval > 0 {
+		escalator := jobs.NewPriorityEscalator(taskService, cfg.PriorityEscalationInterval)
+		jobsDone.Add(1)
+		go func() {
+			defer jobsDone.Done()
+			escalator.Run(ctx)
+		}()
+	}
+	if webhook != nil {
+		jobsDone.Add(1)
+		go func() {
+			defer jobsDone.Done()
+			webhook.Run(ctx)
+		}()
+	}
+
+	<-ctx.Done()
+	log.Println("Shutting down server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error during server shutdown: %v", err)
 	}
 
-	log.Printf("Server starting on port %s...", port)
-	log.Fatal(http.ListenAndServe(":"+port, r)) // Use log.Fatal to gracefully exit on server error
+	// Let any in-flight job pass finish before the repository and database are closed
+	jobsDone.Wait()
 }
 
 // healthCheck handler for basic service availability
@@ -0,0 +1,494 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the application settings read from the environment
+type Config struct {
+	DatabaseURL string
+	Port        string
+
+	// TasksTable names the table tasks are stored in, so the API can share a schema with an
+	// application that already has a tasks table
+	TasksTable string
+
+	// TrustedProxies lists the CIDRs (or bare IPs) of proxies whose
+	// X-Forwarded-For / X-Real-IP headers are honoured when resolving the client IP.
+	TrustedProxies []string
+
+	// Limits applied when decoding JSON request bodies; 0 disables a limit
+	MaxBodyBytes  int64
+	JSONMaxDepth  int
+	JSONMaxTokens int
+
+	// GetByID cache; a size of 0 disables it
+	TaskCacheSize int
+	TaskCacheTTL  time.Duration
+
+	// EmptyListNoContent makes GET /api/tasks answer an empty result with 204 instead of 200 []
+	EmptyListNoContent bool
+
+	// ListSummary makes GET /api/tasks return trimmed summaries unless ?full=true
+	ListSummary bool
+
+	// StrictQueryParams rejects unknown query parameters on GET /api/tasks
+	StrictQueryParams bool
+
+	// StrictBoolParams rejects boolean query values other than true/false/1/0/yes/no with 400;
+	// when false they are read as false
+	StrictBoolParams bool
+
+	// StrictContentType rejects request bodies not sent as application/json with 415
+	StrictContentType bool
+
+	// ClaimLeaseDuration is how long POST /api/tasks/claim holds a task for its worker
+	ClaimLeaseDuration time.Duration
+
+	// Links adds HATEOAS _links to task responses by default; PublicBaseURL, when set, is the
+	// scheme and host used for their absolute URLs
+	Links         bool
+	PublicBaseURL string
+
+	// ReviveOnUpdate lets PUT and PATCH undelete a soft-deleted task instead of returning 404
+	ReviveOnUpdate bool
+
+	// SkipNoopUpdates makes a PUT or PATCH that changes nothing return the task without writing
+	// it, so updated_at is kept and no event is sent
+	SkipNoopUpdates bool
+
+	// PutCreates makes PUT on a missing task ID create the task with that ID instead of returning 404
+	PutCreates bool
+
+	// AdminToken is the bearer token for admin-only requests such as listing deleted tasks; empty
+	// disables them
+	AdminToken string
+
+	// MaintenanceAnalyze and MaintenanceReindex enable those operations of POST
+	// /admin/maintenance; with both off it refuses every request. A run is cancelled after
+	// MaintenanceTimeout.
+	MaintenanceAnalyze bool
+	MaintenanceReindex bool
+	MaintenanceTimeout time.Duration
+
+	// TitleMinLength is the minimum title length in characters; 0 disables the check
+	TitleMinLength int
+
+	// TitleBlocklistFile is an optional file of terms titles must not contain, one per line
+	TitleBlocklistFile string
+
+	// RequireDescription rejects tasks created without a description and updates that clear it
+	RequireDescription bool
+
+	// StatusCaseInsensitive accepts statuses in any case in request bodies and the ?status= filter,
+	// normalising them to lowercase
+	StatusCaseInsensitive bool
+
+	// LockCompleted makes PUT and PATCH return 409 for edits to a completed task, other than
+	// changing its status
+	LockCompleted bool
+
+	// ListLimit caps GET /api/tasks when no ?limit is given, and ListMaxLimit caps ?limit; zero
+	// turns the respective cap off
+	ListLimit    int
+	ListMaxLimit int
+
+	// RecentTasksLimit and RecentTasksMaxLimit are the default and largest ?limit for
+	// GET /api/tasks/recent
+	RecentTasksLimit    int
+	RecentTasksMaxLimit int
+
+	// CacheControlTask and CacheControlList are the Cache-Control headers for GET /api/tasks/{id}
+	// and GET /api/tasks
+	CacheControlTask string
+	CacheControlList string
+
+	// MaxBatchIDs caps the IDs in one batch request; RejectDuplicateBatchIDs turns repeated
+	// IDs into a 400 instead of ignoring the repeats
+	MaxBatchIDs             int
+	RejectDuplicateBatchIDs bool
+
+	// IDMode is how tasks are identified in URLs and responses: int or uuid
+	IDMode string
+
+	// IDFormat is how integer IDs are written in responses: number or string
+	IDFormat string
+
+	// AssigneeFormat is what an assignee must look like: username, email or numeric
+	AssigneeFormat string
+
+	// TimeFormat is the default timestamp format in responses: rfc3339, rfc3339nano or unix
+	TimeFormat string
+
+	// ErrorFormat is how error responses are written: text or json
+	ErrorFormat string
+
+	// TrailingSlash is how paths ending in "/" are handled: strip, redirect or strict
+	TrailingSlash string
+
+	// ReopenStatus is the status POST /api/tasks/{id}/reopen returns a task to
+	ReopenStatus string
+
+	// ListMaxAge limits GET /api/tasks without date filters or ?all=true to tasks created within
+	// it; zero lists tasks of any age
+	ListMaxAge time.Duration
+
+	// ListOrder is the creation-time order of GET /api/tasks when no ?sort= is given: desc
+	// (newest first) or asc (oldest first)
+	ListOrder string
+
+	// SortDirections overrides the direction ?sort=field lists a field in when the request
+	// doesn't give one, as field=asc or field=desc pairs
+	SortDirections []string
+
+	// RandomTaskSample makes GET /api/tasks/random sample from a random ID instead of shuffling
+	// every match; faster on large tables, but not uniform
+	RandomTaskSample bool
+
+	// DefaultDueDays gives new tasks without a due_date one this many days out; zero disables it
+	DefaultDueDays int
+
+	// StatusTransitionsFile is an optional JSON file with the allowed status transitions;
+	// when empty the built-in workflow is used
+	StatusTransitionsFile string
+
+	// StatusLabelsDir is an optional directory of <language>.json files adding to, or
+	// overriding, the built-in status label translations
+	StatusLabelsDir string
+
+	// DebugBodies logs request and response bodies (truncated to DebugBodiesMaxBytes).
+	// Only for diagnosing client issues; never enable it by default.
+	DebugBodies         bool
+	DebugBodiesMaxBytes int
+
+	// DebugQueries logs every SQL statement with its duration; argument values are only
+	// included when DebugQueryArgs is also set
+	DebugQueries   bool
+	DebugQueryArgs bool
+
+	// LogRedactFields names task fields (JSON keys, including metadata keys, and columns) whose
+	// values are logged as *** by DebugBodies and DebugQueries. Empty redacts nothing.
+	LogRedactFields []string
+
+	// MaxConcurrentRequests caps how many API requests are served at once; excess requests get
+	// 503. Zero disables the limit.
+	MaxConcurrentRequests int
+
+	// MaxConcurrentTransactions caps how many transactional writes run at once, separately from
+	// MaxConcurrentRequests; one arriving at the limit waits up to TransactionQueueWait for a
+	// slot, then gets 503. Zero disables the limit.
+	MaxConcurrentTransactions int
+	TransactionQueueWait      time.Duration
+
+	// RateLimitPerMinute caps requests per client IP per minute, with bursts of up to
+	// RateLimitBurst; excess requests get 429. Zero disables the limit.
+	RateLimitPerMinute int
+	RateLimitBurst     int
+
+	// DBConnectAttempts and DBConnectBackoff control how long startup waits for the database:
+	// up to DBConnectAttempts pings, with the delay between them doubling from DBConnectBackoff
+	DBConnectAttempts int
+	DBConnectBackoff  time.Duration
+
+	// ReadinessRetryAfter is the Retry-After, in seconds, sent with the 503s answered before the
+	// server is ready, from /health/ready and the gated routes alike; 0 leaves it off
+	ReadinessRetryAfter int
+	// SchemaCheck verifies at startup that the tasks table has every column the API uses
+	SchemaCheck bool
+
+	// WebhookURL receives a POST for every task create, update and delete; empty disables it
+	WebhookURL     string
+	WebhookTimeout time.Duration
+	// EventMode is "item" for one event per task in bulk operations, or "batch" for one event
+	// holding them all
+	EventMode string
+
+	// LeaseReaperInterval is how often expired leases are returned to the queue; 0 disables the reaper
+	LeaseReaperInterval time.Duration
+	// PriorityEscalationInterval is how often overdue tasks have their priority raised; 0 disables it.
+	// Only tasks in one of PriorityEscalationStatuses more than PriorityEscalationAfterDays past
+	// their due date are raised.
+	PriorityEscalationInterval  time.Duration
+	PriorityEscalationStatuses  []string
+	PriorityEscalationAfterDays int
+}
+
+// Load reads the configuration from environment variables, applying defaults where sensible
+func Load() (*Config, error) {
+	cfg := &Config{
+		DatabaseURL:    os.Getenv("DATABASE_URL"),
+		Port:           getEnv("PORT", "8080"),
+		TasksTable:     getEnv("TASKS_TABLE", "tasks"),
+		TrustedProxies: getList("TRUSTED_PROXIES"),
+	}
+
+	var err error
+	if cfg.MaxBodyBytes, err = getInt64("MAX_BODY_BYTES", 1<<20); err != nil {
+		return nil, err
+	}
+	if cfg.JSONMaxDepth, err = getInt("JSON_MAX_DEPTH", 32); err != nil {
+		return nil, err
+	}
+	if cfg.JSONMaxTokens, err = getInt("JSON_MAX_TOKENS", 10000); err != nil {
+		return nil, err
+	}
+
+	if cfg.TaskCacheSize, err = getInt("TASK_CACHE_SIZE", 0); err != nil {
+		return nil, err
+	}
+	if cfg.TaskCacheTTL, err = getDuration("TASK_CACHE_TTL", 30*time.Second); err != nil {
+		return nil, err
+	}
+
+	if cfg.EmptyListNoContent, err = getBool("EMPTY_LIST_NO_CONTENT", false); err != nil {
+		return nil, err
+	}
+
+	if cfg.ListSummary, err = getBool("LIST_SUMMARY", true); err != nil {
+		return nil, err
+	}
+
+	if cfg.StrictQueryParams, err = getBool("STRICT_QUERY_PARAMS", false); err != nil {
+		return nil, err
+	}
+	if cfg.StrictBoolParams, err = getBool("STRICT_BOOL_PARAMS", true); err != nil {
+		return nil, err
+	}
+	if cfg.StrictContentType, err = getBool("STRICT_CONTENT_TYPE", false); err != nil {
+		return nil, err
+	}
+	if cfg.ClaimLeaseDuration, err = getDuration("CLAIM_LEASE_DURATION", 5*time.Minute); err != nil {
+		return nil, err
+	}
+
+	if cfg.Links, err = getBool("LINKS", false); err != nil {
+		return nil, err
+	}
+	cfg.PublicBaseURL = os.Getenv("PUBLIC_BASE_URL")
+	if cfg.ReviveOnUpdate, err = getBool("REVIVE_ON_UPDATE", false); err != nil {
+		return nil, err
+	}
+	if cfg.SkipNoopUpdates, err = getBool("SKIP_NOOP_UPDATES", true); err != nil {
+		return nil, err
+	}
+	if cfg.LockCompleted, err = getBool("LOCK_COMPLETED", false); err != nil {
+		return nil, err
+	}
+	if cfg.PutCreates, err = getBool("PUT_CREATES", false); err != nil {
+		return nil, err
+	}
+	if cfg.StatusCaseInsensitive, err = getBool("STATUS_CASE_INSENSITIVE", false); err != nil {
+		return nil, err
+	}
+	if cfg.RequireDescription, err = getBool("REQUIRE_DESCRIPTION", false); err != nil {
+		return nil, err
+	}
+	if cfg.ListLimit, err = getInt("LIST_LIMIT", 1000); err != nil {
+		return nil, err
+	}
+	if cfg.ListMaxLimit, err = getInt("LIST_MAX_LIMIT", 5000); err != nil {
+		return nil, err
+	}
+	if cfg.RecentTasksLimit, err = getInt("RECENT_TASKS_LIMIT", 10); err != nil {
+		return nil, err
+	}
+	if cfg.RecentTasksMaxLimit, err = getInt("RECENT_TASKS_MAX_LIMIT", 100); err != nil {
+		return nil, err
+	}
+	cfg.CacheControlTask = getEnv("CACHE_CONTROL_TASK", "no-store")
+	cfg.CacheControlList = getEnv("CACHE_CONTROL_LIST", "no-store")
+
+	if cfg.MaxBatchIDs, err = getInt("MAX_BATCH_IDS", 100); err != nil {
+		return nil, err
+	}
+	if cfg.RejectDuplicateBatchIDs, err = getBool("REJECT_DUPLICATE_BATCH_IDS", false); err != nil {
+		return nil, err
+	}
+
+	cfg.IDMode = getEnv("ID_MODE", "int")
+	cfg.IDFormat = getEnv("ID_FORMAT", "number")
+	cfg.AssigneeFormat = getEnv("ASSIGNEE_FORMAT", "username")
+	cfg.TimeFormat = getEnv("TIME_FORMAT", "rfc3339")
+	cfg.ErrorFormat = getEnv("ERROR_FORMAT", "text")
+	cfg.TrailingSlash = getEnv("TRAILING_SLASH", "strip")
+
+	cfg.ReopenStatus = getEnv("REOPEN_STATUS", "in_progress")
+	cfg.AdminToken = getEnv("ADMIN_TOKEN", "")
+	if cfg.MaintenanceAnalyze, err = getBool("MAINTENANCE_ANALYZE", false); err != nil {
+		return nil, err
+	}
+	if cfg.MaintenanceReindex, err = getBool("MAINTENANCE_REINDEX", false); err != nil {
+		return nil, err
+	}
+	if cfg.MaintenanceTimeout, err = getDuration("MAINTENANCE_TIMEOUT", 10*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.ReopenStatus != "pending" && cfg.ReopenStatus != "in_progress" {
+		return nil, fmt.Errorf("REOPEN_STATUS must be pending or in_progress, got %q", cfg.ReopenStatus)
+	}
+
+	if cfg.DefaultDueDays, err = getInt("DEFAULT_DUE_DAYS", 0); err != nil {
+		return nil, err
+	}
+	if cfg.TitleMinLength, err = getInt("TITLE_MIN_LENGTH", 0); err != nil {
+		return nil, err
+	}
+	cfg.ListOrder = getEnv("LIST_ORDER", "desc")
+	cfg.SortDirections = getList("SORT_DIRECTIONS")
+	if cfg.RandomTaskSample, err = getBool("RANDOM_TASK_SAMPLE", false); err != nil {
+		return nil, err
+	}
+	if cfg.ListMaxAge, err = getDuration("LIST_MAX_AGE", 0); err != nil {
+		return nil, err
+	}
+
+	cfg.StatusTransitionsFile = os.Getenv("STATUS_TRANSITIONS_FILE")
+	cfg.StatusLabelsDir = os.Getenv("STATUS_LABELS_DIR")
+	cfg.TitleBlocklistFile = os.Getenv("TITLE_BLOCKLIST_FILE")
+
+	if cfg.DebugBodies, err = getBool("DEBUG_BODIES", false); err != nil {
+		return nil, err
+	}
+	if cfg.DebugBodiesMaxBytes, err = getInt("DEBUG_BODIES_MAX_BYTES", 2048); err != nil {
+		return nil, err
+	}
+	if cfg.DebugQueries, err = getBool("DEBUG_QUERIES", false); err != nil {
+		return nil, err
+	}
+	if cfg.DebugQueryArgs, err = getBool("DEBUG_QUERY_ARGS", false); err != nil {
+		return nil, err
+	}
+	cfg.LogRedactFields = getList("LOG_REDACT_FIELDS")
+
+	if cfg.MaxConcurrentRequests, err = getInt("MAX_CONCURRENT_REQUESTS", 0); err != nil {
+		return nil, err
+	}
+	if cfg.MaxConcurrentTransactions, err = getInt("MAX_CONCURRENT_TRANSACTIONS", 0); err != nil {
+		return nil, err
+	}
+	if cfg.TransactionQueueWait, err = getDuration("TRANSACTION_QUEUE_WAIT", 0); err != nil {
+		return nil, err
+	}
+	if cfg.RateLimitPerMinute, err = getInt("RATE_LIMIT_PER_MINUTE", 0); err != nil {
+		return nil, err
+	}
+	if cfg.RateLimitBurst, err = getInt("RATE_LIMIT_BURST", 20); err != nil {
+		return nil, err
+	}
+
+	if cfg.DBConnectAttempts, err = getInt("DB_CONNECT_ATTEMPTS", 10); err != nil {
+		return nil, err
+	}
+	if cfg.DBConnectBackoff, err = getDuration("DB_CONNECT_BACKOFF", time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.ReadinessRetryAfter, err = getInt("READINESS_RETRY_AFTER", 2); err != nil {
+		return nil, err
+	}
+
+	if cfg.SchemaCheck, err = getBool("SCHEMA_CHECK", true); err != nil {
+		return nil, err
+	}
+
+	cfg.WebhookURL = os.Getenv("WEBHOOK_URL")
+	cfg.EventMode = getEnv("EVENT_MODE", "item")
+	if cfg.WebhookTimeout, err = getDuration("WEBHOOK_TIMEOUT", 5*time.Second); err != nil {
+		return nil, err
+	}
+
+	if cfg.LeaseReaperInterval, err = getDuration("LEASE_REAPER_INTERVAL", 30*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.PriorityEscalationInterval, err = getDuration("PRIORITY_ESCALATION_INTERVAL", 0); err != nil {
+		return nil, err
+	}
+	cfg.PriorityEscalationStatuses = getList("PRIORITY_ESCALATION_STATUSES")
+	if cfg.PriorityEscalationAfterDays, err = getInt("PRIORITY_ESCALATION_AFTER_DAYS", 0); err != nil {
+		return nil, err
+	}
+	if cfg.PriorityEscalationAfterDays < 0 {
+		return nil, fmt.Errorf("PRIORITY_ESCALATION_AFTER_DAYS must not be negative, got %d", cfg.PriorityEscalationAfterDays)
+	}
+
+	if cfg.DatabaseURL == "" {
+		return nil, errors.New("DATABASE_URL environment variable not set")
+	}
+
+	return cfg, nil
+}
+
+// getEnv returns the value of the environment variable or the fallback if it is unset or empty
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// getInt parses an integer environment variable, returning the fallback if it is unset
+func getInt(key string, fallback int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got %q", key, v)
+	}
+	return n, nil
+}
+
+// getInt64 is the int64 counterpart of getInt
+func getInt64(key string, fallback int64) (int64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got %q", key, v)
+	}
+	return n, nil
+}
+
+// getBool parses a boolean environment variable, returning the fallback if it is unset
+func getBool(key string, fallback bool) (bool, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean, got %q", key, v)
+	}
+	return b, nil
+}
+
+// getDuration parses a duration environment variable (e.g. "30s"), returning the fallback if it is unset
+func getDuration(key string, fallback time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative duration, got %q", key, v)
+	}
+	return d, nil
+}
+
+// getList splits a comma-separated environment variable into its trimmed, non-empty parts
+func getList(key string) []string {
+	var out []string
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// requiredColumns lists every column of the tasks table the repository reads or writes. Keep it in step with
+// scripts/setup-db.sh when a migration adds a column.
+var requiredColumns = []string{
+	"id", "title", "description", "status", "metadata", "created_at", "updated_at",
+	"claimed_by", "lease_expires_at", "deleted_at", "completed_at", "reopen_reason",
+	"assignee", "due_date", "uuid", "started_at", "priority",
+}
+
+const schemaColumnsQuery = `
+	SELECT column_name FROM information_schema.columns
+	WHERE table_schema = current_schema() AND table_name = $1`
+
+// CheckSchema verifies that the tasks table (named table, see TASKS_TABLE) exists and has every
+// column the API needs, so a deployment that skipped scripts/setup-db.sh fails at startup rather
+// than on its first request
+func CheckSchema(ctx context.Context, db *sql.DB, table string) error {
+	rows, err := db.QueryContext(ctx, schemaColumnsQuery, table)
+	if err != nil {
+		return fmt.Errorf("failed to read tasks schema: %w", err)
+	}
+	defer rows.Close()
+
+	found := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return fmt.Errorf("failed to read tasks schema: %w", err)
+		}
+		found[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read tasks schema: %w", err)
+	}
+	return compareColumns(table, found)
+}
+
+// compareColumns reports which required columns are absent from found
+func compareColumns(table string, found map[string]bool) error {
+	if len(found) == 0 {
+		return fmt.Errorf("table %s does not exist; run scripts/setup-db.sh", table)
+	}
+	var missing []string
+	for _, col := range requiredColumns {
+		if !found[col] {
+			missing = append(missing, col)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("table %s is missing columns %s; run scripts/setup-db.sh", table, strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareColumns(t *testing.T) {
+	all := map[string]bool{}
+	for _, col := range requiredColumns {
+		all[col] = true
+	}
+	all["legacy_notes"] = true // extra columns are fine
+	assert.NoError(t, compareColumns("tasks", all))
+
+	delete(all, "due_date")
+	delete(all, "uuid")
+	assert.EqualError(t, compareColumns("tasks", all), "table tasks is missing columns due_date, uuid; run scripts/setup-db.sh")
+
+	assert.EqualError(t, compareColumns("tasks", map[string]bool{}), "table tasks does not exist; run scripts/setup-db.sh")
+	assert.EqualError(t, compareColumns("todo_tasks", map[string]bool{}), "table todo_tasks does not exist; run scripts/setup-db.sh")
+}
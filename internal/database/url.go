@@ -0,0 +1,38 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SupportedSchemes lists the DATABASE_URL schemes the API can connect with
+var SupportedSchemes = []string{"postgres", "postgresql"}
+
+// ValidateURL checks that a DATABASE_URL is a well-formed URL with a supported scheme, so a
+// mistyped or foreign URL (mysql://...) fails at startup instead of on the first query.
+// lib/pq's key=value form ("host=localhost dbname=tasks") has no scheme and is accepted as is.
+// Errors never repeat the URL, which usually holds a password.
+func ValidateURL(raw string) error {
+	if !strings.Contains(raw, "://") && strings.Contains(raw, "=") {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("DATABASE_URL is not a valid URL: %v", err)
+	}
+	if u.Scheme == "" {
+		return fmt.Errorf("DATABASE_URL has no scheme (supported: %s)", strings.Join(SupportedSchemes, ", "))
+	}
+	for _, scheme := range SupportedSchemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return nil
+		}
+	}
+	return fmt.Errorf("DATABASE_URL scheme %q is not supported (supported: %s)", u.Scheme, strings.Join(SupportedSchemes, ", "))
+}
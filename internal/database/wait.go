@@ -0,0 +1,49 @@
+// Package database holds startup checks run against the database before the API serves traffic
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// maxWaitBackoff caps the delay between connection attempts
+const maxWaitBackoff = 30 * time.Second
+
+// Pinger is satisfied by *sql.DB
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// WaitForDB pings db up to attempts times, doubling the delay between attempts from backoff
+// (capped at 30s), and returns nil as soon as a ping succeeds. It gives up with the last ping
+// error once attempts are exhausted or ctx is cancelled. Each failed attempt is logged.
+func WaitForDB(ctx context.Context, db Pinger, attempts int, backoff time.Duration, logger *log.Logger) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	delay := backoff
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = db.PingContext(ctx); err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+		logger.Printf("Database not ready (attempt %d/%d): %v; retrying in %s", attempt, attempts, err, delay)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("gave up waiting for database: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > maxWaitBackoff {
+			delay = maxWaitBackoff
+		}
+	}
+	return fmt.Errorf("database not ready after %d attempts: %w", attempts, err)
+}
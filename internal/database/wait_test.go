@@ -0,0 +1,58 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePinger fails the first `failures` pings, then succeeds
+type fakePinger struct {
+	failures int
+	calls    int
+}
+
+func (f *fakePinger) PingContext(ctx context.Context) error {
+	f.calls++
+	if f.calls <= f.failures {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestWaitForDB_SucceedsAfterRetries(t *testing.T) {
+	var logs bytes.Buffer
+	db := &fakePinger{failures: 2}
+
+	err := WaitForDB(context.Background(), db, 5, time.Millisecond, log.New(&logs, "", 0))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, db.calls)
+	assert.Contains(t, logs.String(), "attempt 1/5")
+	assert.Contains(t, logs.String(), "attempt 2/5")
+}
+
+func TestWaitForDB_GivesUp(t *testing.T) {
+	db := &fakePinger{failures: 10}
+
+	err := WaitForDB(context.Background(), db, 3, time.Millisecond, log.New(&bytes.Buffer{}, "", 0))
+
+	assert.EqualError(t, err, "database not ready after 3 attempts: connection refused")
+	assert.Equal(t, 3, db.calls)
+}
+
+func TestWaitForDB_StopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	db := &fakePinger{failures: 10}
+
+	err := WaitForDB(ctx, db, 5, time.Hour, log.New(&bytes.Buffer{}, "", 0))
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, db.calls)
+}
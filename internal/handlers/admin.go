@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+)
+
+// includeDeletedParam asks the list endpoint for soft-deleted tasks too; only admins get them
+const includeDeletedParam = "include_deleted"
+
+// WithAdminToken sets the bearer token that marks a request as coming from an admin. Admin-only
+// behaviour (such as ?include_deleted=true) is unavailable while the token is empty.
+func WithAdminToken(token string) Option {
+	return func(h *TaskHandler) {
+		h.adminToken = token
+	}
+}
+
+// isAdmin reports whether r carries the admin token as "Authorization: Bearer <token>"
+func (h *TaskHandler) isAdmin(r *http.Request) bool {
+	if h.adminToken == "" {
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) == 1
+}
+
+// RunMaintenance handles POST /admin/maintenance, running database maintenance such as ANALYZE
+// on the tasks table and reporting how long it took. It is for admins only; anyone else gets 401
+// whether or not maintenance is enabled. The body is optional (see models.MaintenanceRequest).
+func (h *TaskHandler) RunMaintenance(w http.ResponseWriter, r *http.Request) {
+	if !h.isAdmin(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		h.writeCodedError(w, http.StatusUnauthorized, CodeAdminRequired, "admin token required")
+		return
+	}
+
+	var req models.MaintenanceRequest
+	if err := h.decodeJSON(w, r, &req); err != nil && !errors.Is(err, errEmptyBody) {
+		h.writeDecodeError(w, err)
+		return
+	}
+
+	report, err := h.service.RunMaintenance(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, "run maintenance", err)
+		return
+	}
+
+	h.respond(w, r, http.StatusOK, report)
+}
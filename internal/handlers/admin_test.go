@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/cliffdoyle/task-api/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIsAdmin(t *testing.T) {
+	tests := map[string]struct {
+		token  string
+		header string
+		want   bool
+	}{
+		"matching token":    {"s3cret", "Bearer s3cret", true},
+		"wrong token":       {"s3cret", "Bearer guess", false},
+		"no header":         {"s3cret", "", false},
+		"not bearer":        {"s3cret", "Basic s3cret", false},
+		"no token set":      {"", "Bearer ", false},
+		"token is a prefix": {"s3cret", "Bearer s3cret2", false},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := NewTaskHandler(new(MockTaskService), WithAdminToken(tc.token))
+			req := httptest.NewRequest("GET", "/api/tasks", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+
+			assert.Equal(t, tc.want, h.isAdmin(req))
+		})
+	}
+}
+
+func TestGetAllTasks_IncludeDeletedAdmin(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService, WithAdminToken("s3cret"), WithSummaryList(true))
+	deletedAt := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	mockService.On("GetAllTasks", models.ListFilter{IncludeDeleted: true}).Return([]*models.Task{
+		{ID: 2, Title: "Gone", Status: "pending", DeletedAt: &deletedAt, Deleted: true},
+		{ID: 1, Title: "Here", Status: "pending"},
+	}, nil)
+	req := httptest.NewRequest("GET", "/api/tasks?include_deleted=true&strict_params=true", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.GetAllTasks(rr, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"deleted_at":"2024-05-01T09:00:00Z","deleted":true`)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAllTasks_IncludeDeletedIgnoredForNonAdmins(t *testing.T) {
+	tests := map[string]struct {
+		token  string
+		header string
+	}{
+		"no credentials":    {"s3cret", ""},
+		"wrong token":       {"s3cret", "Bearer guess"},
+		"admin not enabled": {"", "Bearer "},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockTaskService)
+			h := NewTaskHandler(mockService, WithAdminToken(tc.token))
+			mockService.On("GetAllTasks", models.ListFilter{}).Return([]*models.Task{{ID: 1, Title: "Here"}}, nil)
+			req := httptest.NewRequest("GET", "/api/tasks?include_deleted=true", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+
+			// Act
+			rr := httptest.NewRecorder()
+			h.GetAllTasks(rr, req)
+
+			// Assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetAllTasks_IncludeDeletedInvalid(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService, WithAdminToken("s3cret"))
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.GetAllTasks(rr, httptest.NewRequest("GET", "/api/tasks?include_deleted=maybe", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNotCalled(t, "GetAllTasks", mock.Anything)
+}
+
+func TestRunMaintenance(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService, WithAdminToken("s3cret"))
+	report := &models.MaintenanceReport{
+		Results:    []models.MaintenanceResult{{Operation: "reindex", DurationMS: 40}, {Operation: "analyze", DurationMS: 12}},
+		DurationMS: 52,
+	}
+	mockService.On("RunMaintenance", mock.Anything, &models.MaintenanceRequest{Operations: []string{"reindex", "analyze"}}).Return(report, nil)
+	req := httptest.NewRequest("POST", "/admin/maintenance", strings.NewReader(`{"operations":["reindex","analyze"]}`))
+	req.Header.Set("Authorization", "Bearer s3cret")
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.RunMaintenance(rr, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"results":[{"operation":"reindex","duration_ms":40},{"operation":"analyze","duration_ms":12}],"duration_ms":52}`, rr.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestRunMaintenance_EmptyBodyRunsEnabled(t *testing.T) {
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService, WithAdminToken("s3cret"))
+	mockService.On("RunMaintenance", mock.Anything, &models.MaintenanceRequest{}).Return(&models.MaintenanceReport{}, nil)
+	req := httptest.NewRequest("POST", "/admin/maintenance", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+
+	rr := httptest.NewRecorder()
+	h.RunMaintenance(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestRunMaintenance_Errors(t *testing.T) {
+	tests := map[string]struct {
+		header     string
+		body       string
+		serviceErr error
+		wantStatus int
+		wantCode   ErrorCode
+	}{
+		"no token":          {header: "", wantStatus: http.StatusUnauthorized, wantCode: CodeAdminRequired},
+		"wrong token":       {header: "Bearer guess", wantStatus: http.StatusUnauthorized, wantCode: CodeAdminRequired},
+		"bad body":          {header: "Bearer s3cret", body: `{"operations":"analyze"}`, wantStatus: http.StatusBadRequest, wantCode: CodeInvalidBody},
+		"disabled":          {header: "Bearer s3cret", serviceErr: service.ErrMaintenanceDisabled, wantStatus: http.StatusForbidden, wantCode: CodeMaintenanceDisabled},
+		"unknown operation": {header: "Bearer s3cret", serviceErr: service.ErrInvalidMaintenance, wantStatus: http.StatusBadRequest, wantCode: CodeInvalidMaintenance},
+		"timed out":         {header: "Bearer s3cret", serviceErr: context.DeadlineExceeded, wantStatus: http.StatusGatewayTimeout, wantCode: CodeTimeout},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockService := new(MockTaskService)
+			h := NewTaskHandler(mockService, WithAdminToken("s3cret"))
+			if tc.serviceErr != nil {
+				mockService.On("RunMaintenance", mock.Anything, mock.Anything).Return(nil, tc.serviceErr)
+			}
+			req := httptest.NewRequest("POST", "/admin/maintenance", strings.NewReader(tc.body))
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+
+			rr := httptest.NewRecorder()
+			h.RunMaintenance(rr, req)
+
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, string(tc.wantCode), rr.Header().Get(ErrorCodeHeader))
+			if tc.wantStatus == http.StatusUnauthorized {
+				assert.Equal(t, "Bearer", rr.Header().Get("WWW-Authenticate"))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
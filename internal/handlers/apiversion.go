@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+)
+
+// APIVersionHeader lets a client declare which request body schema it is sending. The version
+// used is echoed back in the response header of the same name.
+const APIVersionHeader = "X-Api-Version"
+
+// LatestAPIVersion is assumed when a request has no X-Api-Version header
+const LatestAPIVersion = "1"
+
+// bodyVersion decodes the create and update bodies of one schema version into the internal
+// request models
+type bodyVersion struct {
+	create func(h *TaskHandler, w http.ResponseWriter, r *http.Request) (*models.CreateTaskRequest, error)
+	update func(h *TaskHandler, w http.ResponseWriter, r *http.Request) (*models.UpdateTaskRequest, error)
+}
+
+// bodyVersions holds every request body schema still accepted. A breaking change to a body adds
+// a new version with its own request structs and a mapping onto the models, leaving the old
+// version decoding as before; remember to bump LatestAPIVersion.
+var bodyVersions = map[string]bodyVersion{
+	// Version 1 is the shape of the internal request models themselves
+	"1": {
+		create: func(h *TaskHandler, w http.ResponseWriter, r *http.Request) (*models.CreateTaskRequest, error) {
+			var req models.CreateTaskRequest
+			if err := h.decodeJSON(w, r, &req); err != nil {
+				return nil, err
+			}
+			return &req, nil
+		},
+		update: func(h *TaskHandler, w http.ResponseWriter, r *http.Request) (*models.UpdateTaskRequest, error) {
+			var req models.UpdateTaskRequest
+			if err := h.decodeJSON(w, r, &req); err != nil {
+				return nil, err
+			}
+			return &req, nil
+		},
+	},
+}
+
+// requestBodyVersion picks the body schema named by X-Api-Version, defaulting to the latest,
+// and echoes the chosen version in the response
+func requestBodyVersion(w http.ResponseWriter, r *http.Request) (bodyVersion, error) {
+	name := strings.TrimSpace(r.Header.Get(APIVersionHeader))
+	if name == "" {
+		name = LatestAPIVersion
+	}
+	version, ok := bodyVersions[name]
+	if !ok {
+		return bodyVersion{}, fmt.Errorf("unsupported %s %q (supported: %s)", APIVersionHeader, name, supportedAPIVersions())
+	}
+	w.Header().Set(APIVersionHeader, name)
+	return version, nil
+}
+
+// supportedAPIVersions lists the accepted versions for error messages
+func supportedAPIVersions() string {
+	names := make([]string, 0, len(bodyVersions))
+	for name := range bodyVersions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
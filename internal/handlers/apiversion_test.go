@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateTask_APIVersionHeader(t *testing.T) {
+	cases := map[string]struct {
+		header string
+		want   int
+	}{
+		"absent uses latest": {"", http.StatusCreated},
+		"explicit v1":        {" 1 ", http.StatusCreated},
+		"unknown":            {"7", http.StatusBadRequest},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockTaskService)
+			h := NewTaskHandler(mockService)
+			mockService.On("CreateTask", &models.CreateTaskRequest{Title: "Versioned"}).Return(&models.Task{ID: 1, Title: "Versioned"}, nil)
+			req := httptest.NewRequest("POST", "/api/tasks", strings.NewReader(`{"title":"Versioned"}`))
+			if tc.header != "" {
+				req.Header.Set(APIVersionHeader, tc.header)
+			}
+
+			// Act
+			rr := httptest.NewRecorder()
+			h.CreateTask(rr, req)
+
+			// Assert
+			assert.Equal(t, tc.want, rr.Code)
+			if tc.want == http.StatusBadRequest {
+				assert.Equal(t, "unsupported X-Api-Version \"7\" (supported: 1)\n", rr.Body.String())
+				mockService.AssertNotCalled(t, "CreateTask", &models.CreateTaskRequest{Title: "Versioned"})
+				return
+			}
+			assert.Equal(t, LatestAPIVersion, rr.Header().Get(APIVersionHeader))
+		})
+	}
+}
+
+func TestUpdateTask_UnknownAPIVersion(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	req := mux.SetURLVars(httptest.NewRequest("PUT", "/api/tasks/1", strings.NewReader(`{"title":"T"}`)), map[string]string{"id": "1"})
+	req.Header.Set(APIVersionHeader, "2024-01-01")
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.UpdateTask(rr, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertExpectations(t)
+}
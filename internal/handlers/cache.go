@@ -0,0 +1,34 @@
+package handlers
+
+import "net/http"
+
+// noStore forbids caching; it is the default for every response
+const noStore = "no-store"
+
+// CacheControl holds the Cache-Control values sent on successful reads of tasks, e.g.
+// "private, max-age=30". An empty value means no-store. Every other response, including all
+// mutations, is sent with no-store.
+type CacheControl struct {
+	Task string // GET /api/tasks/{id}
+	List string // GET /api/tasks
+}
+
+// WithCacheControl sets the Cache-Control policies for the task read endpoints
+func WithCacheControl(c CacheControl) Option {
+	return func(h *TaskHandler) {
+		h.cache = c
+	}
+}
+
+// setCacheControl applies policy to a successful read. A cacheable response varies with the
+// headers that change its body, so shared caches keep one copy per variant.
+func setCacheControl(w http.ResponseWriter, policy string) {
+	if policy == "" || policy == noStore {
+		w.Header().Set("Cache-Control", noStore)
+		return
+	}
+	w.Header().Set("Cache-Control", policy)
+	w.Header().Add("Vary", TimeFormatHeader)
+	w.Header().Add("Vary", IDFormatHeader)
+	w.Header().Add("Vary", "Prefer")
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCacheControl_Reads(t *testing.T) {
+	mockService := new(MockTaskService)
+	mockService.On("GetTask", 1).Return(&models.Task{ID: 1, Title: "T"}, nil)
+	mockService.On("GetAllTasks", mock.Anything).Return([]*models.Task{}, nil)
+	getTask := func(h *TaskHandler) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.GetTask(rr, mux.SetURLVars(httptest.NewRequest("GET", "/api/tasks/1", nil), map[string]string{"id": "1"}))
+		return rr
+	}
+	list := func(h *TaskHandler, prefer string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/tasks", nil)
+		if prefer != "" {
+			req.Header.Set("Prefer", prefer)
+		}
+		rr := httptest.NewRecorder()
+		h.GetAllTasks(rr, req)
+		return rr
+	}
+
+	// Default: nothing is cacheable
+	h := NewTaskHandler(mockService)
+	assert.Equal(t, "no-store", getTask(h).Header().Get("Cache-Control"))
+	assert.Equal(t, "no-store", list(h, "").Header().Get("Cache-Control"))
+	assert.Empty(t, getTask(h).Header().Values("Vary"))
+
+	// Configured per endpoint
+	h = NewTaskHandler(mockService, WithCacheControl(CacheControl{Task: "private, max-age=60", List: "max-age=5"}))
+	rr := getTask(h)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "private, max-age=60", rr.Header().Get("Cache-Control"))
+	assert.Equal(t, []string{TimeFormatHeader, IDFormatHeader, "Prefer"}, rr.Header().Values("Vary"))
+	assert.Equal(t, "max-age=5", list(h, "").Header().Get("Cache-Control"))
+	rr = list(h, "return=minimal")
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, "max-age=5", rr.Header().Get("Cache-Control"))
+}
+
+func TestCacheControl_MutationsNoStore(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService, WithCacheControl(CacheControl{Task: "max-age=60", List: "max-age=60"}))
+	mockService.On("CreateTask", &models.CreateTaskRequest{Title: "T"}).Return(&models.Task{ID: 1, Title: "T"}, nil)
+	mockService.On("DeleteTask", 1, &models.DeleteTaskRequest{}).Return(nil)
+
+	// Act
+	created := httptest.NewRecorder()
+	h.CreateTask(created, httptest.NewRequest("POST", "/api/tasks", strings.NewReader(`{"title":"T"}`)))
+	deleted := httptest.NewRecorder()
+	h.DeleteTask(deleted, mux.SetURLVars(httptest.NewRequest("DELETE", "/api/tasks/1", nil), map[string]string{"id": "1"}))
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, created.Code)
+	assert.Equal(t, "no-store", created.Header().Get("Cache-Control"))
+	assert.Equal(t, http.StatusNoContent, deleted.Code)
+	assert.Equal(t, "no-store", deleted.Header().Get("Cache-Control"))
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+)
+
+// calendarProdID identifies this API as the producer of exported calendars
+const calendarProdID = "-//cliffdoyle//task-api//EN"
+
+// icsLineLimit is the maximum line length in octets before RFC 5545 requires folding
+const icsLineLimit = 75
+
+// icsEscaper escapes TEXT values per RFC 5545 section 3.3.11
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// GetCalendar handles GET requests for /api/tasks/calendar.ics. It exports every open task that
+// has a due date as an all-day iCalendar event, so the feed can be subscribed to from a calendar
+// app. It accepts the same filters as the list endpoint (?assignee=, ?metadata.<key>=, ...);
+// completed tasks are always left out.
+func (h *TaskHandler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	filter, err := h.parseListFilter(r)
+	if err != nil {
+		h.writeCodedError(w, http.StatusBadRequest, CodeInvalidParam, err.Error())
+		return
+	}
+	filter.HasDueDate = true
+	filter.Open = true
+	// An old task with an open due date still belongs in the calendar
+	filter.All = true
+	filter.Statuses = openStatuses(filter.Statuses)
+	if len(filter.Statuses) == 0 {
+		// Only ?status=completed was asked for, which the calendar never includes
+		writeCalendar(w, nil)
+		return
+	}
+
+	tasks, err := h.service.GetAllTasks(filter)
+	if err != nil {
+		h.writeError(w, "retrieve tasks", err)
+		return
+	}
+	writeCalendar(w, tasks)
+}
+
+// openStatuses narrows a status filter to statuses that aren't completed; an empty filter
+// means every open status
+func openStatuses(statuses []string) []string {
+	if len(statuses) == 0 {
+		return []string{"in_progress", "pending"}
+	}
+	open := []string{}
+	for _, s := range statuses {
+		if s != "completed" {
+			open = append(open, s)
+		}
+	}
+	return open
+}
+
+// writeCalendar writes tasks as a VCALENDAR with one all-day VEVENT per task
+func writeCalendar(w http.ResponseWriter, tasks []*models.Task) {
+	var buf bytes.Buffer
+	writeICSLine(&buf, "BEGIN:VCALENDAR")
+	writeICSLine(&buf, "VERSION:2.0")
+	writeICSLine(&buf, "PRODID:"+calendarProdID)
+	writeICSLine(&buf, "CALSCALE:GREGORIAN")
+	for _, task := range tasks {
+		if task.DueDate == nil {
+			continue
+		}
+		writeICSLine(&buf, "BEGIN:VEVENT")
+		writeICSLine(&buf, fmt.Sprintf("UID:task-%d@task-api", task.ID))
+		writeICSLine(&buf, "DTSTAMP:"+task.UpdatedAt.UTC().Format("20060102T150405Z"))
+		writeICSLine(&buf, "DTSTART;VALUE=DATE:"+task.DueDate.Format("20060102"))
+		// DTEND is exclusive, so a one-day event ends the following day
+		writeICSLine(&buf, "DTEND;VALUE=DATE:"+task.DueDate.AddDate(0, 0, 1).Format("20060102"))
+		writeICSLine(&buf, "SUMMARY:"+icsEscaper.Replace(task.Title))
+		if task.Description != "" {
+			writeICSLine(&buf, "DESCRIPTION:"+icsEscaper.Replace(task.Description))
+		}
+		writeICSLine(&buf, "END:VEVENT")
+	}
+	writeICSLine(&buf, "END:VCALENDAR")
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="tasks.ics"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// writeICSLine writes one content line terminated by CRLF, folding it so no physical line
+// exceeds icsLineLimit octets. Folds never split a UTF-8 sequence.
+func writeICSLine(buf *bytes.Buffer, line string) {
+	limit := icsLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		limit = icsLineLimit - 1 // continuation lines start with a space
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+// isRuneStart reports whether b begins a UTF-8 sequence (is not a continuation byte)
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCalendar_EmitsEventsForOpenTasksWithDueDates(t *testing.T) {
+	due := models.NewDate(time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC))
+	updated := time.Date(2024, 6, 1, 9, 30, 0, 0, time.UTC)
+	mockService := new(MockTaskService)
+	mockService.On("GetAllTasks", models.ListFilter{
+		Statuses:   []string{"in_progress", "pending"},
+		Assignee:   "alice",
+		HasDueDate: true,
+		Open:       true,
+		All:        true,
+	}).Return([]*models.Task{
+		{ID: 7, Title: "Ship v2; then, relax", Description: "Line one\nback\\slash", DueDate: &due, UpdatedAt: updated},
+	}, nil)
+	h := NewTaskHandler(mockService)
+
+	rr := httptest.NewRecorder()
+	h.GetCalendar(rr, httptest.NewRequest("GET", "/api/tasks/calendar.ics?assignee=alice", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/calendar; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + calendarProdID,
+		"CALSCALE:GREGORIAN",
+		"BEGIN:VEVENT",
+		"UID:task-7@task-api",
+		"DTSTAMP:20240601T093000Z",
+		"DTSTART;VALUE=DATE:20240630",
+		"DTEND;VALUE=DATE:20240701",
+		`SUMMARY:Ship v2\; then\, relax`,
+		`DESCRIPTION:Line one\nback\\slash`,
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n"), rr.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestGetCalendar_CompletedOnlyIsEmpty(t *testing.T) {
+	// The service is never asked for completed tasks
+	h := NewTaskHandler(new(MockTaskService))
+
+	rr := httptest.NewRecorder()
+	h.GetCalendar(rr, httptest.NewRequest("GET", "/api/tasks/calendar.ics?status=completed", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "BEGIN:VEVENT")
+	assert.Contains(t, rr.Body.String(), "END:VCALENDAR\r\n")
+}
+
+func TestOpenStatuses(t *testing.T) {
+	assert.Equal(t, []string{"in_progress", "pending"}, openStatuses(nil))
+	assert.Equal(t, []string{"pending"}, openStatuses([]string{"completed", "pending"}))
+	assert.Empty(t, openStatuses([]string{"completed"}))
+}
+
+func TestWriteICSLine_Folds(t *testing.T) {
+	var buf bytes.Buffer
+	// Multi-byte runes straddle the fold points
+	writeICSLine(&buf, "SUMMARY:"+strings.Repeat("é", 100))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	assert.Greater(t, len(lines), 1)
+	var unfolded strings.Builder
+	for i, line := range lines {
+		assert.LessOrEqual(t, len(line), icsLineLimit, "line %d", i)
+		if i > 0 {
+			assert.True(t, strings.HasPrefix(line, " "))
+			line = line[1:]
+		}
+		unfolded.WriteString(line)
+	}
+	assert.Equal(t, "SUMMARY:"+strings.Repeat("é", 100), unfolded.String())
+}
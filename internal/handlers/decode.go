@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DecodeLimits bounds how much work decoding a request body may cost.
+// A zero value for any field disables that particular limit.
+type DecodeLimits struct {
+	MaxBodyBytes int64 // maximum size of the raw body
+	MaxDepth     int   // maximum nesting of objects/arrays
+	MaxTokens    int   // maximum number of JSON tokens (keys, values, delimiters)
+}
+
+// DefaultDecodeLimits are generous for task payloads while stopping pathological input
+var DefaultDecodeLimits = DecodeLimits{
+	MaxBodyBytes: 1 << 20, // 1 MiB
+	MaxDepth:     32,
+	MaxTokens:    10000,
+}
+
+var (
+	errEmptyBody         = errors.New("request body is empty")
+	errJSONTooDeep       = errors.New("JSON nesting exceeds maximum depth")
+	errJSONTooManyTokens = errors.New("JSON exceeds maximum number of tokens")
+	errUnsupportedMedia  = errors.New("Content-Type must be application/json")
+)
+
+// decodeJSON reads the request body within the handler's limits and unmarshals it into dst.
+// The body is scanned token by token first so deeply nested or oversized documents are
+// rejected before encoding/json builds any values from them.
+func (h *TaskHandler) decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	var body io.Reader = r.Body
+	if h.decodeLimits.MaxBodyBytes > 0 {
+		body = http.MaxBytesReader(w, r.Body, h.decodeLimits.MaxBodyBytes)
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return errEmptyBody
+	}
+	// Checked after the empty-body case so endpoints with optional bodies accept a bare request
+	if h.strictContentType && !isJSONContentType(r.Header.Get("Content-Type")) {
+		return errUnsupportedMedia
+	}
+	if err := checkJSONLimits(data, h.decodeLimits); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// isJSONContentType accepts application/json and +json types such as application/merge-patch+json,
+// optionally with charset=utf-8
+func isJSONContentType(header string) bool {
+	mediaType, params, err := mime.ParseMediaType(header)
+	if err != nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+		return false
+	}
+	for name, value := range params {
+		if name != "charset" || !strings.EqualFold(value, "utf-8") {
+			return false
+		}
+	}
+	return true
+}
+
+// checkJSONLimits walks the document's tokens, enforcing the depth and token limits
+func checkJSONLimits(data []byte, limits DecodeLimits) error {
+	if limits.MaxDepth <= 0 && limits.MaxTokens <= 0 {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	depth, tokens := 0, 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		tokens++
+		if limits.MaxTokens > 0 && tokens > limits.MaxTokens {
+			return fmt.Errorf("%w (%d)", errJSONTooManyTokens, limits.MaxTokens)
+		}
+
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+			if limits.MaxDepth > 0 && depth > limits.MaxDepth {
+				return fmt.Errorf("%w (%d)", errJSONTooDeep, limits.MaxDepth)
+			}
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+}
+
+// decodeErrorStatus maps a decodeJSON error to the HTTP status to respond with
+func decodeErrorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	if errors.Is(err, errUnsupportedMedia) {
+		return http.StatusUnsupportedMediaType
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckJSONLimits_WithinLimits(t *testing.T) {
+	err := checkJSONLimits([]byte(`{"title":"Task","description":"Desc"}`), DefaultDecodeLimits)
+	assert.NoError(t, err)
+}
+
+func TestCheckJSONLimits_TooDeep(t *testing.T) {
+	payload := strings.Repeat("[", 100) + strings.Repeat("]", 100)
+
+	err := checkJSONLimits([]byte(payload), DecodeLimits{MaxDepth: 32})
+
+	assert.True(t, errors.Is(err, errJSONTooDeep))
+}
+
+func TestCheckJSONLimits_TooManyTokens(t *testing.T) {
+	payload := "[" + strings.Repeat("1,", 50) + "1]"
+
+	err := checkJSONLimits([]byte(payload), DecodeLimits{MaxTokens: 20})
+
+	assert.True(t, errors.Is(err, errJSONTooManyTokens))
+}
+
+func TestCreateTask_PathologicallyNestedBody(t *testing.T) {
+	// The service is never reached, the guard rejects the body first
+	h := NewTaskHandler(nil)
+	payload := `{"title":` + strings.Repeat(`{"a":`, 10000) + `1` + strings.Repeat(`}`, 10000) + `}`
+
+	req := httptest.NewRequest("POST", "/api/tasks", strings.NewReader(payload))
+	rr := httptest.NewRecorder()
+	h.CreateTask(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "maximum depth")
+}
+
+func TestCreateTask_BodyTooLarge(t *testing.T) {
+	h := NewTaskHandler(nil, WithDecodeLimits(DecodeLimits{MaxBodyBytes: 16}))
+
+	req := httptest.NewRequest("POST", "/api/tasks", strings.NewReader(`{"title":"a very long title indeed"}`))
+	rr := httptest.NewRecorder()
+	h.CreateTask(rr, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+}
+
+func TestIsJSONContentType(t *testing.T) {
+	cases := map[string]bool{
+		"application/json":                  true,
+		"application/json; charset=utf-8":   true,
+		"Application/JSON; charset=UTF-8":   true,
+		"":                                  false,
+		"text/plain":                        false,
+		"application/x-www-form-urlencoded": false,
+		"application/json; charset=latin1":  false,
+		"application/json; version=2":       false,
+		"application/merge-patch+json":      true,
+	}
+	for header, want := range cases {
+		assert.Equal(t, want, isJSONContentType(header), header)
+	}
+}
+
+func TestStrictContentType_Rejects415(t *testing.T) {
+	// The service is never reached for a wrongly labelled body
+	h := NewTaskHandler(nil, WithStrictContentType(true))
+	handlers := map[string]http.HandlerFunc{
+		"create":    h.CreateTask,
+		"update":    h.UpdateTask,
+		"batch-get": h.BatchGetTasks,
+		"claim":     h.ClaimTask,
+	}
+	for name, handler := range handlers {
+		req := mux.SetURLVars(httptest.NewRequest("POST", "/", strings.NewReader(`{"title":"x"}`)), map[string]string{"id": "1"})
+		req.Header.Set("Content-Type", "text/plain")
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code, name)
+	}
+}
+
+func TestStrictContentType_LenientByDefault(t *testing.T) {
+	mockService := new(MockTaskService)
+	mockService.On("CreateTask", &models.CreateTaskRequest{Title: "x"}).Return(&models.Task{ID: 1, Title: "x"}, nil)
+	h := NewTaskHandler(mockService)
+
+	req := httptest.NewRequest("POST", "/api/tasks", strings.NewReader(`{"title":"x"}`))
+	rr := httptest.NewRecorder()
+	h.CreateTask(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+}
+
+func TestStrictContentType_AllowsBareReleaseAndJSON(t *testing.T) {
+	mockService := new(MockTaskService)
+	mockService.On("ReleaseTask", 1, &models.ReleaseTaskRequest{}).Return(&models.Task{ID: 1}, nil)
+	mockService.On("CreateTask", &models.CreateTaskRequest{Title: "x"}).Return(&models.Task{ID: 1, Title: "x"}, nil)
+	h := NewTaskHandler(mockService, WithStrictContentType(true))
+
+	// An empty optional body needs no Content-Type
+	rr := httptest.NewRecorder()
+	h.ReleaseTask(rr, mux.SetURLVars(httptest.NewRequest("POST", "/api/tasks/1/release", nil), map[string]string{"id": "1"}))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req := httptest.NewRequest("POST", "/api/tasks", strings.NewReader(`{"title":"x"}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	rr = httptest.NewRecorder()
+	h.CreateTask(rr, req)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+}
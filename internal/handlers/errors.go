@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cliffdoyle/task-api/internal/repository"
+	"github.com/cliffdoyle/task-api/internal/service"
+)
+
+// StatusClientClosedRequest is the non-standard status (from nginx) for a request the client
+// abandoned before the response was ready
+const StatusClientClosedRequest = 499
+
+// ErrorCode is a stable, machine-readable name for an error response. Messages may be reworded;
+// codes only ever get added.
+type ErrorCode string
+
+const (
+	CodeTaskNotFound     ErrorCode = "task.not_found"
+	CodeTemplateNotFound ErrorCode = "template.not_found"
+
+	CodeInvalidTransition ErrorCode = "status.invalid_transition"
+
+	CodeDuplicateTitle ErrorCode = "conflict.duplicate_title"
+	CodeTaskIDTaken    ErrorCode = "conflict.task_id_taken"
+	CodeTaskLocked     ErrorCode = "conflict.task_locked"
+	CodeNotClaimed     ErrorCode = "conflict.not_claimed"
+	CodeNotCompleted   ErrorCode = "conflict.not_completed"
+	CodeTaskCompleted  ErrorCode = "conflict.task_completed"
+
+	CodeAdminRequired       ErrorCode = "auth.admin_required"
+	CodeMaintenanceDisabled ErrorCode = "maintenance.disabled"
+
+	CodeTitleRequired            ErrorCode = "validation.title_required"
+	CodeInvalidTitle             ErrorCode = "validation.invalid_title"
+	CodeInvalidStatus            ErrorCode = "validation.invalid_status"
+	CodeInvalidPriority          ErrorCode = "validation.invalid_priority"
+	CodeInvalidDescription       ErrorCode = "validation.invalid_description"
+	CodeInvalidMetadata          ErrorCode = "validation.invalid_metadata"
+	CodeInvalidAssignee          ErrorCode = "validation.invalid_assignee"
+	CodeInvalidDueDate           ErrorCode = "validation.invalid_due_date"
+	CodeInvalidPatch             ErrorCode = "validation.invalid_patch"
+	CodeInvalidTemplate          ErrorCode = "validation.invalid_template"
+	CodeInvalidWorkerID          ErrorCode = "validation.invalid_worker_id"
+	CodeInvalidReopenReason      ErrorCode = "validation.invalid_reason"
+	CodeInvalidBatch             ErrorCode = "validation.invalid_batch"
+	CodeInvalidTransitionRequest ErrorCode = "validation.invalid_transition_request"
+	CodeInvalidDateRange         ErrorCode = "validation.invalid_date_range"
+	CodeInvalidSnooze            ErrorCode = "validation.invalid_snooze"
+	CodeInvalidMaintenance       ErrorCode = "validation.invalid_maintenance"
+	CodeInvalidActor             ErrorCode = "validation.invalid_actor"
+
+	CodeInvalidBody          ErrorCode = "request.invalid_body"
+	CodeBodyTooLarge         ErrorCode = "request.body_too_large"
+	CodeUnsupportedMediaType ErrorCode = "request.unsupported_media_type"
+	CodeInvalidParam         ErrorCode = "request.invalid_param"
+	CodeUnknownParam         ErrorCode = "request.unknown_param"
+	CodeInvalidCursor        ErrorCode = "request.invalid_cursor"
+	CodeUnsupportedVersion   ErrorCode = "request.unsupported_version"
+	CodeNotSupported         ErrorCode = "request.not_supported"
+	CodeCancelled            ErrorCode = "request.cancelled"
+	CodeTimeout              ErrorCode = "request.timeout"
+
+	CodeRouteNotFound    ErrorCode = "route.not_found"
+	CodeMethodNotAllowed ErrorCode = "route.method_not_allowed"
+
+	CodeBusy ErrorCode = "server.busy"
+
+	CodeInternal ErrorCode = "internal"
+)
+
+// busyRetryAfter is the Retry-After value, in seconds, sent with a server.busy 503
+const busyRetryAfter = "1"
+
+// ErrorCodeHeader carries the ErrorCode of every error response, whatever the ErrorFormat
+const ErrorCodeHeader = "X-Error-Code"
+
+// errorMapping is how writeError answers an error wrapping err. An empty message means the
+// error's own text is shown.
+type errorMapping struct {
+	err     error
+	code    ErrorCode
+	status  int
+	message string
+}
+
+// errorMappings is the one place service and repository errors are turned into responses.
+// The first entry whose error is wrapped wins.
+var errorMappings = []errorMapping{
+	{repository.ErrTaskNotFound, CodeTaskNotFound, http.StatusNotFound, "task not found"},
+	{repository.ErrTemplateNotFound, CodeTemplateNotFound, http.StatusNotFound, "template not found"},
+
+	{service.ErrInvalidTransition, CodeInvalidTransition, http.StatusConflict, ""},
+	{repository.ErrDuplicateTask, CodeDuplicateTitle, http.StatusConflict, repository.ErrDuplicateTask.Error()},
+	{repository.ErrTaskIDTaken, CodeTaskIDTaken, http.StatusConflict, "task ID belongs to a deleted task"},
+	{service.ErrTaskLocked, CodeTaskLocked, http.StatusConflict, ""},
+	{repository.ErrTaskNotClaimed, CodeNotClaimed, http.StatusConflict, ""},
+	{repository.ErrTaskNotCompleted, CodeNotCompleted, http.StatusConflict, ""},
+	{service.ErrTaskCompleted, CodeTaskCompleted, http.StatusConflict, ""},
+
+	{service.ErrMaintenanceDisabled, CodeMaintenanceDisabled, http.StatusForbidden, ""},
+
+	{service.ErrTitleRequired, CodeTitleRequired, http.StatusBadRequest, ""},
+	{service.ErrInvalidTitle, CodeInvalidTitle, http.StatusBadRequest, ""},
+	{service.ErrInvalidStatus, CodeInvalidStatus, http.StatusBadRequest, ""},
+	{service.ErrInvalidPriority, CodeInvalidPriority, http.StatusBadRequest, ""},
+	{service.ErrInvalidDescription, CodeInvalidDescription, http.StatusBadRequest, ""},
+	{repository.ErrDescriptionTooLong, CodeInvalidDescription, http.StatusBadRequest, ""},
+	{service.ErrInvalidMetadata, CodeInvalidMetadata, http.StatusBadRequest, ""},
+	{service.ErrInvalidAssignee, CodeInvalidAssignee, http.StatusBadRequest, ""},
+	{service.ErrInvalidDueDate, CodeInvalidDueDate, http.StatusBadRequest, ""},
+	{service.ErrInvalidPatch, CodeInvalidPatch, http.StatusBadRequest, ""},
+	{service.ErrInvalidTemplate, CodeInvalidTemplate, http.StatusBadRequest, ""},
+	{service.ErrInvalidWorkerID, CodeInvalidWorkerID, http.StatusBadRequest, ""},
+	{service.ErrInvalidReopenReason, CodeInvalidReopenReason, http.StatusBadRequest, ""},
+	{service.ErrInvalidBatch, CodeInvalidBatch, http.StatusBadRequest, ""},
+	{service.ErrInvalidTransitionRequest, CodeInvalidTransitionRequest, http.StatusBadRequest, ""},
+	{service.ErrInvalidDateRange, CodeInvalidDateRange, http.StatusBadRequest, ""},
+	{service.ErrInvalidSnooze, CodeInvalidSnooze, http.StatusBadRequest, ""},
+	{service.ErrInvalidMaintenance, CodeInvalidMaintenance, http.StatusBadRequest, ""},
+	{service.ErrInvalidActor, CodeInvalidActor, http.StatusBadRequest, ""},
+	{service.ErrInvalidCursor, CodeInvalidCursor, http.StatusBadRequest, ""},
+
+	// Too many writes at once; the client should retry shortly
+	{service.ErrTooManyTransactions, CodeBusy, http.StatusServiceUnavailable, ""},
+
+	// A request whose context ended is not a server fault: the client went away (499) or ran
+	// out of time (504), so neither shows up among the 500s. Only maintenance passes the request
+	// context down today; other endpoints reach these once their service calls take a context.
+	{context.Canceled, CodeCancelled, StatusClientClosedRequest, "request cancelled"},
+	{context.DeadlineExceeded, CodeTimeout, http.StatusGatewayTimeout, ""},
+}
+
+// ErrorFormat selects how error responses are written
+type ErrorFormat string
+
+const (
+	// ErrorFormatText writes the message as plain text, with the code only in X-Error-Code
+	ErrorFormatText ErrorFormat = "text"
+	// ErrorFormatJSON writes {"error": "<message>", "code": "<code>"}
+	ErrorFormatJSON ErrorFormat = "json"
+)
+
+// ParseErrorFormat validates an ErrorFormat name (case-insensitive)
+func ParseErrorFormat(s string) (ErrorFormat, error) {
+	switch f := ErrorFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case ErrorFormatText, ErrorFormatJSON:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown error format %q (want text or json)", s)
+}
+
+// WithErrorFormat sets how error responses are written; the default is ErrorFormatText
+func WithErrorFormat(f ErrorFormat) Option {
+	return func(h *TaskHandler) {
+		h.errorFormat = f
+	}
+}
+
+// writeError answers an error from the service, looking its code and status up in
+// errorMappings. Anything not listed is a 500, prefixed with what the handler was trying to do.
+func (h *TaskHandler) writeError(w http.ResponseWriter, action string, err error) {
+	for _, m := range errorMappings {
+		if !errors.Is(err, m.err) {
+			continue
+		}
+		message := m.message
+		if m.code == CodeTimeout {
+			message = fmt.Sprintf("timed out trying to %s", action)
+		} else if message == "" {
+			message = err.Error()
+		}
+		if m.code == CodeBusy {
+			w.Header().Set("Retry-After", busyRetryAfter)
+		}
+		h.writeCodedError(w, m.status, m.code, message)
+		return
+	}
+	h.writeCodedError(w, http.StatusInternalServerError, CodeInternal, fmt.Sprintf("failed to %s: %v", action, err))
+}
+
+// writeCodedError writes an error response in the handler's ErrorFormat
+func (h *TaskHandler) writeCodedError(w http.ResponseWriter, status int, code ErrorCode, message string) {
+	w.Header().Set(ErrorCodeHeader, string(code))
+	if h.errorFormat == ErrorFormatJSON {
+		writeJSON(w, status, errorResponse{Error: message, Code: code})
+		return
+	}
+	http.Error(w, message, status)
+}
+
+// writeDecodeError answers a decodeJSON error
+func (h *TaskHandler) writeDecodeError(w http.ResponseWriter, err error) {
+	status := decodeErrorStatus(err)
+	code := CodeInvalidBody
+	switch status {
+	case http.StatusRequestEntityTooLarge:
+		code = CodeBodyTooLarge
+	case http.StatusUnsupportedMediaType:
+		code = CodeUnsupportedMediaType
+	}
+	h.writeCodedError(w, status, code, fmt.Sprintf("invalid request body: %v", err))
+}
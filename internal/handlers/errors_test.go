@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cliffdoyle/task-api/internal/repository"
+	"github.com/cliffdoyle/task-api/internal/service"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteError(t *testing.T) {
+	tests := map[string]struct {
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		"other error":       {errors.New("connection refused"), http.StatusInternalServerError, "failed to get task: connection refused"},
+		"cancelled":         {context.Canceled, StatusClientClosedRequest, "request cancelled"},
+		"wrapped cancelled": {fmt.Errorf("failed to get task from repository: %w", context.Canceled), StatusClientClosedRequest, "request cancelled"},
+		"deadline":          {fmt.Errorf("failed to get task from repository: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, "timed out trying to get task"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			rr := httptest.NewRecorder()
+			NewTaskHandler(new(MockTaskService)).writeError(rr, "get task", tc.err)
+
+			// Assert
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, tc.wantBody+"\n", rr.Body.String())
+		})
+	}
+}
+
+func TestGetTask_ContextErrors(t *testing.T) {
+	tests := map[string]struct {
+		err        error
+		wantStatus int
+	}{
+		"client went away": {context.Canceled, StatusClientClosedRequest},
+		"timed out":        {context.DeadlineExceeded, http.StatusGatewayTimeout},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockTaskService)
+			h := NewTaskHandler(mockService)
+			mockService.On("GetTask", 1).Return(nil, fmt.Errorf("failed to get task from repository: %w", tc.err))
+
+			// Act
+			rr := httptest.NewRecorder()
+			h.GetTask(rr, mux.SetURLVars(httptest.NewRequest("GET", "/api/tasks/1", nil), map[string]string{"id": "1"}))
+
+			// Assert
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWriteError_Codes(t *testing.T) {
+	tests := map[string]struct {
+		err        error
+		wantStatus int
+		wantCode   ErrorCode
+	}{
+		"task not found":             {repository.ErrTaskNotFound, http.StatusNotFound, "task.not_found"},
+		"template not found":         {repository.ErrTemplateNotFound, http.StatusNotFound, "template.not_found"},
+		"invalid transition":         {service.ErrInvalidTransition, http.StatusConflict, "status.invalid_transition"},
+		"duplicate title":            {repository.ErrDuplicateTask, http.StatusConflict, "conflict.duplicate_title"},
+		"task ID taken":              {repository.ErrTaskIDTaken, http.StatusConflict, "conflict.task_id_taken"},
+		"task locked":                {service.ErrTaskLocked, http.StatusConflict, "conflict.task_locked"},
+		"not claimed":                {repository.ErrTaskNotClaimed, http.StatusConflict, "conflict.not_claimed"},
+		"not completed":              {repository.ErrTaskNotCompleted, http.StatusConflict, "conflict.not_completed"},
+		"task completed":             {service.ErrTaskCompleted, http.StatusConflict, "conflict.task_completed"},
+		"maintenance disabled":       {service.ErrMaintenanceDisabled, http.StatusForbidden, "maintenance.disabled"},
+		"title required":             {service.ErrTitleRequired, http.StatusBadRequest, "validation.title_required"},
+		"title policy":               {service.ErrInvalidTitle, http.StatusBadRequest, "validation.invalid_title"},
+		"invalid status":             {service.ErrInvalidStatus, http.StatusBadRequest, "validation.invalid_status"},
+		"invalid priority":           {service.ErrInvalidPriority, http.StatusBadRequest, "validation.invalid_priority"},
+		"invalid description":        {service.ErrInvalidDescription, http.StatusBadRequest, "validation.invalid_description"},
+		"description too long":       {repository.ErrDescriptionTooLong, http.StatusBadRequest, "validation.invalid_description"},
+		"invalid metadata":           {service.ErrInvalidMetadata, http.StatusBadRequest, "validation.invalid_metadata"},
+		"invalid assignee":           {service.ErrInvalidAssignee, http.StatusBadRequest, "validation.invalid_assignee"},
+		"invalid due date":           {service.ErrInvalidDueDate, http.StatusBadRequest, "validation.invalid_due_date"},
+		"invalid patch":              {service.ErrInvalidPatch, http.StatusBadRequest, "validation.invalid_patch"},
+		"invalid template":           {service.ErrInvalidTemplate, http.StatusBadRequest, "validation.invalid_template"},
+		"invalid worker ID":          {service.ErrInvalidWorkerID, http.StatusBadRequest, "validation.invalid_worker_id"},
+		"invalid reopen reason":      {service.ErrInvalidReopenReason, http.StatusBadRequest, "validation.invalid_reason"},
+		"invalid batch":              {service.ErrInvalidBatch, http.StatusBadRequest, "validation.invalid_batch"},
+		"invalid transition request": {service.ErrInvalidTransitionRequest, http.StatusBadRequest, "validation.invalid_transition_request"},
+		"invalid date range":         {service.ErrInvalidDateRange, http.StatusBadRequest, "validation.invalid_date_range"},
+		"invalid snooze":             {service.ErrInvalidSnooze, http.StatusBadRequest, "validation.invalid_snooze"},
+		"invalid maintenance":        {service.ErrInvalidMaintenance, http.StatusBadRequest, "validation.invalid_maintenance"},
+		"invalid actor":              {service.ErrInvalidActor, http.StatusBadRequest, "validation.invalid_actor"},
+		"invalid cursor":             {service.ErrInvalidCursor, http.StatusBadRequest, "request.invalid_cursor"},
+		"cancelled":                  {context.Canceled, StatusClientClosedRequest, "request.cancelled"},
+		"timed out":                  {context.DeadlineExceeded, http.StatusGatewayTimeout, "request.timeout"},
+		"too many transactions":      {service.ErrTooManyTransactions, http.StatusServiceUnavailable, "server.busy"},
+		"unexpected":                 {errors.New("connection refused"), http.StatusInternalServerError, "internal"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			h := NewTaskHandler(new(MockTaskService), WithErrorFormat(ErrorFormatJSON))
+
+			// Act
+			rr := httptest.NewRecorder()
+			h.writeError(rr, "do it", fmt.Errorf("wrapped: %w", tc.err))
+
+			// Assert
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			assert.Equal(t, string(tc.wantCode), rr.Header().Get(ErrorCodeHeader))
+			var body errorResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+			assert.Equal(t, tc.wantCode, body.Code)
+			assert.NotEmpty(t, body.Error)
+		})
+	}
+}
+
+func TestWriteError_BusySetsRetryAfter(t *testing.T) {
+	// Arrange
+	h := NewTaskHandler(new(MockTaskService))
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.writeError(rr, "update task", service.ErrTooManyTransactions)
+
+	// Assert
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+}
+
+func TestWriteCodedError_Formats(t *testing.T) {
+	// Act
+	text := httptest.NewRecorder()
+	NewTaskHandler(new(MockTaskService)).writeCodedError(text, http.StatusNotFound, CodeTaskNotFound, "task not found")
+	jsonRR := httptest.NewRecorder()
+	NewTaskHandler(new(MockTaskService), WithErrorFormat(ErrorFormatJSON)).writeCodedError(jsonRR, http.StatusNotFound, CodeTaskNotFound, "task not found")
+
+	// Assert
+	assert.Equal(t, "task not found\n", text.Body.String())
+	assert.Equal(t, "task.not_found", text.Header().Get(ErrorCodeHeader))
+	assert.Equal(t, "application/json", jsonRR.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error":"task not found","code":"task.not_found"}`, jsonRR.Body.String())
+	assert.Equal(t, "task.not_found", jsonRR.Header().Get(ErrorCodeHeader))
+}
+
+func TestParseErrorFormat(t *testing.T) {
+	f, err := ParseErrorFormat(" JSON ")
+	assert.NoError(t, err)
+	assert.Equal(t, ErrorFormatJSON, f)
+
+	_, err = ParseErrorFormat("xml")
+	assert.EqualError(t, err, `unknown error format "xml" (want text or json)`)
+}
+
+// Each handler path reports its code, not just the shared mapping
+func TestHandlerErrorCodes(t *testing.T) {
+	tests := map[string]struct {
+		setup    func(m *MockTaskService)
+		call     func(h *TaskHandler, w http.ResponseWriter)
+		wantCode ErrorCode
+	}{
+		"get missing task": {
+			setup: func(m *MockTaskService) {
+				m.On("GetTask", 7).Return(nil, fmt.Errorf("failed to get task from repository: %w", repository.ErrTaskNotFound))
+			},
+			call: func(h *TaskHandler, w http.ResponseWriter) {
+				h.GetTask(w, mux.SetURLVars(httptest.NewRequest("GET", "/api/tasks/7", nil), map[string]string{"id": "7"}))
+			},
+			wantCode: CodeTaskNotFound,
+		},
+		"bad task ID": {
+			call: func(h *TaskHandler, w http.ResponseWriter) {
+				h.GetTask(w, mux.SetURLVars(httptest.NewRequest("GET", "/api/tasks/abc", nil), map[string]string{"id": "abc"}))
+			},
+			wantCode: CodeInvalidParam,
+		},
+		"create without title": {
+			setup: func(m *MockTaskService) {
+				m.On("CreateTask", mock.Anything).Return(nil, service.ErrTitleRequired)
+			},
+			call: func(h *TaskHandler, w http.ResponseWriter) {
+				h.CreateTask(w, httptest.NewRequest("POST", "/api/tasks", strings.NewReader(`{"title":""}`)))
+			},
+			wantCode: CodeTitleRequired,
+		},
+		"malformed body": {
+			call: func(h *TaskHandler, w http.ResponseWriter) {
+				h.CreateTask(w, httptest.NewRequest("POST", "/api/tasks", strings.NewReader(`{"title":`)))
+			},
+			wantCode: CodeInvalidBody,
+		},
+		"forbidden transition": {
+			setup: func(m *MockTaskService) {
+				m.On("TransitionTask", 3, mock.Anything).Return(nil, fmt.Errorf("%w: completed -> pending", service.ErrInvalidTransition))
+			},
+			call: func(h *TaskHandler, w http.ResponseWriter) {
+				req := httptest.NewRequest("POST", "/api/tasks/3/transitions", strings.NewReader(`{"to":"pending"}`))
+				h.TransitionTask(w, mux.SetURLVars(req, map[string]string{"id": "3"}))
+			},
+			wantCode: CodeInvalidTransition,
+		},
+		"unknown list parameter": {
+			call: func(h *TaskHandler, w http.ResponseWriter) {
+				h.GetAllTasks(w, httptest.NewRequest("GET", "/api/tasks?strict_params=true&colour=red", nil))
+			},
+			wantCode: CodeUnknownParam,
+		},
+		"invalid status filter": {
+			call: func(h *TaskHandler, w http.ResponseWriter) {
+				h.GetAllTasks(w, httptest.NewRequest("GET", "/api/tasks?status=done", nil))
+			},
+			wantCode: CodeInvalidParam,
+		},
+		"null title in patch": {
+			call: func(h *TaskHandler, w http.ResponseWriter) {
+				req := httptest.NewRequest("PATCH", "/api/tasks/1", strings.NewReader(`{"title":null}`))
+				h.PatchTask(w, mux.SetURLVars(req, map[string]string{"id": "1"}))
+			},
+			wantCode: CodeInvalidPatch,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockTaskService)
+			if tc.setup != nil {
+				tc.setup(mockService)
+			}
+			h := NewTaskHandler(mockService, WithErrorFormat(ErrorFormatJSON))
+
+			// Act
+			rr := httptest.NewRecorder()
+			tc.call(h, rr)
+
+			// Assert
+			var body errorResponse
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+			assert.Equal(t, tc.wantCode, body.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/version"
+)
+
+// HealthHandler serves operational endpoints describing the running build
+type HealthHandler struct {
+	startedAt time.Time
+	now       func() time.Time
+}
+
+// NewHealthHandler creates a HealthHandler for a process that started at startedAt
+func NewHealthHandler(startedAt time.Time) *HealthHandler {
+	return &HealthHandler{startedAt: startedAt, now: time.Now}
+}
+
+// buildInfo is the body returned by GET /health/info
+type buildInfo struct {
+	Version       string    `json:"version"`
+	Commit        string    `json:"commit"`
+	BuildTime     string    `json:"build_time"`
+	GoVersion     string    `json:"go_version"`
+	StartTime     time.Time `json:"start_time"`
+	Uptime        string    `json:"uptime"`
+	UptimeSeconds int64     `json:"uptime_seconds"`
+}
+
+// Info handles GET requests reporting the build version, Go version and process uptime
+func (h *HealthHandler) Info(w http.ResponseWriter, r *http.Request) {
+	uptime := h.now().Sub(h.startedAt).Truncate(time.Second)
+	writeJSON(w, http.StatusOK, buildInfo{
+		Version:       version.Version,
+		Commit:        version.Commit,
+		BuildTime:     version.BuildTime,
+		GoVersion:     runtime.Version(),
+		StartTime:     h.startedAt.UTC(),
+		Uptime:        uptime.String(),
+		UptimeSeconds: int64(uptime.Seconds()),
+	})
+}
+
+// ReadyCheck returns a handler for GET /health/ready: 200 once ready is true, 503 until then.
+// The 503 tells the caller when to look again with Retry-After, in seconds, unless retryAfter
+// is zero or less.
+func ReadyCheck(ready *atomic.Bool, retryAfter int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !ready.Load() {
+			if retryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			}
+			http.Error(w, "starting", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	}
+}
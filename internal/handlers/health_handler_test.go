@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthInfo(t *testing.T) {
+	// Arrange
+	started := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	h := NewHealthHandler(started)
+	h.now = func() time.Time { return started.Add(90*time.Minute + 500*time.Millisecond) }
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.Info(rr, httptest.NewRequest("GET", "/health/info", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var info buildInfo
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&info))
+	assert.Equal(t, version.Version, info.Version)
+	assert.Equal(t, version.Commit, info.Commit)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+	assert.True(t, started.Equal(info.StartTime))
+	assert.Equal(t, "1h30m0s", info.Uptime)
+	assert.Equal(t, int64(5400), info.UptimeSeconds)
+}
+
+func TestReadyCheck(t *testing.T) {
+	var ready atomic.Bool
+	handler := ReadyCheck(&ready, 3)
+
+	rr := httptest.NewRecorder()
+	handler(rr, httptest.NewRequest("GET", "/health/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "3", rr.Header().Get("Retry-After"))
+
+	ready.Store(true)
+	rr = httptest.NewRecorder()
+	handler(rr, httptest.NewRequest("GET", "/health/ready", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("Retry-After"))
+}
+
+func TestReadyCheck_NoRetryAfter(t *testing.T) {
+	var ready atomic.Bool
+
+	rr := httptest.NewRecorder()
+	ReadyCheck(&ready, 0)(rr, httptest.NewRequest("GET", "/health/ready", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Empty(t, rr.Header().Get("Retry-After"))
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// IDFormat selects how integer IDs are written in JSON responses
+type IDFormat string
+
+const (
+	// IDFormatNumber writes IDs as JSON numbers
+	IDFormatNumber IDFormat = "number"
+	// IDFormatString writes IDs as strings of digits, which JavaScript clients can hold without
+	// losing precision above 2^53
+	IDFormatString IDFormat = "string"
+)
+
+// IDFormatHeader lets a client pick an IDFormat per request
+const IDFormatHeader = "Accept-ID-Format"
+
+// ParseIDFormat validates an IDFormat name (case-insensitive)
+func ParseIDFormat(s string) (IDFormat, error) {
+	switch f := IDFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case IDFormatNumber, IDFormatString:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown ID format %q (want number or string)", s)
+}
+
+// WithIDFormat sets the default encoding of integer IDs in responses
+func WithIDFormat(f IDFormat) Option {
+	return func(h *TaskHandler) {
+		h.idFormat = f
+	}
+}
+
+// requestIDFormat returns the format asked for in Accept-ID-Format, falling back to the handler's
+// default when the header is absent or names an unknown format
+func (h *TaskHandler) requestIDFormat(r *http.Request) IDFormat {
+	if header := r.Header.Get(IDFormatHeader); header != "" {
+		if f, err := ParseIDFormat(header); err == nil {
+			return f
+		}
+	}
+	if h.idFormat == "" {
+		return IDFormatNumber
+	}
+	return h.idFormat
+}
+
+// idFields are the response keys holding a task ID or a list of them
+var idFields = map[string]bool{"id": true, "task_id": true, "ids": true, "missing": true}
+
+// applyStringIDs rewrites an encoded response so every numeric ID is a string. IDs that are
+// already strings, such as UUIDs in uuid mode, are left alone.
+func applyStringIDs(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(stringifyIDs(doc))
+}
+
+// stringifyIDs walks a decoded JSON document, converting ID numbers to strings in place
+func stringifyIDs(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if key == "metadata" {
+				continue
+			}
+			if idFields[key] {
+				v[key] = idString(value)
+				continue
+			}
+			v[key] = stringifyIDs(value)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = stringifyIDs(v[i])
+		}
+	}
+	return v
+}
+
+// idString converts an ID, or each ID in a list, from a number to a string
+func idString(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		return v.String()
+	case []interface{}:
+		for i := range v {
+			if n, ok := v[i].(json.Number); ok {
+				v[i] = n.String()
+			}
+		}
+	}
+	return v
+}
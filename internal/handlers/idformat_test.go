@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDFormat_RoundTrip(t *testing.T) {
+	cases := map[IDFormat]string{
+		IDFormatNumber: `"ids":[1,99]`,
+		IDFormatString: `"ids":["1","99"]`,
+	}
+	for format, want := range cases {
+		t.Run(string(format), func(t *testing.T) {
+			// Arrange
+			h := NewTaskHandler(new(MockTaskService), WithIDFormat(format))
+			rr := httptest.NewRecorder()
+
+			// Act: encode a response carrying IDs, then decode its ids as a request would
+			h.respond(rr, httptest.NewRequest("GET", "/", nil), http.StatusOK,
+				&models.BatchGetRequest{IDs: []int{1, 99}})
+			var decoded models.BatchGetRequest
+			err := json.Unmarshal(rr.Body.Bytes(), &decoded)
+
+			// Assert
+			assert.Contains(t, rr.Body.String(), want)
+			require.NoError(t, err)
+			assert.Equal(t, models.IDList{1, 99}, decoded.IDs)
+		})
+	}
+}
+
+func TestRespond_StringIDs(t *testing.T) {
+	// Arrange
+	h := NewTaskHandler(new(MockTaskService))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(IDFormatHeader, "string")
+	resp := &models.BatchGetResponse{
+		Found:   []*models.Task{{ID: 9007199254740993, Title: "Big", Metadata: map[string]interface{}{"id": 5}}},
+		Missing: []int{4},
+	}
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.respond(rr, req, http.StatusOK, resp)
+
+	// Assert
+	body := rr.Body.String()
+	assert.Contains(t, body, `"id":"9007199254740993"`, "no precision lost on the way")
+	assert.Contains(t, body, `"missing":["4"]`)
+	assert.Contains(t, body, `"metadata":{"id":5}`, "metadata is client data and left alone")
+}
+
+func TestRespond_IDFormatHeaderAndDefault(t *testing.T) {
+	cases := map[string]struct {
+		def    IDFormat
+		header string
+		want   string
+	}{
+		"default":           {"", "", `"id":3`},
+		"configured":        {IDFormatString, "", `"id":"3"`},
+		"header overrides":  {IDFormatString, "number", `"id":3`},
+		"unknown header":    {IDFormatString, "hex", `"id":"3"`},
+		"header when unset": {"", "String", `"id":"3"`},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := NewTaskHandler(new(MockTaskService), WithIDFormat(tc.def))
+			req := httptest.NewRequest("GET", "/", nil)
+			if tc.header != "" {
+				req.Header.Set(IDFormatHeader, tc.header)
+			}
+
+			rr := httptest.NewRecorder()
+			h.respond(rr, req, http.StatusOK, &models.Task{ID: 3})
+
+			assert.Contains(t, rr.Body.String(), tc.want)
+		})
+	}
+}
+
+func TestBatchGetTasks_StringIDs(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("BatchGetTasks", &models.BatchGetRequest{IDs: []int{1, 2}}).
+		Return(&models.BatchGetResponse{Found: []*models.Task{}, Missing: []int{}}, nil)
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.BatchGetTasks(rr, httptest.NewRequest("POST", "/api/tasks/batch-get", strings.NewReader(`{"ids":["1",2]}`)))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestIDList_Invalid(t *testing.T) {
+	for _, body := range []string{`{"ids":["one"]}`, `{"ids":[1.5]}`, `{"ids":"1"}`, `{"ids":[true]}`} {
+		var req models.BatchGetRequest
+		assert.Error(t, json.Unmarshal([]byte(body), &req), body)
+	}
+}
+
+func TestParseIDFormat(t *testing.T) {
+	f, err := ParseIDFormat(" STRING ")
+	assert.NoError(t, err)
+	assert.Equal(t, IDFormatString, f)
+
+	_, err = ParseIDFormat("bigint")
+	assert.Error(t, err)
+}
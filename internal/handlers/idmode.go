@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/cliffdoyle/task-api/internal/repository"
+	"github.com/gorilla/mux"
+)
+
+// IDMode selects how tasks are identified in URLs and responses
+type IDMode string
+
+const (
+	// IDModeInt exposes the serial integer primary key
+	IDModeInt IDMode = "int"
+	// IDModeUUID exposes each task's random UUID instead, so IDs can't be guessed or used to
+	// count tasks. Integer IDs are still used internally.
+	IDModeUUID IDMode = "uuid"
+)
+
+// ParseIDMode validates an IDMode name (case-insensitive)
+func ParseIDMode(s string) (IDMode, error) {
+	switch m := IDMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case IDModeInt, IDModeUUID:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown ID mode %q (want int or uuid)", s)
+}
+
+// WithIDMode sets how task IDs are parsed from routes and written in responses
+func WithIDMode(m IDMode) Option {
+	return func(h *TaskHandler) {
+		h.idMode = m
+	}
+}
+
+// uuidPattern matches a UUID in its canonical 8-4-4-4-12 hex form
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// taskIDParam reads the {id} path variable as a task's internal ID. In uuid mode it must be
+// a UUID, which is looked up; an unknown UUID yields repository.ErrTaskNotFound.
+func (h *TaskHandler) taskIDParam(r *http.Request) (int, error) {
+	if h.idMode != IDModeUUID {
+		return parseIDParam(r, "id")
+	}
+	raw := mux.Vars(r)["id"]
+	if !uuidPattern.MatchString(raw) {
+		return 0, &ParamError{Name: "id", Value: raw, Reason: "must be a UUID"}
+	}
+	return h.service.ResolveUUID(strings.ToLower(raw))
+}
+
+// writeIDError answers a taskIDParam error: 404 for an unknown UUID, otherwise as writeParamError
+func (h *TaskHandler) writeIDError(w http.ResponseWriter, err error) {
+	if errors.Is(err, repository.ErrTaskNotFound) {
+		h.writeError(w, "resolve task ID", err)
+		return
+	}
+	h.writeParamError(w, err)
+}
+
+// uuidIDFields maps each ID field in a response to the field holding the same task's UUID
+var uuidIDFields = map[string]string{"id": "uuid", "task_id": "task_uuid"}
+
+// applyUUIDIDs rewrites an encoded response for uuid mode: wherever an object carries both an
+// integer ID and the matching UUID, the UUID replaces the ID and its own field is dropped
+func applyUUIDIDs(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(replaceIDs(doc))
+}
+
+// replaceIDs walks a decoded JSON document, swapping IDs for UUIDs in place
+func replaceIDs(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for idField, uuidField := range uuidIDFields {
+			if uuid, ok := v[uuidField].(string); ok {
+				if _, ok := v[idField]; ok {
+					v[idField] = uuid
+					delete(v, uuidField)
+				}
+			}
+		}
+		for key, value := range v {
+			if key == "metadata" {
+				continue
+			}
+			v[key] = replaceIDs(value)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = replaceIDs(v[i])
+		}
+	}
+	return v
+}
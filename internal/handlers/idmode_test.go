@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/cliffdoyle/task-api/internal/repository"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+const testUUID = "3f2b8c1e-9a4d-4e6f-8b2a-1c5d7e9f0a3b"
+
+func TestParseIDMode(t *testing.T) {
+	m, err := ParseIDMode(" UUID ")
+	assert.NoError(t, err)
+	assert.Equal(t, IDModeUUID, m)
+
+	_, err = ParseIDMode("ulid")
+	assert.Error(t, err)
+}
+
+func TestGetTask_UUIDMode(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService, WithIDMode(IDModeUUID))
+	mockService.On("ResolveUUID", testUUID).Return(7, nil)
+	mockService.On("ResolveUUID", "00000000-0000-4000-8000-000000000000").
+		Return(0, fmt.Errorf("failed to resolve task UUID: %w", repository.ErrTaskNotFound))
+	mockService.On("GetTask", 7).Return(&models.Task{ID: 7, UUID: testUUID, Title: "Secret count",
+		Metadata: map[string]interface{}{"id": 1, "uuid": "left alone"}}, nil)
+	get := func(id string) *httptest.ResponseRecorder {
+		req := mux.SetURLVars(httptest.NewRequest("GET", "/api/tasks/"+id, nil), map[string]string{"id": id})
+		rr := httptest.NewRecorder()
+		h.GetTask(rr, req)
+		return rr
+	}
+
+	// Act
+	found := get(strings.ToUpper(testUUID))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, found.Code)
+	assert.Contains(t, found.Body.String(), `"id":"`+testUUID+`"`)
+	assert.NotContains(t, found.Body.String(), `"id":7`)
+	assert.Contains(t, found.Body.String(), `"metadata":{"id":1,"uuid":"left alone"}`, "metadata is client data")
+	assert.Equal(t, http.StatusNotFound, get("00000000-0000-4000-8000-000000000000").Code)
+	assert.Equal(t, http.StatusBadRequest, get("7").Code, "integer IDs aren't accepted in uuid mode")
+}
+
+func TestGetTask_IntModeRejectsUUID(t *testing.T) {
+	h := NewTaskHandler(new(MockTaskService))
+
+	req := mux.SetURLVars(httptest.NewRequest("GET", "/api/tasks/"+testUUID, nil), map[string]string{"id": testUUID})
+	rr := httptest.NewRecorder()
+	h.GetTask(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestBatchGetTasks_UnavailableInUUIDMode(t *testing.T) {
+	h := NewTaskHandler(new(MockTaskService), WithIDMode(IDModeUUID))
+
+	rr := httptest.NewRecorder()
+	h.BatchGetTasks(rr, httptest.NewRequest("POST", "/api/tasks/batch-get", strings.NewReader(`{"ids":[1]}`)))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestApplyUUIDIDs(t *testing.T) {
+	data := []byte(`{"changes":[{"id":1,"uuid":"a"},{"id":2}],"next":{"task_id":3,"task_uuid":"c","status":"pending"}}`)
+
+	out, err := applyUUIDIDs(data)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"changes":[{"id":"a"},{"id":2}],"next":{"task_id":"c","status":"pending"}}`, string(out))
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/gorilla/mux"
+)
+
+// Route names the link builder looks up. Routes are registered under these names in
+// cmd/api/main.go; a link whose route isn't registered is simply left out.
+const (
+	RouteTask            = "task"
+	RouteTaskTransitions = "task-transitions"
+)
+
+// linksParam turns links on (?links=true) or off (?links=false) for a single request
+const linksParam = "links"
+
+// Links configures the optional _links added to task responses
+type Links struct {
+	Router  *mux.Router // resolves route names to paths
+	Default bool        // add links when the request doesn't say
+	BaseURL string      // scheme and host for absolute URLs, e.g. https://api.example.com; derived from the request when empty
+}
+
+// WithLinks enables _links on task responses, built from the router's named routes
+func WithLinks(links Links) Option {
+	return func(h *TaskHandler) {
+		h.links = links
+	}
+}
+
+// link is one entry in _links
+type link struct {
+	Href   string `json:"href"`
+	Method string `json:"method"`
+}
+
+// taskLink describes a link added to every task. To link a new endpoint, name its route and
+// add a row here.
+type taskLink struct {
+	rel    string
+	route  string
+	method string
+}
+
+var taskLinks = []taskLink{
+	{rel: "self", route: RouteTask, method: http.MethodGet},
+	{rel: "update", route: RouteTask, method: http.MethodPut},
+	{rel: "patch", route: RouteTask, method: http.MethodPatch},
+	{rel: "delete", route: RouteTask, method: http.MethodDelete},
+	{rel: "transitions", route: RouteTaskTransitions, method: http.MethodGet},
+}
+
+// linkedTask is a task with its _links; embedding keeps the task's own fields at the top level
+type linkedTask struct {
+	*models.Task
+	Links map[string]link `json:"_links"`
+}
+
+// linkedSummary is a task summary with its _links
+type linkedSummary struct {
+	*models.TaskSummary
+	Links map[string]link `json:"_links"`
+}
+
+// wantLinks reports whether r's response should carry _links. It is read while the response
+// is written, too late for a 400, so ValidateLinks rejects bad values first and anything
+// unrecognised that gets this far keeps the default.
+func (h *TaskHandler) wantLinks(r *http.Request) bool {
+	if h.links.Router == nil {
+		return false
+	}
+	if want, ok := parseBoolValue(r.URL.Query().Get(linksParam)); ok {
+		return want
+	}
+	return h.links.Default
+}
+
+// ValidateLinks is middleware that answers 400 when ?links isn't a boolean, before the handler
+// runs and possibly writes anything. With lenient booleans it lets every request through.
+func (h *TaskHandler) ValidateLinks(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := h.parseBool(r, linksParam); err != nil {
+			h.writeParamError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// addLinks returns v with _links attached to every task in it, for the response types that
+// carry tasks; anything else is returned unchanged
+func (h *TaskHandler) addLinks(r *http.Request, v interface{}) interface{} {
+	switch v := v.(type) {
+	case *models.Task:
+		return h.linkTask(r, v)
+	case []*models.Task:
+		linked := make([]linkedTask, len(v))
+		for i, task := range v {
+			linked[i] = h.linkTask(r, task)
+		}
+		return linked
+	case []*models.TaskSummary:
+		linked := make([]linkedSummary, len(v))
+		for i, summary := range v {
+			linked[i] = linkedSummary{TaskSummary: summary, Links: h.taskLinks(r, summary.ID, summary.UUID)}
+		}
+		return linked
+	case *models.OldestPendingResponse:
+		return struct {
+			Task       linkedTask `json:"task"`
+			AgeSeconds int64      `json:"age_seconds"`
+		}{h.linkTask(r, v.Task), v.AgeSeconds}
+	case *models.BatchGetResponse:
+		found := make([]linkedTask, len(v.Found))
+		for i, task := range v.Found {
+			found[i] = h.linkTask(r, task)
+		}
+		return struct {
+			Found   []linkedTask `json:"found"`
+			Missing []int        `json:"missing"`
+		}{found, v.Missing}
+	}
+	return v
+}
+
+// linkTask attaches the _links for one task
+func (h *TaskHandler) linkTask(r *http.Request, task *models.Task) linkedTask {
+	return linkedTask{Task: task, Links: h.taskLinks(r, task.ID, task.UUID)}
+}
+
+// taskLinks builds the _links for the task with the given ID and UUID
+func (h *TaskHandler) taskLinks(r *http.Request, taskID int, uuid string) map[string]link {
+	id := strconv.Itoa(taskID)
+	if h.idMode == IDModeUUID {
+		id = uuid
+	}
+	base := h.baseURL(r)
+
+	links := map[string]link{}
+	for _, tl := range taskLinks {
+		route := h.links.Router.Get(tl.route)
+		if route == nil {
+			continue
+		}
+		u, err := route.URLPath("id", id)
+		if err != nil {
+			continue
+		}
+		links[tl.rel] = link{Href: base + u.Path, Method: tl.method}
+	}
+	return links
+}
+
+// baseURL is the configured base URL, or the scheme and host the request was made to
+func (h *TaskHandler) baseURL(r *http.Request) string {
+	if h.links.BaseURL != "" {
+		return strings.TrimSuffix(h.links.BaseURL, "/")
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// linkedRouter registers the task routes the way cmd/api/main.go does
+func linkedRouter(mockService *MockTaskService, links Links, opts ...Option) *mux.Router {
+	r := mux.NewRouter()
+	links.Router = r
+	h := NewTaskHandler(mockService, append(opts, WithLinks(links))...)
+	r.Use(h.ValidateLinks)
+	r.HandleFunc("/api/tasks", h.GetAllTasks).Methods("GET")
+	r.HandleFunc("/api/tasks/{id}", h.GetTask).Methods("GET").Name(RouteTask)
+	r.HandleFunc("/api/tasks/{id}/next-allowed-transitions", h.GetNextTransitions).Methods("GET").Name(RouteTaskTransitions)
+	return r
+}
+
+func decodeLinks(t *testing.T, body []byte) map[string]link {
+	var doc struct {
+		Links map[string]link `json:"_links"`
+	}
+	require.NoError(t, json.Unmarshal(body, &doc))
+	return doc.Links
+}
+
+func TestLinks_SelfLinkResolves(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	mockService.On("GetTask", 5).Return(&models.Task{ID: 5, Title: "Linked"}, nil)
+	router := linkedRouter(mockService, Links{})
+
+	// Act
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "http://tasks.example.com/api/tasks/5?links=true", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, rr.Code)
+	links := decodeLinks(t, rr.Body.Bytes())
+	assert.Equal(t, link{Href: "http://tasks.example.com/api/tasks/5", Method: "GET"}, links["self"])
+	assert.Equal(t, link{Href: "http://tasks.example.com/api/tasks/5", Method: "DELETE"}, links["delete"])
+	assert.Equal(t, link{Href: "http://tasks.example.com/api/tasks/5/next-allowed-transitions", Method: "GET"}, links["transitions"])
+	assert.Contains(t, rr.Body.String(), `"title":"Linked"`, "task fields stay at the top level")
+
+	// Following self through the router yields the same task
+	self, err := url.Parse(links["self"].Href)
+	require.NoError(t, err)
+	followed := httptest.NewRecorder()
+	router.ServeHTTP(followed, httptest.NewRequest("GET", self.String(), nil))
+	assert.Equal(t, http.StatusOK, followed.Code)
+	assert.Contains(t, followed.Body.String(), `"id":5`)
+	assert.NotContains(t, followed.Body.String(), "_links", "links are off by default")
+}
+
+func TestLinks_DefaultOnWithBaseURL(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	mockService.On("GetAllTasks", models.ListFilter{}).Return([]*models.Task{{ID: 1, UUID: testUUID}}, nil)
+	router := linkedRouter(mockService, Links{Default: true, BaseURL: "https://api.example.com/"}, WithIDMode(IDModeUUID))
+
+	// Act
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/tasks", nil))
+	off := httptest.NewRecorder()
+	router.ServeHTTP(off, httptest.NewRequest("GET", "/api/tasks?links=false", nil))
+	offNo := httptest.NewRecorder()
+	router.ServeHTTP(offNo, httptest.NewRequest("GET", "/api/tasks?links=NO", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, rr.Code)
+	var tasks []json.RawMessage
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &tasks))
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "https://api.example.com/api/tasks/"+testUUID, decodeLinks(t, tasks[0])["self"].Href)
+	assert.NotContains(t, off.Body.String(), "_links")
+	assert.NotContains(t, offNo.Body.String(), "_links", "links takes the same spellings as other booleans")
+}
+
+func TestLinks_UnrecognisedValue(t *testing.T) {
+	tests := map[string]struct {
+		opts       []Option
+		wantStatus int
+	}{
+		"strict rejects it":         {nil, http.StatusBadRequest},
+		"lenient keeps the default": {[]Option{WithStrictBoolParams(false)}, http.StatusOK},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockTaskService)
+			mockService.On("GetTask", 5).Return(&models.Task{ID: 5}, nil)
+			router := linkedRouter(mockService, Links{Default: true}, tc.opts...)
+
+			// Act
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest("GET", "/api/tasks/5?links=maybe", nil))
+
+			// Assert
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			if tc.wantStatus == http.StatusBadRequest {
+				assert.Equal(t, string(CodeInvalidParam), rr.Header().Get(ErrorCodeHeader))
+				assert.Contains(t, rr.Body.String(), "links")
+				mockService.AssertNotCalled(t, "GetTask", 5)
+			} else {
+				assert.Contains(t, rr.Body.String(), "_links")
+			}
+		})
+	}
+}
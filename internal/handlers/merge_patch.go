@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+)
+
+// parseMergePatch turns the top-level keys of a JSON Merge Patch into a PatchTaskRequest.
+// Working from raw values is what lets an explicit null be told apart from an absent key.
+func parseMergePatch(raw map[string]json.RawMessage) (*models.PatchTaskRequest, error) {
+	patch := &models.PatchTaskRequest{}
+
+	// Sorted so the error for a patch with several bad keys is deterministic
+	keys := make([]string, 0, len(raw))
+	for k := range raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := raw[key]
+		isNull := bytes.Equal(bytes.TrimSpace(value), []byte("null"))
+
+		switch key {
+		case "title", "status", "priority":
+			if isNull {
+				return nil, fmt.Errorf("%s cannot be null", key)
+			}
+			var s string
+			if err := json.Unmarshal(value, &s); err != nil {
+				return nil, fmt.Errorf("%s must be a string", key)
+			}
+			switch key {
+			case "title":
+				patch.Title = &s
+			case "status":
+				patch.Status = &s
+			default:
+				patch.Priority = &s
+			}
+		case "description", "assignee":
+			s := ""
+			if !isNull {
+				if err := json.Unmarshal(value, &s); err != nil {
+					return nil, fmt.Errorf("%s must be a string or null", key)
+				}
+			}
+			if key == "description" {
+				patch.Description = &s
+			} else {
+				patch.Assignee = &s
+			}
+		case "metadata":
+			if isNull {
+				patch.ClearMetadata = true
+				continue
+			}
+			var m map[string]interface{}
+			if err := json.Unmarshal(value, &m); err != nil {
+				return nil, fmt.Errorf("%s must be an object or null", key)
+			}
+			// An empty object is a no-op under merge patch rules, but still counts as present
+			if m == nil {
+				m = map[string]interface{}{}
+			}
+			patch.Metadata = m
+		case "due_date":
+			if isNull {
+				patch.ClearDueDate = true
+				continue
+			}
+			var d models.Date
+			if err := json.Unmarshal(value, &d); err != nil {
+				return nil, fmt.Errorf("%s: %v", key, err)
+			}
+			patch.DueDate = &d
+		default:
+			return nil, fmt.Errorf("field %q cannot be patched", key)
+		}
+	}
+	return patch, nil
+}
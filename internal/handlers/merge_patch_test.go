@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodePatch(t *testing.T, body string) (*models.PatchTaskRequest, error) {
+	var raw map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal([]byte(body), &raw))
+	return parseMergePatch(raw)
+}
+
+func TestParseMergePatch_NullVersusAbsent(t *testing.T) {
+	patch, err := decodePatch(t, `{"description": null, "metadata": {"team": null, "sprint": 13}}`)
+
+	require.NoError(t, err)
+	assert.Nil(t, patch.Title, "absent keys stay nil")
+	assert.Nil(t, patch.Status)
+	require.NotNil(t, patch.Description)
+	assert.Equal(t, "", *patch.Description, "null clears the description")
+	assert.Equal(t, map[string]interface{}{"team": nil, "sprint": float64(13)}, patch.Metadata)
+	assert.False(t, patch.ClearMetadata)
+}
+
+func TestParseMergePatch_Unassign(t *testing.T) {
+	patch, err := decodePatch(t, `{"assignee": null}`)
+
+	require.NoError(t, err)
+	require.NotNil(t, patch.Assignee)
+	assert.Equal(t, "", *patch.Assignee)
+}
+
+func TestParseMergePatch_DueDate(t *testing.T) {
+	patch, err := decodePatch(t, `{"due_date": "2024-06-30"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-06-30", patch.DueDate.String())
+
+	patch, err = decodePatch(t, `{"due_date": null}`)
+	require.NoError(t, err)
+	assert.True(t, patch.ClearDueDate)
+
+	_, err = decodePatch(t, `{"due_date": "30/06/2024"}`)
+	assert.Error(t, err)
+}
+
+func TestParseMergePatch_Priority(t *testing.T) {
+	patch, err := decodePatch(t, `{"priority": "high"}`)
+
+	require.NoError(t, err)
+	require.NotNil(t, patch.Priority)
+	assert.Equal(t, "high", *patch.Priority)
+}
+
+func TestParseMergePatch_ClearMetadata(t *testing.T) {
+	patch, err := decodePatch(t, `{"metadata": null, "title": "New"}`)
+
+	require.NoError(t, err)
+	assert.True(t, patch.ClearMetadata)
+	assert.Equal(t, "New", *patch.Title)
+}
+
+func TestParseMergePatch_Invalid(t *testing.T) {
+	for _, body := range []string{
+		`{"title": null}`,
+		`{"status": null}`,
+		`{"priority": null}`,
+		`{"title": 5}`,
+		`{"description": ["a"]}`,
+		`{"metadata": "x"}`,
+		`{"id": 9}`,
+		`{"created_at": "2024-01-01T00:00:00Z"}`,
+	} {
+		_, err := decodePatch(t, body)
+		assert.Error(t, err, body)
+	}
+}
+
+func TestPatchTask_ContentTypes(t *testing.T) {
+	mockService := new(MockTaskService)
+	title := "New"
+	mockService.On("PatchTask", 1, &models.PatchTaskRequest{Title: &title}).Return(&models.Task{ID: 1, Title: "New"}, nil)
+	h := NewTaskHandler(mockService)
+
+	for contentType, want := range map[string]int{
+		"application/merge-patch+json": http.StatusOK,
+		"application/json":             http.StatusOK,
+		"":                             http.StatusOK,
+		"text/plain":                   http.StatusUnsupportedMediaType,
+	} {
+		req := mux.SetURLVars(httptest.NewRequest("PATCH", "/api/tasks/1", strings.NewReader(`{"title":"New"}`)), map[string]string{"id": "1"})
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rr := httptest.NewRecorder()
+		h.PatchTask(rr, req)
+
+		assert.Equal(t, want, rr.Code, contentType)
+	}
+}
+
+func TestPatchTask_ReadOnlyFieldRejected(t *testing.T) {
+	// The service is never reached for an invalid patch
+	h := NewTaskHandler(nil)
+
+	req := mux.SetURLVars(httptest.NewRequest("PATCH", "/api/tasks/1", strings.NewReader(`{"id":2}`)), map[string]string{"id": "1"})
+	rr := httptest.NewRecorder()
+	h.PatchTask(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `field "id" cannot be patched`)
+}
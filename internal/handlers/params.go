@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/gorilla/mux"
+)
+
+// ParamError reports a path or query parameter that could not be parsed or is out of range.
+// Handlers always answer it with 400 Bad Request via writeParamError.
+type ParamError struct {
+	Name   string // parameter name, e.g. "id"
+	Value  string // raw value as received
+	Reason string
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("invalid %s %q: %s", e.Name, e.Value, e.Reason)
+}
+
+// parseIDParam reads the named path variable as a positive int ID
+func parseIDParam(r *http.Request, name string) (int, error) {
+	raw := mux.Vars(r)[name]
+	id, err := strconv.Atoi(raw)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, &ParamError{Name: name, Value: raw, Reason: "out of range"}
+		}
+		return 0, &ParamError{Name: name, Value: raw, Reason: "must be an integer"}
+	}
+	if id <= 0 {
+		return 0, &ParamError{Name: name, Value: raw, Reason: "must be a positive integer"}
+	}
+	return id, nil
+}
+
+// timezoneParam is the query parameter that asks for timestamps in a given IANA zone
+const timezoneParam = "tz"
+
+// parseTimezoneParam reads ?tz as an IANA zone name, e.g. America/New_York.
+// It returns nil when the parameter is absent, meaning timestamps stay in UTC.
+func parseTimezoneParam(r *http.Request) (*time.Location, error) {
+	raw := r.URL.Query().Get(timezoneParam)
+	if raw == "" {
+		return nil, nil
+	}
+	// "Local" would leak the server's own zone, which clients can't know
+	if raw == "Local" {
+		return nil, &ParamError{Name: timezoneParam, Value: raw, Reason: "unknown time zone"}
+	}
+	loc, err := time.LoadLocation(raw)
+	if err != nil {
+		return nil, &ParamError{Name: timezoneParam, Value: raw, Reason: "unknown time zone"}
+	}
+	return loc, nil
+}
+
+// parseTimeParam reads the named query parameter as an RFC 3339 timestamp or Unix seconds
+func parseTimeParam(r *http.Request, name string) (time.Time, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return time.Time{}, &ParamError{Name: name, Value: raw, Reason: "is required"}
+	}
+	t, err := parseTimeValue(raw)
+	if err != nil {
+		return time.Time{}, &ParamError{Name: name, Value: raw, Reason: "must be an RFC 3339 timestamp or Unix seconds"}
+	}
+	return t, nil
+}
+
+// parseDateParam reads the named query parameter as a required YYYY-MM-DD date
+func parseDateParam(r *http.Request, name string) (models.Date, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return models.Date{}, &ParamError{Name: name, Value: raw, Reason: "is required"}
+	}
+	d, err := models.ParseDate(raw)
+	if err != nil {
+		return models.Date{}, &ParamError{Name: name, Value: raw, Reason: "must be a date in YYYY-MM-DD form"}
+	}
+	return d, nil
+}
+
+// boolValues are the spellings a boolean query parameter may take, matched ignoring case
+var boolValues = map[string]bool{
+	"true": true, "1": true, "yes": true,
+	"false": false, "0": false, "no": false,
+}
+
+// parseBoolValue reads raw as one of boolValues, reporting whether it was one
+func parseBoolValue(raw string) (value, ok bool) {
+	value, ok = boolValues[strings.ToLower(strings.TrimSpace(raw))]
+	return value, ok
+}
+
+// parseBool reads the named query parameter as a boolean, returning false when it is absent.
+// Any other value is a ParamError, or false when the handler parses booleans leniently.
+func (h *TaskHandler) parseBool(r *http.Request, name string) (bool, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return false, nil
+	}
+	b, ok := parseBoolValue(raw)
+	if !ok && !h.lenientBools {
+		return false, &ParamError{Name: name, Value: raw, Reason: "must be true, false, 1, 0, yes or no"}
+	}
+	return b, nil
+}
+
+// limitParam caps how many results an endpoint returns
+const limitParam = "limit"
+
+// parseLimitParam reads ?limit as a positive int, returning 0 when it is absent
+func parseLimitParam(r *http.Request) (int, error) {
+	raw := r.URL.Query().Get(limitParam)
+	if raw == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit <= 0 {
+		return 0, &ParamError{Name: limitParam, Value: raw, Reason: "must be a positive integer"}
+	}
+	return limit, nil
+}
+
+// writeParamError responds to a ParamError with 400; any other error is treated as internal
+func (h *TaskHandler) writeParamError(w http.ResponseWriter, err error) {
+	var paramErr *ParamError
+	if errors.As(err, &paramErr) {
+		h.writeCodedError(w, http.StatusBadRequest, CodeInvalidParam, paramErr.Error())
+		return
+	}
+	h.writeCodedError(w, http.StatusInternalServerError, CodeInternal, err.Error())
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func requestWithID(method, id string) *http.Request {
+	req := httptest.NewRequest(method, "/api/tasks/"+id, nil)
+	return mux.SetURLVars(req, map[string]string{"id": id})
+}
+
+func TestParseIDParam_Valid(t *testing.T) {
+	id, err := parseIDParam(requestWithID("GET", "42"), "id")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 42, id)
+}
+
+func TestParseIDParam_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"abc":                   "must be an integer",
+		"":                      "must be an integer",
+		"0":                     "must be a positive integer",
+		"-5":                    "must be a positive integer",
+		"99999999999999999999":  "out of range",
+		"-99999999999999999999": "out of range",
+	}
+	for raw, reason := range cases {
+		_, err := parseIDParam(requestWithID("GET", raw), "id")
+
+		var paramErr *ParamError
+		assert.True(t, errors.As(err, &paramErr), raw)
+		assert.Equal(t, reason, paramErr.Reason, raw)
+	}
+}
+
+func TestHandlers_RejectInvalidIDWithoutCallingService(t *testing.T) {
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+
+	handlers := map[string]http.HandlerFunc{
+		"GET":    h.GetTask,
+		"PUT":    h.UpdateTask,
+		"DELETE": h.DeleteTask,
+		"POST":   h.ReleaseTask,
+	}
+	for method, handler := range handlers {
+		for _, raw := range []string{"abc", "0", "-1", "99999999999999999999"} {
+			rr := httptest.NewRecorder()
+			handler(rr, requestWithID(method, raw))
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code, "%s %s", method, raw)
+		}
+	}
+	// No expectations were set, so any service call would have panicked
+	mockService.AssertExpectations(t)
+}
+
+func TestParseTimezoneParam(t *testing.T) {
+	loc, err := parseTimezoneParam(httptest.NewRequest("GET", "/api/tasks", nil))
+	assert.NoError(t, err)
+	assert.Nil(t, loc, "no tz means UTC")
+
+	loc, err = parseTimezoneParam(httptest.NewRequest("GET", "/api/tasks?tz=America/New_York", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "America/New_York", loc.String())
+
+	for _, raw := range []string{"Mars/Olympus_Mons", "Local", "../etc/passwd"} {
+		_, err := parseTimezoneParam(httptest.NewRequest("GET", "/api/tasks?tz="+url.QueryEscape(raw), nil))
+
+		var paramErr *ParamError
+		assert.True(t, errors.As(err, &paramErr), raw)
+	}
+}
+
+func TestParseBool_AcceptedValues(t *testing.T) {
+	cases := map[string]bool{
+		"true": true, "TRUE": true, "1": true, "yes": true, "Yes": true,
+		"false": false, "False": false, "0": false, "no": false, "NO": false,
+		"": false,
+	}
+	for _, strict := range []bool{true, false} {
+		h := NewTaskHandler(nil, WithStrictBoolParams(strict))
+		for raw, want := range cases {
+			got, err := h.parseBool(httptest.NewRequest("GET", "/api/tasks?all="+raw, nil), "all")
+
+			assert.NoError(t, err, "%q strict=%v", raw, strict)
+			assert.Equal(t, want, got, "%q strict=%v", raw, strict)
+		}
+	}
+}
+
+func TestParseBool_UnrecognisedValues(t *testing.T) {
+	strict := NewTaskHandler(nil)
+	lenient := NewTaskHandler(nil, WithStrictBoolParams(false))
+
+	for _, raw := range []string{"maybe", "t", "on", "2", "yess"} {
+		req := httptest.NewRequest("GET", "/api/tasks?full="+raw, nil)
+
+		_, err := strict.parseBool(req, "full")
+		var paramErr *ParamError
+		if assert.True(t, errors.As(err, &paramErr), raw) {
+			assert.Equal(t, "full", paramErr.Name, raw)
+		}
+
+		got, err := lenient.parseBool(req, "full")
+		assert.NoError(t, err, raw)
+		assert.False(t, got, raw)
+	}
+}
+
+func TestGetAllTasks_BoolParamModes(t *testing.T) {
+	// Strict: 400 naming the parameter, and the service is never asked
+	mockService := new(MockTaskService)
+	rr := httptest.NewRecorder()
+	NewTaskHandler(mockService).GetAllTasks(rr, httptest.NewRequest("GET", "/api/tasks?all=maybe", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "invalid all")
+	mockService.AssertExpectations(t)
+
+	// Lenient: the unknown value reads as false
+	mockService = new(MockTaskService)
+	mockService.On("GetAllTasks", models.ListFilter{}).Return([]*models.Task{}, nil)
+	rr = httptest.NewRecorder()
+	NewTaskHandler(mockService, WithStrictBoolParams(false)).GetAllTasks(rr, httptest.NewRequest("GET", "/api/tasks?all=maybe", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+
+	// Either way yes and 1 mean true
+	mockService = new(MockTaskService)
+	mockService.On("GetAllTasks", models.ListFilter{All: true}).Return([]*models.Task{}, nil)
+	for _, raw := range []string{"yes", "1", "True"} {
+		rr = httptest.NewRecorder()
+		NewTaskHandler(mockService).GetAllTasks(rr, httptest.NewRequest("GET", "/api/tasks?all="+raw, nil))
+		assert.Equal(t, http.StatusOK, rr.Code, raw)
+	}
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// errorResponse is the JSON body returned for routing-level errors
+type errorResponse struct {
+	Error string    `json:"error"`
+	Code  ErrorCode `json:"code,omitempty"`
+}
+
+// writeJSON encodes v as the JSON response body with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeJSONError writes a JSON error body with the given status code
+func writeJSONError(w http.ResponseWriter, status int, code ErrorCode, message string) {
+	w.Header().Set(ErrorCodeHeader, string(code))
+	writeJSON(w, status, errorResponse{Error: message, Code: code})
+}
+
+// NotFoundHandler returns a JSON 404 for requests that match no route
+func NotFoundHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSONError(w, http.StatusNotFound, CodeRouteNotFound, "resource not found")
+	})
+}
+
+// MethodNotAllowedHandler returns a JSON 405 whose Allow header lists the methods
+// registered on router for the requested path
+func MethodNotAllowedHandler(router *mux.Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if allowed := allowedMethods(router, r.URL.Path); len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+		}
+		writeJSONError(w, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "method not allowed")
+	})
+}
+
+// allowedMethods collects the methods of every route whose path template matches path
+func allowedMethods(router *mux.Router, path string) []string {
+	seen := map[string]bool{}
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		pattern, err := route.GetPathRegexp()
+		if err != nil {
+			return nil // route without a path template
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil || !re.MatchString(path) {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil // route without a method matcher
+		}
+		for _, m := range methods {
+			seen[m] = true
+		}
+		return nil
+	})
+
+	allowed := make([]string, 0, len(seen))
+	for m := range seen {
+		allowed = append(allowed, m)
+	}
+	sort.Strings(allowed)
+	return allowed
+}
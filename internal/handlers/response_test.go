@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRouter registers the task routes with no-op handlers so only routing is exercised
+func newTestRouter() *mux.Router {
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+	r := mux.NewRouter()
+	r.HandleFunc("/api/tasks", noop).Methods("POST")
+	r.HandleFunc("/api/tasks", noop).Methods("GET")
+	r.HandleFunc("/api/tasks/{id}", noop).Methods("GET")
+	r.HandleFunc("/api/tasks/{id}", noop).Methods("PUT")
+	r.HandleFunc("/api/tasks/{id}", noop).Methods("DELETE")
+	r.NotFoundHandler = NotFoundHandler()
+	r.MethodNotAllowedHandler = MethodNotAllowedHandler(r)
+	return r
+}
+
+func TestMethodNotAllowed_JSONWithAllowHeader(t *testing.T) {
+	router := newTestRouter()
+
+	req := httptest.NewRequest("PATCH", "/api/tasks/1", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	assert.Equal(t, "DELETE, GET, PUT", rr.Header().Get("Allow"))
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var body errorResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+	assert.Equal(t, "method not allowed", body.Error)
+}
+
+func TestMethodNotAllowed_CollectionAllowHeader(t *testing.T) {
+	router := newTestRouter()
+
+	req := httptest.NewRequest("DELETE", "/api/tasks", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	assert.Equal(t, "GET, POST", rr.Header().Get("Allow"))
+}
+
+func TestNotFound_JSON(t *testing.T) {
+	router := newTestRouter()
+
+	req := httptest.NewRequest("GET", "/api/unknown", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var body errorResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+	assert.Equal(t, "resource not found", body.Error)
+}
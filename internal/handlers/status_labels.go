@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+)
+
+// DefaultLabelLanguage is used when Accept-Language names no language with labels
+const DefaultLabelLanguage = "en"
+
+// embeddedLabels holds the built-in translations, one <language>.json file per language
+//
+//go:embed labels/*.json
+var embeddedLabels embed.FS
+
+// StatusLabels maps a language tag (lowercase, e.g. "es" or "pt-br") to a display label for
+// each status. Statuses themselves are never translated.
+type StatusLabels map[string]map[string]string
+
+// statusLabel is one entry of the statuses response
+type statusLabel struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+// statusesResponse is the body of GET /api/tasks/statuses
+type statusesResponse struct {
+	Language string        `json:"language"`
+	Statuses []statusLabel `json:"statuses"`
+}
+
+// DefaultStatusLabels returns the built-in translations
+func DefaultStatusLabels() StatusLabels {
+	labels, err := readLabels(embeddedLabels, "labels")
+	if err != nil {
+		panic(err) // the embedded files are checked by the tests
+	}
+	return labels
+}
+
+// LoadStatusLabels returns the built-in translations plus those in dir, one <language>.json
+// file per language mapping statuses to labels. A file for a built-in language replaces its
+// labels status by status. An empty dir returns just the built-in translations.
+func LoadStatusLabels(dir string) (StatusLabels, error) {
+	labels := DefaultStatusLabels()
+	if dir == "" {
+		return labels, nil
+	}
+	extra, err := readLabels(os.DirFS(dir), ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load status labels from %s: %w", dir, err)
+	}
+	for lang, byStatus := range extra {
+		if labels[lang] == nil {
+			labels[lang] = map[string]string{}
+		}
+		for status, label := range byStatus {
+			labels[lang][status] = label
+		}
+	}
+	return labels, nil
+}
+
+// readLabels reads every <language>.json file in dir of fsys
+func readLabels(fsys fs.FS, dir string) (StatusLabels, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	labels := StatusLabels{}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var byStatus map[string]string
+		if err := json.Unmarshal(data, &byStatus); err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		for status, label := range byStatus {
+			if !models.IsValidStatus(status) {
+				return nil, fmt.Errorf("%s: unknown status %q", entry.Name(), status)
+			}
+			if strings.TrimSpace(label) == "" {
+				return nil, fmt.Errorf("%s: empty label for %q", entry.Name(), status)
+			}
+		}
+		labels[strings.ToLower(strings.TrimSuffix(entry.Name(), ".json"))] = byStatus
+	}
+	return labels, nil
+}
+
+// WithStatusLabels sets the translations GET /api/tasks/statuses answers with; the default is
+// DefaultStatusLabels
+func WithStatusLabels(labels StatusLabels) Option {
+	return func(h *TaskHandler) {
+		h.statusLabels = labels
+	}
+}
+
+// GetStatuses handles GET requests listing every status with a display label in the language
+// asked for by Accept-Language. Labels missing from that language fall back to English, and
+// then to the status itself.
+func (h *TaskHandler) GetStatuses(w http.ResponseWriter, r *http.Request) {
+	labels := h.statusLabels
+	lang := matchLanguage(r.Header.Get("Accept-Language"), labels)
+
+	resp := statusesResponse{Language: lang, Statuses: make([]statusLabel, 0, len(models.Statuses))}
+	for _, status := range models.Statuses {
+		label := labels[lang][status]
+		if label == "" {
+			label = labels[DefaultLabelLanguage][status]
+		}
+		if label == "" {
+			label = status
+		}
+		resp.Statuses = append(resp.Statuses, statusLabel{Value: status, Label: label})
+	}
+
+	w.Header().Set("Content-Language", lang)
+	w.Header().Add("Vary", "Accept-Language")
+	h.respond(w, r, http.StatusOK, resp)
+}
+
+// matchLanguage picks the language in labels that best fits an Accept-Language header. Ranges
+// are tried in order of quality; each matches its exact tag first and then its primary
+// language, so "es-MX" is served "es" labels. Nothing matching gives DefaultLabelLanguage.
+func matchLanguage(header string, labels StatusLabels) string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var ranges []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			ranges = append(ranges, weighted{tag, q})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	for _, r := range ranges {
+		if _, ok := labels[r.tag]; ok {
+			return r.tag
+		}
+		if base, _, found := strings.Cut(r.tag, "-"); found {
+			if _, ok := labels[base]; ok {
+				return base
+			}
+		}
+	}
+	return DefaultLabelLanguage
+}
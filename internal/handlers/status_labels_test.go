@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getStatuses calls GetStatuses with the given Accept-Language header
+func getStatuses(t *testing.T, h *TaskHandler, acceptLanguage string) (*httptest.ResponseRecorder, statusesResponse) {
+	req := httptest.NewRequest("GET", "/api/tasks/statuses", nil)
+	if acceptLanguage != "" {
+		req.Header.Set("Accept-Language", acceptLanguage)
+	}
+	rr := httptest.NewRecorder()
+	h.GetStatuses(rr, req)
+	var body statusesResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+	return rr, body
+}
+
+func TestGetStatuses_Spanish(t *testing.T) {
+	// Arrange
+	h := NewTaskHandler(new(MockTaskService))
+
+	// Act
+	rr, body := getStatuses(t, h, "es")
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "es", rr.Header().Get("Content-Language"))
+	assert.Equal(t, "Accept-Language", rr.Header().Get("Vary"))
+	assert.Equal(t, statusesResponse{Language: "es", Statuses: []statusLabel{
+		{Value: "pending", Label: "Pendiente"},
+		{Value: "in_progress", Label: "En curso"},
+		{Value: "completed", Label: "Completada"},
+	}}, body)
+}
+
+func TestGetStatuses_LanguageNegotiation(t *testing.T) {
+	tests := map[string]struct {
+		header string
+		want   string
+	}{
+		"no header":             {"", "en"},
+		"unknown language":      {"ja", "en"},
+		"region falls back":     {"es-MX", "es"},
+		"case-insensitive":      {"FR-ca", "fr"},
+		"highest quality wins":  {"de;q=0.5, fr;q=0.9", "fr"},
+		"unsupported skipped":   {"ja, de;q=0.8", "de"},
+		"zero quality excluded": {"es;q=0, de;q=0.1", "de"},
+		"wildcard":              {"*", "en"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, body := getStatuses(t, NewTaskHandler(new(MockTaskService)), tc.header)
+			assert.Equal(t, tc.want, body.Language)
+		})
+	}
+}
+
+func TestDefaultStatusLabels_CoverEveryStatus(t *testing.T) {
+	for lang, byStatus := range DefaultStatusLabels() {
+		for _, status := range models.Statuses {
+			assert.NotEmpty(t, byStatus[status], "%s has no label for %s", lang, status)
+		}
+	}
+}
+
+func TestLoadStatusLabels_Dir(t *testing.T) {
+	// Arrange: a new language, a partial override, and a file that isn't JSON labels
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pt-BR.json"), []byte(`{"pending": "Pendente"}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "es.json"), []byte(`{"completed": "Hecha"}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.txt"), []byte("ignored"), 0o644))
+
+	// Act
+	labels, err := LoadStatusLabels(dir)
+	require.NoError(t, err)
+	h := NewTaskHandler(new(MockTaskService), WithStatusLabels(labels))
+	_, pt := getStatuses(t, h, "pt-br")
+	_, es := getStatuses(t, h, "es")
+
+	// Assert: missing labels fall back to English; overrides keep the other built-in labels
+	assert.Equal(t, "pt-br", pt.Language)
+	assert.Equal(t, []statusLabel{{"pending", "Pendente"}, {"in_progress", "In progress"}, {"completed", "Completed"}}, pt.Statuses)
+	assert.Equal(t, []statusLabel{{"pending", "Pendiente"}, {"in_progress", "En curso"}, {"completed", "Hecha"}}, es.Statuses)
+}
+
+func TestLoadStatusLabels_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown status": `{"done": "Done"}`,
+		"empty label":    `{"pending": " "}`,
+		"not an object":  `["pending"]`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "xx.json"), []byte(content), 0o644))
+
+			_, err := LoadStatusLabels(dir)
+
+			assert.ErrorContains(t, err, "xx.json")
+		})
+	}
+
+	_, err := LoadStatusLabels(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
@@ -5,139 +5,1110 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"strconv" // For converting string ID from URL to int
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/cliffdoyle/task-api/internal/models"
 	"github.com/cliffdoyle/task-api/internal/repository"
 	"github.com/cliffdoyle/task-api/internal/service"
-	"github.com/gorilla/mux" // For routing and path variables
 )
 
+// ActorHeader names who is making a request, for the audit log. There is no authentication, so
+// it is taken on trust.
+const ActorHeader = "X-Actor"
+
 // TaskHandler provides HTTP handlers for task-related operations
 type TaskHandler struct {
-	service service.TaskService
+	service      service.TaskService
+	decodeLimits DecodeLimits
+
+	// emptyListNoContent makes an empty list respond 204 instead of 200 []
+	emptyListNoContent bool
+	// strictParams rejects unknown query parameters on the list endpoint
+	strictParams bool
+	// lenientBools reads unrecognised boolean query values as false instead of rejecting them
+	lenientBools bool
+	// strictContentType rejects JSON bodies not labelled application/json with 415
+	strictContentType bool
+	// timeFormat is the default timestamp format for responses (see Accept-Time-Format)
+	timeFormat TimeFormat
+	// idMode picks integer or UUID task IDs in routes and responses
+	idMode IDMode
+	// idFormat is the default encoding of integer IDs in responses (see Accept-ID-Format)
+	idFormat IDFormat
+	// links configures _links on task responses (see WithLinks)
+	links Links
+	// cache sets Cache-Control on the task read endpoints (see WithCacheControl)
+	cache CacheControl
+	// summaryList makes the list endpoint return TaskSummary objects unless ?full=true
+	summaryList bool
+	// foldStatus accepts ?status= filter values in any case
+	foldStatus bool
+	// listLimit caps the list endpoint when no ?limit is given, and maxListLimit caps ?limit;
+	// zero means no cap (see WithListLimits)
+	listLimit    int
+	maxListLimit int
+	// putCreates makes PUT on a missing ID create the task with that ID (see WithPutCreates)
+	putCreates bool
+	// adminToken authorises admin-only behaviour; empty disables it (see WithAdminToken)
+	adminToken string
+	// errorFormat picks plain-text or JSON error bodies (see WithErrorFormat)
+	errorFormat ErrorFormat
+	// statusLabels holds the translated status labels served by GetStatuses
+	statusLabels StatusLabels
+}
+
+// Option configures optional TaskHandler behaviour
+type Option func(*TaskHandler)
+
+// WithDecodeLimits overrides the limits applied when decoding request bodies
+func WithDecodeLimits(limits DecodeLimits) Option {
+	return func(h *TaskHandler) {
+		h.decodeLimits = limits
+	}
+}
+
+// WithEmptyListNoContent sets the default response for an empty task list:
+// 204 No Content when true, 200 with [] when false (the default).
+// Clients can override it per request with the Prefer header.
+func WithEmptyListNoContent(enabled bool) Option {
+	return func(h *TaskHandler) {
+		h.emptyListNoContent = enabled
+	}
+}
+
+// WithSummaryList makes the list endpoint return trimmed TaskSummary objects by default.
+// Clients can ask for complete tasks per request with ?full=true, or summaries with ?full=false.
+func WithSummaryList(enabled bool) Option {
+	return func(h *TaskHandler) {
+		h.summaryList = enabled
+	}
+}
+
+// WithCaseInsensitiveStatus makes the ?status= filter accept statuses in any case, matching the
+// service option of the same name
+func WithCaseInsensitiveStatus(enabled bool) Option {
+	return func(h *TaskHandler) {
+		h.foldStatus = enabled
+	}
+}
+
+// WithListLimits caps how many tasks the list endpoint returns. Without ?limit a list stops at
+// def tasks; an explicit ?limit may ask for up to max. A response that stops short of every
+// matching task carries X-Result-Truncated: true. Zero leaves the respective cap off.
+func WithListLimits(def, max int) Option {
+	return func(h *TaskHandler) {
+		h.listLimit = def
+		h.maxListLimit = max
+	}
+}
+
+// WithPutCreates makes PUT /api/tasks/{id} create a task with that ID, returning 201, when no
+// live task has it. By default such a PUT returns 404, since IDs are normally generated.
+func WithPutCreates(enabled bool) Option {
+	return func(h *TaskHandler) {
+		h.putCreates = enabled
+	}
+}
+
+// WithStrictParams makes the list endpoint reject unknown query parameters by default.
+// Clients can opt in per request with ?strict_params=true regardless of this setting.
+func WithStrictParams(enabled bool) Option {
+	return func(h *TaskHandler) {
+		h.strictParams = enabled
+	}
+}
+
+// WithStrictBoolParams sets how boolean query parameters (?all, ?full, ...) treat a value
+// other than true, false, 1, 0, yes or no: strict, the default, answers 400 naming the
+// parameter, while lenient reads it as false.
+func WithStrictBoolParams(strict bool) Option {
+	return func(h *TaskHandler) {
+		h.lenientBools = !strict
+	}
+}
+
+// WithStrictContentType makes request bodies require Content-Type: application/json
+// (optionally with charset=utf-8); anything else is answered with 415. Off by default.
+func WithStrictContentType(enabled bool) Option {
+	return func(h *TaskHandler) {
+		h.strictContentType = enabled
+	}
 }
 
 // NewTaskHandler creates a new instance of TaskHandler
-func NewTaskHandler(service service.TaskService) *TaskHandler {
-	return &TaskHandler{service: service}
+func NewTaskHandler(service service.TaskService, opts ...Option) *TaskHandler {
+	h := &TaskHandler{service: service, decodeLimits: DefaultDecodeLimits, statusLabels: DefaultStatusLabels()}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // CreateTask handles POST requests to create a new task
 func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
-	var req models.CreateTaskRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+	version, err := requestBodyVersion(w, r)
+	if err != nil {
+		h.writeCodedError(w, http.StatusBadRequest, CodeUnsupportedVersion, err.Error())
+		return
+	}
+	ifNotExists, err := h.parseBool(r, ifNotExistsParam)
+	if err != nil {
+		h.writeParamError(w, err)
+		return
+	}
+	req, err := version.create(h, w, r)
+	if err != nil {
+		h.writeDecodeError(w, err)
 		return
 	}
+	req.Actor = r.Header.Get(ActorHeader)
 
-	task, err := h.service.CreateTask(&req)
+	var task *models.Task
+	status := http.StatusCreated
+	if ifNotExists {
+		var created bool
+		task, created, err = h.service.CreateTaskIfNotExists(req)
+		if !created {
+			status = http.StatusOK
+		}
+	} else {
+		task, err = h.service.CreateTask(req)
+	}
 	if err != nil {
-		http.Error(w, fmt.Sprintf("failed to create task: %v", err), http.StatusInternalServerError)
+		h.writeError(w, "create task", err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(task)
+	h.respond(w, r, status, task)
 }
 
+// ifNotExistsParam makes POST /api/tasks return an existing task with the same title (200)
+// instead of creating another
+const ifNotExistsParam = "if_not_exists"
+
 // GetTask handles GET requests to retrieve a single task by ID
 func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	idStr := vars["id"]
-	id, err := strconv.Atoi(idStr)
+	id, err := h.taskIDParam(r)
+	if err != nil {
+		h.writeIDError(w, err)
+		return
+	}
+
+	loc, err := parseTimezoneParam(r)
 	if err != nil {
-		http.Error(w, "invalid task ID format", http.StatusBadRequest)
+		h.writeParamError(w, err)
 		return
 	}
 
 	task, err := h.service.GetTask(id)
 	if err != nil {
-		// Distinguish between "not found" and other errors, use errors.Is() to check for the specific wraped error
-		if errors.Is(err,repository.ErrTaskNotFound){
-			http.Error(w, "task not found", http.StatusNotFound)
+		h.writeError(w, "retrieve task", err)
+		return
+	}
+	localizeTask(task, loc)
+
+	setCacheControl(w, h.cache.Task)
+	h.respond(w, r, http.StatusOK, task)
+}
+
+// GetAllTasks handles GET requests to retrieve all tasks.
+// ?status=<status>[,<status>...] keeps tasks in any of the given statuses.
+// Tasks can be filtered by metadata value (?metadata.<key>=<value> or ?meta_<key>=<value>)
+// and by metadata key existence (?has=<key>). ?tz=<zone> renders timestamps in that zone.
+func (h *TaskHandler) GetAllTasks(w http.ResponseWriter, r *http.Request) {
+	strict, err := h.parseBool(r, strictParamsParam)
+	if err != nil {
+		h.writeParamError(w, err)
+		return
+	}
+	if h.strictParams || strict {
+		if unknown := unknownListParams(r); len(unknown) > 0 {
+			h.writeCodedError(w, http.StatusBadRequest, CodeUnknownParam, fmt.Sprintf("unrecognized query parameters: %s", strings.Join(unknown, ", ")))
+			return
+		}
+	}
+
+	filter, err := h.parseListFilter(r)
+	if err != nil {
+		h.writeCodedError(w, http.StatusBadRequest, CodeInvalidParam, err.Error())
+		return
+	}
+	loc, err := parseTimezoneParam(r)
+	if err != nil {
+		h.writeParamError(w, err)
+		return
+	}
+
+	full, err := h.wantFullList(r)
+	if err != nil {
+		h.writeParamError(w, err)
+		return
+	}
+	includeDeleted, err := h.parseBool(r, includeDeletedParam)
+	if err != nil {
+		h.writeParamError(w, err)
+		return
+	}
+	// Anyone else gets the normal list. Summaries have no deleted_at, so admins get full tasks.
+	if includeDeleted && h.isAdmin(r) {
+		filter.IncludeDeleted = true
+		full = true
+	}
+	limit, implicit, err := h.listPageLimit(r)
+	if err != nil {
+		h.writeParamError(w, err)
+		return
+	}
+	if limit > 0 {
+		// One extra row tells a full page apart from a truncated one
+		filter.Limit = limit + 1
+	}
+
+	var list interface{}
+	var count int
+	truncated := false
+	if full {
+		tasks, err := h.service.GetAllTasks(filter)
+		if err != nil {
+			h.writeError(w, "retrieve tasks", err)
+			return
+		}
+		if limit > 0 && len(tasks) > limit {
+			tasks, truncated = tasks[:limit], true
+		}
+		for _, task := range tasks {
+			localizeTask(task, loc)
+		}
+		list, count = tasks, len(tasks)
+	} else {
+		summaries, err := h.service.ListTaskSummaries(filter)
+		if err != nil {
+			h.writeError(w, "retrieve tasks", err)
 			return
 		}
+		if limit > 0 && len(summaries) > limit {
+			summaries, truncated = summaries[:limit], true
+		}
+		list, count = summaries, len(summaries)
+	}
 
-		//All other errors are internal 
-		http.Error(w, fmt.Sprintf("failed to retrieve task: %v", err), http.StatusInternalServerError)
+	setCacheControl(w, h.cache.List)
+	if truncated {
+		w.Header().Set(resultTruncatedHeader, "true")
+		if implicit {
+			w.Header().Set("Warning", fmt.Sprintf(`299 - "result truncated to %d tasks; pass ?limit= or narrow the filters"`, limit))
+		}
+	}
+	if count == 0 && h.preferNoContent(r) {
+		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(task)
+	h.respond(w, r, http.StatusOK, list)
 }
 
-// GetAllTasks handles GET requests to retrieve all tasks
-func (h *TaskHandler) GetAllTasks(w http.ResponseWriter, r *http.Request) {
-	tasks, err := h.service.GetAllTasks()
+// resultTruncatedHeader marks a list response that stops short of every matching task
+const resultTruncatedHeader = "X-Result-Truncated"
+
+// listPageLimit returns how many tasks the list endpoint may return: ?limit capped at the
+// handler's maximum, or the default cap when ?limit is absent, in which case implicit is true.
+// Zero means no cap.
+func (h *TaskHandler) listPageLimit(r *http.Request) (limit int, implicit bool, err error) {
+	limit, err = parseLimitParam(r)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("failed to retrieve tasks: %v", err), http.StatusInternalServerError)
+		return 0, false, err
+	}
+	if limit == 0 {
+		return h.listLimit, true, nil
+	}
+	if h.maxListLimit > 0 && limit > h.maxListLimit {
+		limit = h.maxListLimit
+	}
+	return limit, false, nil
+}
+
+// fullParam asks the list endpoint for complete tasks (?full=true) or summaries (?full=false)
+const fullParam = "full"
+
+// wantFullList reports whether the list endpoint should return complete tasks rather than
+// summaries, honouring ?full over the handler's default
+func (h *TaskHandler) wantFullList(r *http.Request) (bool, error) {
+	raw := r.URL.Query().Get(fullParam)
+	if raw == "" {
+		return !h.summaryList, nil
+	}
+	return h.parseBool(r, fullParam)
+}
+
+// BatchGetTasks handles POST requests that fetch several tasks by ID in one call.
+// It always responds 200; IDs that don't exist are listed under "missing".
+func (h *TaskHandler) BatchGetTasks(w http.ResponseWriter, r *http.Request) {
+	// The request and the "missing" list are integer IDs, which uuid mode keeps private
+	if h.idMode == IDModeUUID {
+		h.writeCodedError(w, http.StatusBadRequest, CodeNotSupported, "batch-get takes integer IDs and is not available when ID_MODE=uuid")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(tasks)
+	loc, err := parseTimezoneParam(r)
+	if err != nil {
+		h.writeParamError(w, err)
+		return
+	}
+
+	var req models.BatchGetRequest
+	if err := h.decodeJSON(w, r, &req); err != nil {
+		h.writeDecodeError(w, err)
+		return
+	}
+
+	result, err := h.service.BatchGetTasks(&req)
+	if err != nil {
+		h.writeError(w, "get tasks", err)
+		return
+	}
+	for _, task := range result.Found {
+		localizeTask(task, loc)
+	}
+
+	h.respond(w, r, http.StatusOK, result)
 }
 
-// UpdateTask handles PUT requests to update an existing task by ID
-func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	idStr := vars["id"]
-	id, err := strconv.Atoi(idStr)
+// GetChanges handles GET requests for the incremental sync feed.
+// ?since is required on the first page; later pages pass the previous response's next_cursor
+// as ?cursor instead. ?limit caps the page size.
+func (h *TaskHandler) GetChanges(w http.ResponseWriter, r *http.Request) {
+	cursor := r.URL.Query().Get("cursor")
+	var since time.Time
+	if cursor == "" {
+		var err error
+		if since, err = parseTimeParam(r, "since"); err != nil {
+			h.writeParamError(w, err)
+			return
+		}
+	}
+	limit, err := parseLimitParam(r)
 	if err != nil {
-		http.Error(w, "invalid task ID format", http.StatusBadRequest)
+		h.writeParamError(w, err)
 		return
 	}
 
-	var req models.UpdateTaskRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+	changes, err := h.service.GetChanges(since, cursor, limit)
+	if err != nil {
+		h.writeError(w, "get changes", err)
 		return
 	}
 
-	task, err := h.service.UpdateTask(id, &req)
+	h.respond(w, r, http.StatusOK, changes)
+}
+
+// GetRecentTasks handles GET requests for the most recently updated tasks (?limit=n)
+func (h *TaskHandler) GetRecentTasks(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseLimitParam(r)
 	if err != nil {
-		// Distinguish between "not found", "invalid status", and other errors
-		if err.Error() == fmt.Sprintf("task with ID %d not found: sql: no rows in result set", id) || err.Error() == fmt.Sprintf("task with ID %d not found", id) {
-			http.Error(w, "task not found", http.StatusNotFound)
+		h.writeParamError(w, err)
+		return
+	}
+	loc, err := parseTimezoneParam(r)
+	if err != nil {
+		h.writeParamError(w, err)
+		return
+	}
+
+	tasks, err := h.service.GetRecentTasks(limit)
+	if err != nil {
+		h.writeError(w, "retrieve recent tasks", err)
+		return
+	}
+	for _, task := range tasks {
+		localizeTask(task, loc)
+	}
+
+	h.respond(w, r, http.StatusOK, tasks)
+}
+
+// GetOldestPendingTask handles GET requests for the pending task that has waited longest, with
+// its age in seconds. It responds 204 No Content when nothing is pending.
+func (h *TaskHandler) GetOldestPendingTask(w http.ResponseWriter, r *http.Request) {
+	loc, err := parseTimezoneParam(r)
+	if err != nil {
+		h.writeParamError(w, err)
+		return
+	}
+
+	oldest, err := h.service.OldestPendingTask()
+	if err != nil {
+		if errors.Is(err, repository.ErrNoTaskAvailable) {
+			w.Header().Set("Cache-Control", noStore)
+			w.WriteHeader(http.StatusNoContent)
 			return
 		}
-		if err.Error() == "invalid status value" {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		h.writeError(w, "get oldest pending task", err)
+		return
+	}
+	localizeTask(oldest.Task, loc)
+
+	h.respond(w, r, http.StatusOK, oldest)
+}
+
+// GetRandomTask handles GET requests for one task picked at random from those matching the list
+// filters (?status=, ?assignee=, metadata and creation time). It responds 204 No Content when
+// none match.
+func (h *TaskHandler) GetRandomTask(w http.ResponseWriter, r *http.Request) {
+	filter, err := h.parseListFilter(r)
+	if err != nil {
+		h.writeCodedError(w, http.StatusBadRequest, CodeInvalidParam, err.Error())
+		return
+	}
+	loc, err := parseTimezoneParam(r)
+	if err != nil {
+		h.writeParamError(w, err)
+		return
+	}
+
+	task, err := h.service.RandomTask(filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoTaskAvailable) {
+			w.Header().Set("Cache-Control", noStore)
+			w.WriteHeader(http.StatusNoContent)
 			return
 		}
-		http.Error(w, fmt.Sprintf("failed to update task: %v", err), http.StatusInternalServerError)
+		h.writeError(w, "get random task", err)
+		return
+	}
+	localizeTask(task, loc)
+
+	h.respond(w, r, http.StatusOK, task)
+}
+
+// GetDailyMetrics handles GET requests for /api/tasks/metrics/daily?from=YYYY-MM-DD&to=YYYY-MM-DD,
+// listing how many tasks were created and completed on each day of the range
+func (h *TaskHandler) GetDailyMetrics(w http.ResponseWriter, r *http.Request) {
+	from, err := parseDateParam(r, "from")
+	if err != nil {
+		h.writeParamError(w, err)
+		return
+	}
+	to, err := parseDateParam(r, "to")
+	if err != nil {
+		h.writeParamError(w, err)
+		return
+	}
+
+	metrics, err := h.service.DailyMetrics(from, to)
+	if err != nil {
+		h.writeError(w, "get metrics", err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(task)
+	h.respond(w, r, http.StatusOK, metrics)
 }
 
-// DeleteTask handles DELETE requests to remove a task by ID
+// GetWorkload handles GET requests for /api/tasks/workload, counting each assignee's tasks by
+// status. ?status= narrows the count as it does for the list endpoint.
+func (h *TaskHandler) GetWorkload(w http.ResponseWriter, r *http.Request) {
+	filter, err := h.parseListFilter(r)
+	if err != nil {
+		h.writeCodedError(w, http.StatusBadRequest, CodeInvalidParam, err.Error())
+		return
+	}
+
+	workload, err := h.service.Workload(filter.Statuses)
+	if err != nil {
+		h.writeError(w, "get workload", err)
+		return
+	}
+
+	h.respond(w, r, http.StatusOK, workload)
+}
+
+// UpdateTask handles PUT requests to update an existing task by ID
+func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
+	id, err := h.taskIDParam(r)
+	if err != nil {
+		h.writeIDError(w, err)
+		return
+	}
+
+	version, err := requestBodyVersion(w, r)
+	if err != nil {
+		h.writeCodedError(w, http.StatusBadRequest, CodeUnsupportedVersion, err.Error())
+		return
+	}
+	req, err := version.update(h, w, r)
+	if err != nil {
+		h.writeDecodeError(w, err)
+		return
+	}
+	req.Actor = r.Header.Get(ActorHeader)
+
+	var task *models.Task
+	status := http.StatusOK
+	if h.putCreates {
+		var created bool
+		task, created, err = h.service.PutTask(id, req)
+		if created {
+			status = http.StatusCreated
+		}
+	} else {
+		task, err = h.service.UpdateTask(id, req)
+	}
+	if err != nil {
+		// Distinguish between "not found", "invalid status", and other errors
+		h.writeError(w, "update task", err)
+		return
+	}
+
+	h.respond(w, r, status, task)
+}
+
+// PatchTask handles PATCH requests carrying a JSON Merge Patch (RFC 7386): keys present in the
+// body are set, an explicit null clears a nullable field, and absent keys are left alone.
+func (h *TaskHandler) PatchTask(w http.ResponseWriter, r *http.Request) {
+	id, err := h.taskIDParam(r)
+	if err != nil {
+		h.writeIDError(w, err)
+		return
+	}
+
+	if ct := r.Header.Get("Content-Type"); ct != "" && !isJSONContentType(ct) {
+		h.writeCodedError(w, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, "Content-Type must be application/merge-patch+json")
+		return
+	}
+
+	var raw map[string]json.RawMessage
+	if err := h.decodeJSON(w, r, &raw); err != nil {
+		h.writeDecodeError(w, err)
+		return
+	}
+	patch, err := parseMergePatch(raw)
+	if err != nil {
+		h.writeCodedError(w, http.StatusBadRequest, CodeInvalidPatch, err.Error())
+		return
+	}
+	patch.Actor = r.Header.Get(ActorHeader)
+
+	task, err := h.service.PatchTask(id, patch)
+	if err != nil {
+		h.writeError(w, "patch task", err)
+		return
+	}
+
+	h.respond(w, r, http.StatusOK, task)
+}
+
+// GetNextTransitions handles GET requests for /api/tasks/{id}/next-allowed-transitions, listing
+// the statuses the task can move to according to the workflow UpdateTask and PatchTask enforce
+func (h *TaskHandler) GetNextTransitions(w http.ResponseWriter, r *http.Request) {
+	id, err := h.taskIDParam(r)
+	if err != nil {
+		h.writeIDError(w, err)
+		return
+	}
+
+	next, err := h.service.NextTransitions(id)
+	if err != nil {
+		h.writeError(w, "get transitions", err)
+		return
+	}
+
+	h.respond(w, r, http.StatusOK, next)
+}
+
+// TransitionTask handles POST requests that move a task to another status, e.g.
+// {"to": "in_progress", "note": "picked up"}. The X-Actor header names who made the change for
+// the audit log. A move the workflow forbids gets 409 Conflict.
+func (h *TaskHandler) TransitionTask(w http.ResponseWriter, r *http.Request) {
+	id, err := h.taskIDParam(r)
+	if err != nil {
+		h.writeIDError(w, err)
+		return
+	}
+
+	var req models.TransitionTaskRequest
+	if err := h.decodeJSON(w, r, &req); err != nil {
+		h.writeDecodeError(w, err)
+		return
+	}
+	req.Actor = r.Header.Get(ActorHeader)
+
+	task, err := h.service.TransitionTask(id, &req)
+	if err != nil {
+		h.writeError(w, "transition task", err)
+		return
+	}
+
+	h.respond(w, r, http.StatusOK, task)
+}
+
+// SnoozeTask handles POST requests that push a task's due date back, by {"duration": "48h"} or
+// to {"until": "2024-06-01"}. The X-Actor header names who snoozed it for the audit log.
+// Completed tasks get 409 Conflict.
+func (h *TaskHandler) SnoozeTask(w http.ResponseWriter, r *http.Request) {
+	id, err := h.taskIDParam(r)
+	if err != nil {
+		h.writeIDError(w, err)
+		return
+	}
+
+	var req models.SnoozeTaskRequest
+	if err := h.decodeJSON(w, r, &req); err != nil {
+		h.writeDecodeError(w, err)
+		return
+	}
+	req.Actor = r.Header.Get(ActorHeader)
+
+	task, err := h.service.SnoozeTask(id, &req)
+	if err != nil {
+		h.writeError(w, "snooze task", err)
+		return
+	}
+
+	h.respond(w, r, http.StatusOK, task)
+}
+
+// DeleteTask handles DELETE requests to remove a task by ID. The task is soft-deleted so that
+// it still appears, flagged as deleted, in the changes feed.
 func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	idStr := vars["id"]
-	id, err := strconv.Atoi(idStr)
+	id, err := h.taskIDParam(r)
 	if err != nil {
-		http.Error(w, "invalid task ID format", http.StatusBadRequest)
+		h.writeIDError(w, err)
 		return
 	}
 
-	err = h.service.DeleteTask(id)
+	representation, err := deleteReturnsRepresentation(r)
 	if err != nil {
-		if err.Error() == fmt.Sprintf("task with ID %d not found for deletion", id) {
-			http.Error(w, err.Error(), http.StatusNotFound)
+		h.writeParamError(w, err)
+		return
+	}
+	req := &models.DeleteTaskRequest{Actor: r.Header.Get(ActorHeader)}
+	if representation {
+		task, err := h.service.DeleteTaskReturning(id, req)
+		if err != nil {
+			h.writeError(w, "delete task", err)
 			return
 		}
-		http.Error(w, fmt.Sprintf("failed to delete task: %v", err), http.StatusInternalServerError)
+		h.respond(w, r, http.StatusOK, task)
+		return
+	}
+
+	err = h.service.DeleteTask(id, req)
+	if err != nil {
+		h.writeError(w, "delete task", err)
 		return
 	}
 
+	w.Header().Set("Cache-Control", noStore)
 	w.WriteHeader(http.StatusNoContent) // 204 No Content for successful deletion
 }
+
+// ClaimTask handles POST requests that hand the oldest pending task to a worker.
+// It responds 204 No Content when there is nothing to claim.
+func (h *TaskHandler) ClaimTask(w http.ResponseWriter, r *http.Request) {
+	var req models.ClaimTaskRequest
+	if err := h.decodeJSON(w, r, &req); err != nil {
+		h.writeDecodeError(w, err)
+		return
+	}
+
+	task, err := h.service.ClaimTask(&req)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoTaskAvailable) {
+			w.Header().Set("Cache-Control", noStore)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		h.writeError(w, "claim task", err)
+		return
+	}
+
+	h.respond(w, r, http.StatusOK, task)
+}
+
+// ReassignTasks handles POST requests that move every task of one assignee to another, e.g.
+// {"from": "alice", "to": "bob"}, responding with how many tasks moved
+func (h *TaskHandler) ReassignTasks(w http.ResponseWriter, r *http.Request) {
+	var req models.ReassignTasksRequest
+	if err := h.decodeJSON(w, r, &req); err != nil {
+		h.writeDecodeError(w, err)
+		return
+	}
+	req.Actor = r.Header.Get(ActorHeader)
+
+	resp, err := h.service.ReassignTasks(&req)
+	if err != nil {
+		h.writeError(w, "reassign tasks", err)
+		return
+	}
+
+	h.respond(w, r, http.StatusOK, resp)
+}
+
+// ReleaseTask handles POST requests that return a claimed task to the queue.
+// The body is optional; a worker_id in it restricts the release to that worker's claim.
+func (h *TaskHandler) ReleaseTask(w http.ResponseWriter, r *http.Request) {
+	id, err := h.taskIDParam(r)
+	if err != nil {
+		h.writeIDError(w, err)
+		return
+	}
+
+	var req models.ReleaseTaskRequest
+	if err := h.decodeJSON(w, r, &req); err != nil && !errors.Is(err, errEmptyBody) {
+		h.writeDecodeError(w, err)
+		return
+	}
+
+	task, err := h.service.ReleaseTask(id, &req)
+	if err != nil {
+		h.writeError(w, "release task", err)
+		return
+	}
+
+	h.respond(w, r, http.StatusOK, task)
+}
+
+// ReopenTask handles POST requests that move a completed task back to work.
+// The body must carry a reason; a task that isn't completed gets 409 Conflict. The X-Actor
+// header names who reopened it for the audit log.
+func (h *TaskHandler) ReopenTask(w http.ResponseWriter, r *http.Request) {
+	id, err := h.taskIDParam(r)
+	if err != nil {
+		h.writeIDError(w, err)
+		return
+	}
+
+	var req models.ReopenTaskRequest
+	if err := h.decodeJSON(w, r, &req); err != nil {
+		h.writeDecodeError(w, err)
+		return
+	}
+	req.Actor = r.Header.Get(ActorHeader)
+
+	task, err := h.service.ReopenTask(id, &req)
+	if err != nil {
+		h.writeError(w, "reopen task", err)
+		return
+	}
+
+	h.respond(w, r, http.StatusOK, task)
+}
+
+// AssignTask handles POST requests that set or clear a task's assignee. The body must hold
+// exactly one key, "assignee", so a misspelt key can't silently unassign the task. The X-Actor
+// header names who made the assignment for the audit log.
+func (h *TaskHandler) AssignTask(w http.ResponseWriter, r *http.Request) {
+	id, err := h.taskIDParam(r)
+	if err != nil {
+		h.writeIDError(w, err)
+		return
+	}
+
+	var raw map[string]json.RawMessage
+	if err := h.decodeJSON(w, r, &raw); err != nil {
+		h.writeDecodeError(w, err)
+		return
+	}
+	value, ok := raw["assignee"]
+	if !ok || len(raw) != 1 {
+		h.writeCodedError(w, http.StatusBadRequest, CodeInvalidBody, `request body must be {"assignee": "<name>"} or {"assignee": null}`)
+		return
+	}
+	var req models.AssignTaskRequest
+	if err := json.Unmarshal(value, &req.Assignee); err != nil {
+		h.writeCodedError(w, http.StatusBadRequest, CodeInvalidAssignee, "assignee must be a string or null")
+		return
+	}
+	req.Actor = r.Header.Get(ActorHeader)
+
+	task, err := h.service.AssignTask(id, &req)
+	if err != nil {
+		h.writeError(w, "assign task", err)
+		return
+	}
+
+	h.respond(w, r, http.StatusOK, task)
+}
+
+// returnParam picks the DELETE response: ?return=minimal (the default) is 204 No Content and
+// ?return=representation is 200 with the deleted task
+const returnParam = "return"
+
+// deleteReturnsRepresentation reports whether a DELETE should answer with the deleted task,
+// asked for by ?return=representation or "Prefer: return=representation". The query parameter
+// wins when both are given.
+func deleteReturnsRepresentation(r *http.Request) (bool, error) {
+	if value := r.URL.Query().Get(returnParam); value != "" {
+		switch strings.ToLower(value) {
+		case "minimal":
+			return false, nil
+		case "representation":
+			return true, nil
+		}
+		return false, &ParamError{Name: returnParam, Value: value, Reason: "must be minimal or representation"}
+	}
+	for _, header := range r.Header.Values("Prefer") {
+		for _, pref := range strings.Split(header, ",") {
+			if strings.EqualFold(strings.TrimSpace(pref), "return=representation") {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// preferNoContent decides whether an empty list should be answered with 204.
+// "Prefer: return=minimal" asks for 204 and "Prefer: return=representation" for 200 [],
+// otherwise the handler's configured default applies.
+func (h *TaskHandler) preferNoContent(r *http.Request) bool {
+	for _, header := range r.Header.Values("Prefer") {
+		for _, pref := range strings.Split(header, ",") {
+			switch strings.ToLower(strings.TrimSpace(pref)) {
+			case "return=minimal":
+				return true
+			case "return=representation":
+				return false
+			}
+		}
+	}
+	return h.emptyListNoContent
+}
+
+// Metadata filters may be written as ?metadata.<key>=<value> or ?meta_<key>=<value>;
+// ?has=<key>[,<key>...] requires the keys to exist
+const (
+	metadataFilterPrefix      = "metadata."
+	metadataShortFilterPrefix = "meta_"
+	metadataHasParam          = "has"
+)
+
+// statusParam filters by status; ?status=<status>[,<status>...] matches any of them
+const statusParam = "status"
+
+// assigneeParam filters by assignee; ?assignee=none lists unassigned tasks instead
+const assigneeParam = "assignee"
+
+// createdAfterParam and createdBeforeParam filter by creation time (RFC 3339 or Unix seconds).
+// Either one replaces the default max-age window, as does ?all=true.
+const (
+	createdAfterParam  = "created_after"
+	createdBeforeParam = "created_before"
+	allParam           = "all"
+)
+
+// updatedByParam lists tasks with an audited change by one actor (as named in X-Actor).
+// updatedAfterParam and updatedBeforeParam bound that actor's changes, or without updated_by
+// the task's updated_at. Like the creation filters, either date replaces the max-age window.
+const (
+	updatedByParam     = "updated_by"
+	updatedAfterParam  = "updated_after"
+	updatedBeforeParam = "updated_before"
+)
+
+// sortParam orders a list by created_at, due_date or completed_at. A bare field name sorts in
+// that field's default direction (see service.DefaultSortDirections), -field descending, and
+// field:asc or field:desc in the direction given. nullsParam is first or last (the default) and
+// places the tasks without a due date or completion time.
+const (
+	sortParam  = "sort"
+	nullsParam = "nulls"
+)
+
+// parseSort reads a ?sort= value into the field to sort by and its direction, which is empty
+// when the value leaves it to the field's default
+func parseSort(raw string) (models.SortField, models.SortOrder, error) {
+	name, order := raw, models.SortOrder("")
+	if strings.HasPrefix(raw, "-") {
+		name, order = raw[1:], models.SortNewestFirst
+	} else if field, dir, ok := strings.Cut(raw, ":"); ok {
+		var err error
+		if order, err = models.ParseSortOrder(dir); err != nil {
+			return "", "", &ParamError{Name: sortParam, Value: raw, Reason: "direction must be asc or desc"}
+		}
+		name = field
+	}
+	field, err := models.ParseSortField(name)
+	if err != nil {
+		return "", "", &ParamError{Name: sortParam, Value: raw, Reason: "want created_at, due_date or completed_at, optionally as -field, field:asc or field:desc"}
+	}
+	return field, order, nil
+}
+
+// localizeTask shows a task's timestamps in loc (from ?tz) instead of UTC. A nil loc is a no-op.
+// Every time.Time field of models.Task must be listed here, or one response mixes offsets.
+func localizeTask(task *models.Task, loc *time.Location) {
+	if loc == nil {
+		return
+	}
+	task.CreatedAt = task.CreatedAt.In(loc)
+	task.UpdatedAt = task.UpdatedAt.In(loc)
+	for _, field := range []**time.Time{&task.LeaseExpiresAt, &task.DeletedAt, &task.StartedAt, &task.CompletedAt} {
+		if *field != nil {
+			t := (*field).In(loc)
+			*field = &t
+		}
+	}
+}
+
+// strictParamsParam opts a single list request into strict query parameter checking
+const strictParamsParam = "strict_params"
+
+// listQueryParams are the exact query parameter names the list endpoint understands.
+// Metadata filters are matched by prefix instead, see isKnownListParam.
+var listQueryParams = map[string]bool{
+	statusParam:         true,
+	assigneeParam:       true,
+	metadataHasParam:    true,
+	strictParamsParam:   true,
+	timezoneParam:       true,
+	linksParam:          true,
+	fullParam:           true,
+	createdAfterParam:   true,
+	createdBeforeParam:  true,
+	updatedByParam:      true,
+	updatedAfterParam:   true,
+	updatedBeforeParam:  true,
+	allParam:            true,
+	limitParam:          true,
+	includeDeletedParam: true,
+	sortParam:           true,
+	nullsParam:          true,
+}
+
+// isKnownListParam reports whether param is understood by the list endpoint
+func isKnownListParam(param string) bool {
+	return listQueryParams[param] ||
+		strings.HasPrefix(param, metadataFilterPrefix) ||
+		strings.HasPrefix(param, metadataShortFilterPrefix)
+}
+
+// unknownListParams returns the sorted names of query parameters the list endpoint would ignore
+func unknownListParams(r *http.Request) []string {
+	var unknown []string
+	for param := range r.URL.Query() {
+		if !isKnownListParam(param) {
+			unknown = append(unknown, param)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// metadataKeyPattern restricts filterable metadata keys. Keys are always sent as query
+// parameters, but a strict whitelist keeps malformed filters from reaching the database at all.
+var metadataKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// parseListFilter builds a ListFilter from the list endpoint's query parameters. With foldStatus,
+// statuses are matched ignoring case and stored in their canonical form.
+func (h *TaskHandler) parseListFilter(r *http.Request) (models.ListFilter, error) {
+	filter := models.ListFilter{}
+	for param, values := range r.URL.Query() {
+		var key string
+		switch {
+		case param == statusParam:
+			for _, value := range values {
+				for _, s := range strings.Split(value, ",") {
+					s = strings.TrimSpace(s)
+					if h.foldStatus {
+						s, _ = models.CanonicalStatus(s)
+					}
+					if !models.IsValidStatus(s) {
+						return filter, fmt.Errorf("invalid status %q in %q filter", s, param)
+					}
+					filter.Statuses = append(filter.Statuses, s)
+				}
+			}
+			continue
+		case param == assigneeParam:
+			assignee := strings.TrimSpace(values[0])
+			if assignee == "" {
+				return filter, fmt.Errorf("%q filter must not be empty", param)
+			}
+			if strings.EqualFold(assignee, models.UnassignedFilterValue) {
+				filter.Unassigned = true
+			} else {
+				filter.Assignee = assignee
+			}
+			continue
+		case param == metadataHasParam:
+			for _, value := range values {
+				for _, k := range strings.Split(value, ",") {
+					k = strings.TrimSpace(k)
+					if !metadataKeyPattern.MatchString(k) {
+						return filter, fmt.Errorf("invalid metadata key %q in %q filter", k, param)
+					}
+					filter.MetadataKeys = append(filter.MetadataKeys, k)
+				}
+			}
+			continue
+		case strings.HasPrefix(param, metadataFilterPrefix):
+			key = strings.TrimPrefix(param, metadataFilterPrefix)
+		case strings.HasPrefix(param, metadataShortFilterPrefix):
+			key = strings.TrimPrefix(param, metadataShortFilterPrefix)
+		default:
+			continue
+		}
+
+		if !metadataKeyPattern.MatchString(key) {
+			return filter, fmt.Errorf("invalid metadata key %q in %q filter", key, param)
+		}
+		if filter.Metadata == nil {
+			filter.Metadata = map[string]string{}
+		}
+		filter.Metadata[key] = values[0]
+	}
+	// Query parameters come from a map; sort so equal requests produce equal filters
+	sort.Strings(filter.Statuses)
+	sort.Strings(filter.MetadataKeys)
+
+	dates := []struct {
+		param string
+		field **time.Time
+	}{
+		{createdAfterParam, &filter.CreatedAfter},
+		{createdBeforeParam, &filter.CreatedBefore},
+		{updatedAfterParam, &filter.UpdatedAfter},
+		{updatedBeforeParam, &filter.UpdatedBefore},
+	}
+	for _, d := range dates {
+		if r.URL.Query().Get(d.param) == "" {
+			continue
+		}
+		t, err := parseTimeParam(r, d.param)
+		if err != nil {
+			return filter, err
+		}
+		*d.field = &t
+	}
+	if raw, ok := r.URL.Query()[updatedByParam]; ok {
+		actor := strings.TrimSpace(raw[0])
+		if actor == "" {
+			return filter, &ParamError{Name: updatedByParam, Value: raw[0], Reason: "must not be empty"}
+		}
+		if len(actor) > service.MaxActorLength {
+			return filter, &ParamError{Name: updatedByParam, Value: raw[0], Reason: fmt.Sprintf("must not exceed %d characters", service.MaxActorLength)}
+		}
+		filter.UpdatedBy = actor
+	}
+	all, err := h.parseBool(r, allParam)
+	if err != nil {
+		return filter, err
+	}
+	filter.All = all
+
+	if raw := r.URL.Query().Get(sortParam); raw != "" {
+		if filter.SortBy, filter.Order, err = parseSort(raw); err != nil {
+			return filter, err
+		}
+	}
+	switch nulls := r.URL.Query().Get(nullsParam); nulls {
+	case "", "last":
+	case "first":
+		filter.NullsFirst = true
+	default:
+		return filter, &ParamError{Name: nullsParam, Value: nulls, Reason: "must be first or last"}
+	}
+	return filter, nil
+}
@@ -0,0 +1,1573 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/cliffdoyle/task-api/internal/repository"
+	"github.com/cliffdoyle/task-api/internal/service"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockTaskService is a mock implementation of the TaskService interface,
+// so handlers can be tested without a repository or database.
+type MockTaskService struct {
+	mock.Mock
+}
+
+// CreateTask mocks the CreateTask method of the service
+func (m *MockTaskService) CreateTask(req *models.CreateTaskRequest) (*models.Task, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// GetTask mocks the GetTask method of the service
+func (m *MockTaskService) GetTask(id int) (*models.Task, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// GetAllTasks mocks the GetAllTasks method of the service
+func (m *MockTaskService) GetAllTasks(filter models.ListFilter) ([]*models.Task, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Task), args.Error(1)
+}
+
+// UpdateTask mocks the UpdateTask method of the service
+func (m *MockTaskService) UpdateTask(id int, req *models.UpdateTaskRequest) (*models.Task, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// DeleteTask mocks the DeleteTask method of the service
+func (m *MockTaskService) DeleteTask(id int, req *models.DeleteTaskRequest) error {
+	args := m.Called(id, req)
+	return args.Error(0)
+}
+
+// PutTask mocks the PutTask method of the service
+func (m *MockTaskService) PutTask(id int, req *models.UpdateTaskRequest) (*models.Task, bool, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*models.Task), args.Bool(1), args.Error(2)
+}
+
+// SnoozeTask mocks the SnoozeTask method of the service
+func (m *MockTaskService) SnoozeTask(id int, req *models.SnoozeTaskRequest) (*models.Task, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// PatchTask mocks the PatchTask method of the service
+func (m *MockTaskService) PatchTask(id int, patch *models.PatchTaskRequest) (*models.Task, error) {
+	args := m.Called(id, patch)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// NextTransitions mocks the NextTransitions method of the service
+func (m *MockTaskService) NextTransitions(id int) (*models.NextTransitionsResponse, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.NextTransitionsResponse), args.Error(1)
+}
+
+// ResolveUUID mocks the ResolveUUID method of the service
+func (m *MockTaskService) ResolveUUID(uuid string) (int, error) {
+	args := m.Called(uuid)
+	return args.Int(0), args.Error(1)
+}
+
+// AssignTask mocks the AssignTask method of the service
+func (m *MockTaskService) AssignTask(id int, req *models.AssignTaskRequest) (*models.Task, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// GetRecentTasks mocks the GetRecentTasks method of the service
+func (m *MockTaskService) GetRecentTasks(limit int) ([]*models.Task, error) {
+	args := m.Called(limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Task), args.Error(1)
+}
+
+// OldestPendingTask mocks the OldestPendingTask method of the service
+func (m *MockTaskService) OldestPendingTask() (*models.OldestPendingResponse, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OldestPendingResponse), args.Error(1)
+}
+
+// RandomTask mocks the RandomTask method of the service
+func (m *MockTaskService) RandomTask(filter models.ListFilter) (*models.Task, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// Workload mocks the Workload method of the service
+func (m *MockTaskService) Workload(statuses []string) ([]*models.AssigneeWorkload, error) {
+	args := m.Called(statuses)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AssigneeWorkload), args.Error(1)
+}
+
+// TransitionTask mocks the TransitionTask method of the service
+func (m *MockTaskService) TransitionTask(id int, req *models.TransitionTaskRequest) (*models.Task, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// ListTaskSummaries mocks the ListTaskSummaries method of the service
+func (m *MockTaskService) ListTaskSummaries(filter models.ListFilter) ([]*models.TaskSummary, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TaskSummary), args.Error(1)
+}
+
+// DailyMetrics mocks the DailyMetrics method of the service
+func (m *MockTaskService) DailyMetrics(from, to models.Date) ([]*models.DailyMetrics, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DailyMetrics), args.Error(1)
+}
+
+// CreateTaskIfNotExists mocks the CreateTaskIfNotExists method of the service
+func (m *MockTaskService) CreateTaskIfNotExists(req *models.CreateTaskRequest) (*models.Task, bool, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*models.Task), args.Bool(1), args.Error(2)
+}
+
+// ReassignTasks mocks the ReassignTasks method of the service
+func (m *MockTaskService) ReassignTasks(req *models.ReassignTasksRequest) (*models.ReassignTasksResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReassignTasksResponse), args.Error(1)
+}
+
+// DeleteTaskReturning mocks the DeleteTaskReturning method of the service
+func (m *MockTaskService) DeleteTaskReturning(id int, req *models.DeleteTaskRequest) (*models.Task, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// CreateTemplate mocks the CreateTemplate method of the service
+func (m *MockTaskService) CreateTemplate(req *models.CreateTemplateRequest) (*models.TaskTemplate, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaskTemplate), args.Error(1)
+}
+
+// GetTemplate mocks the GetTemplate method of the service
+func (m *MockTaskService) GetTemplate(id int) (*models.TaskTemplate, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaskTemplate), args.Error(1)
+}
+
+// ListTemplates mocks the ListTemplates method of the service
+func (m *MockTaskService) ListTemplates() ([]*models.TaskTemplate, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TaskTemplate), args.Error(1)
+}
+
+// DeleteTemplate mocks the DeleteTemplate method of the service
+func (m *MockTaskService) DeleteTemplate(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// CreateTaskFromTemplate mocks the CreateTaskFromTemplate method of the service
+func (m *MockTaskService) CreateTaskFromTemplate(templateID int, overrides *models.CreateTaskRequest) (*models.Task, error) {
+	args := m.Called(templateID, overrides)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// BatchGetTasks mocks the BatchGetTasks method of the service
+func (m *MockTaskService) BatchGetTasks(req *models.BatchGetRequest) (*models.BatchGetResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BatchGetResponse), args.Error(1)
+}
+
+// GetChanges mocks the GetChanges method of the service
+func (m *MockTaskService) GetChanges(since time.Time, cursor string, limit int) (*models.ChangesResponse, error) {
+	args := m.Called(since, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChangesResponse), args.Error(1)
+}
+
+// ClaimTask mocks the ClaimTask method of the service
+func (m *MockTaskService) ClaimTask(req *models.ClaimTaskRequest) (*models.Task, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// ReleaseTask mocks the ReleaseTask method of the service
+func (m *MockTaskService) ReleaseTask(id int, req *models.ReleaseTaskRequest) (*models.Task, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// ReopenTask mocks the ReopenTask method of the service
+func (m *MockTaskService) ReopenTask(id int, req *models.ReopenTaskRequest) (*models.Task, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// ReclaimExpiredLeases mocks the ReclaimExpiredLeases method of the service
+func (m *MockTaskService) ReclaimExpiredLeases() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTaskService) EscalatePriorities() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+// RunMaintenance mocks the RunMaintenance method of the service
+func (m *MockTaskService) RunMaintenance(ctx context.Context, req *models.MaintenanceRequest) (*models.MaintenanceReport, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MaintenanceReport), args.Error(1)
+}
+
+// --- Test Cases for parseListFilter ---
+func TestParseListFilter_Metadata(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/tasks?metadata.team=backend&meta_sprint=12&has=owner,area", nil)
+
+	filter, err := new(TaskHandler).parseListFilter(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ListFilter{
+		Metadata:     map[string]string{"team": "backend", "sprint": "12"},
+		MetadataKeys: []string{"area", "owner"},
+	}, filter)
+}
+
+func TestParseListFilter_Statuses(t *testing.T) {
+	cases := map[string][]string{
+		"status=pending":                        {"pending"},
+		"status=pending,in_progress":            {"in_progress", "pending"},
+		"status=completed&status=pending":       {"completed", "pending"},
+		"status=%20in_progress%20,%20completed": {"completed", "in_progress"},
+	}
+	for query, want := range cases {
+		req := httptest.NewRequest("GET", "/api/tasks?"+query, nil)
+
+		filter, err := new(TaskHandler).parseListFilter(req)
+
+		assert.NoError(t, err, query)
+		assert.Equal(t, want, filter.Statuses, query)
+	}
+}
+
+func TestParseListFilter_InvalidStatus(t *testing.T) {
+	for _, query := range []string{
+		"status=done",
+		"status=pending,done",
+		"status=",
+		"status=pending,",
+	} {
+		req := httptest.NewRequest("GET", "/api/tasks?"+query, nil)
+
+		_, err := new(TaskHandler).parseListFilter(req)
+
+		assert.Error(t, err, query)
+	}
+}
+
+func TestParseListFilter_CaseInsensitiveStatus(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/tasks?status=PENDING,In_Progress", nil)
+
+	filter, err := (&TaskHandler{foldStatus: true}).parseListFilter(req)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"in_progress", "pending"}, filter.Statuses)
+
+	_, err = new(TaskHandler).parseListFilter(req)
+	assert.Error(t, err, "mixed case is rejected unless folding is on")
+
+	_, err = (&TaskHandler{foldStatus: true}).parseListFilter(httptest.NewRequest("GET", "/api/tasks?status=Done", nil))
+	assert.Error(t, err)
+}
+
+func TestParseListFilter_Assignee(t *testing.T) {
+	cases := map[string]models.ListFilter{
+		"assignee=alice":               {Assignee: "alice"},
+		"assignee=%20alice%20":         {Assignee: "alice"},
+		"assignee=none":                {Unassigned: true},
+		"assignee=None":                {Unassigned: true},
+		"assignee=none&status=pending": {Unassigned: true, Statuses: []string{"pending"}},
+	}
+	for query, want := range cases {
+		req := httptest.NewRequest("GET", "/api/tasks?"+query, nil)
+
+		filter, err := new(TaskHandler).parseListFilter(req)
+
+		assert.NoError(t, err, query)
+		assert.Equal(t, want, filter, query)
+	}
+
+	_, err := new(TaskHandler).parseListFilter(httptest.NewRequest("GET", "/api/tasks?assignee=", nil))
+	assert.Error(t, err)
+}
+
+func TestParseListFilter_CreatedRangeAndAll(t *testing.T) {
+	after := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Unix(1717200000, 0).UTC()
+	cases := map[string]models.ListFilter{
+		"created_after=2024-05-01T00:00:00Z":                           {CreatedAfter: &after},
+		"created_before=1717200000":                                    {CreatedBefore: &before},
+		"created_after=2024-05-01T00:00:00Z&created_before=1717200000": {CreatedAfter: &after, CreatedBefore: &before},
+		"all=true":  {All: true},
+		"all=false": {},
+	}
+	for query, want := range cases {
+		filter, err := new(TaskHandler).parseListFilter(httptest.NewRequest("GET", "/api/tasks?"+query, nil))
+
+		assert.NoError(t, err, query)
+		assert.Equal(t, want, filter, query)
+	}
+
+	for _, query := range []string{"created_after=yesterday", "created_before=2024-05-01", "all=maybe"} {
+		_, err := new(TaskHandler).parseListFilter(httptest.NewRequest("GET", "/api/tasks?"+query, nil))
+		assert.Error(t, err, query)
+	}
+}
+
+func TestParseListFilter_UpdatedBy(t *testing.T) {
+	after := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Unix(1717300000, 0).UTC()
+	cases := map[string]models.ListFilter{
+		"updated_by=alice":                   {UpdatedBy: "alice"},
+		"updated_by=%20alice%20":             {UpdatedBy: "alice"},
+		"updated_after=2024-06-01T00:00:00Z": {UpdatedAfter: &after},
+		"updated_by=alice&updated_after=2024-06-01T00:00:00Z&updated_before=1717300000": {UpdatedBy: "alice", UpdatedAfter: &after, UpdatedBefore: &before},
+	}
+	for query, want := range cases {
+		filter, err := new(TaskHandler).parseListFilter(httptest.NewRequest("GET", "/api/tasks?"+query, nil))
+
+		assert.NoError(t, err, query)
+		assert.Equal(t, want, filter, query)
+	}
+
+	for query, param := range map[string]string{
+		"updated_by=":                            "updated_by",
+		"updated_by=" + strings.Repeat("a", 256): "updated_by",
+		"updated_by=alice&updated_after=today":   "updated_after",
+		"updated_before=2024-06-01":              "updated_before",
+	} {
+		_, err := new(TaskHandler).parseListFilter(httptest.NewRequest("GET", "/api/tasks?"+query, nil))
+
+		var paramErr *ParamError
+		if assert.ErrorAs(t, err, &paramErr, query) {
+			assert.Equal(t, param, paramErr.Name, query)
+		}
+	}
+}
+
+func TestParseListFilter_Sort(t *testing.T) {
+	cases := map[string]struct {
+		field      models.SortField
+		order      models.SortOrder
+		nullsFirst bool
+	}{
+		"":                             {},
+		"sort=created_at":              {models.SortByCreatedAt, "", false},
+		"sort=-created_at":             {models.SortByCreatedAt, models.SortNewestFirst, false},
+		"sort=due_date":                {models.SortByDueDate, "", false},
+		"sort=due_date:asc":            {models.SortByDueDate, models.SortOldestFirst, false},
+		"sort=completed_at:desc":       {models.SortByCompletedAt, models.SortNewestFirst, false},
+		"sort=-due_date&nulls=first":   {models.SortByDueDate, models.SortNewestFirst, true},
+		"sort=completed_at&nulls=last": {models.SortByCompletedAt, "", false},
+	}
+	for query, want := range cases {
+		filter, err := new(TaskHandler).parseListFilter(httptest.NewRequest("GET", "/api/tasks?"+query, nil))
+
+		assert.NoError(t, err, query)
+		assert.Equal(t, want.field, filter.SortBy, query)
+		assert.Equal(t, want.order, filter.Order, query)
+		assert.Equal(t, want.nullsFirst, filter.NullsFirst, query)
+	}
+
+	for _, query := range []string{"sort=title", "sort=asc", "sort=created_at,id", "sort=due_date:up", "sort=-due_date:asc"} {
+		_, err := new(TaskHandler).parseListFilter(httptest.NewRequest("GET", "/api/tasks?"+query, nil))
+		assert.ErrorContains(t, err, "sort", query)
+	}
+
+	_, err := new(TaskHandler).parseListFilter(httptest.NewRequest("GET", "/api/tasks?sort=due_date&nulls=middle", nil))
+	assert.ErrorContains(t, err, "nulls")
+}
+
+func TestParseListFilter_InvalidMetadataKey(t *testing.T) {
+	for _, query := range []string{
+		"has=team'--",
+		"has=",
+		"meta_=x",
+		"metadata.a%20b=x",
+	} {
+		req := httptest.NewRequest("GET", "/api/tasks?"+query, nil)
+
+		_, err := new(TaskHandler).parseListFilter(req)
+
+		assert.Error(t, err, query)
+	}
+}
+
+// --- Test Cases for GetAllTasks ---
+func TestGetAllTasks_EmptyListDefaultsToEmptyArray(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("GetAllTasks", models.ListFilter{}).Return([]*models.Task{}, nil)
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.GetAllTasks(rr, httptest.NewRequest("GET", "/api/tasks", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, "[]", rr.Body.String()) // never null
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAllTasks_ListLimits(t *testing.T) {
+	three := []*models.Task{{ID: 3}, {ID: 2}, {ID: 1}}
+	tests := map[string]struct {
+		query         string
+		wantLimit     int // filter.Limit passed to the service
+		returned      []*models.Task
+		wantIDs       int
+		wantTruncated bool
+		wantWarning   bool
+	}{
+		"implicit cap hit":        {wantLimit: 3, returned: three, wantIDs: 2, wantTruncated: true, wantWarning: true},
+		"implicit cap not hit":    {wantLimit: 3, returned: three[:2], wantIDs: 2},
+		"explicit limit":          {query: "?limit=1", wantLimit: 2, returned: three[:2], wantIDs: 1, wantTruncated: true},
+		"explicit limit over max": {query: "?limit=50", wantLimit: 6, returned: three, wantIDs: 3},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockTaskService)
+			h := NewTaskHandler(mockService, WithListLimits(2, 5))
+			mockService.On("GetAllTasks", models.ListFilter{Limit: tc.wantLimit}).Return(tc.returned, nil)
+
+			// Act
+			rr := httptest.NewRecorder()
+			h.GetAllTasks(rr, httptest.NewRequest("GET", "/api/tasks"+tc.query, nil))
+
+			// Assert
+			assert.Equal(t, http.StatusOK, rr.Code)
+			var got []models.Task
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+			assert.Len(t, got, tc.wantIDs)
+			if tc.wantTruncated {
+				assert.Equal(t, "true", rr.Header().Get("X-Result-Truncated"))
+			} else {
+				assert.Empty(t, rr.Header().Get("X-Result-Truncated"))
+			}
+			assert.Equal(t, tc.wantWarning, rr.Header().Get("Warning") != "")
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetAllTasks_EmptyListNoContentMode(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService, WithEmptyListNoContent(true))
+	mockService.On("GetAllTasks", models.ListFilter{}).Return([]*models.Task{}, nil)
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.GetAllTasks(rr, httptest.NewRequest("GET", "/api/tasks", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Empty(t, rr.Body.String())
+}
+
+func TestGetAllTasks_PreferHeaderOverridesDefault(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	mockService.On("GetAllTasks", models.ListFilter{}).Return([]*models.Task{}, nil)
+
+	minimal := httptest.NewRequest("GET", "/api/tasks", nil)
+	minimal.Header.Set("Prefer", "return=minimal")
+	representation := httptest.NewRequest("GET", "/api/tasks", nil)
+	representation.Header.Set("Prefer", "return=representation")
+
+	// Act
+	rrMinimal := httptest.NewRecorder()
+	NewTaskHandler(mockService).GetAllTasks(rrMinimal, minimal)
+	rrRepresentation := httptest.NewRecorder()
+	NewTaskHandler(mockService, WithEmptyListNoContent(true)).GetAllTasks(rrRepresentation, representation)
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, rrMinimal.Code)
+	assert.Equal(t, http.StatusOK, rrRepresentation.Code)
+	assert.JSONEq(t, "[]", rrRepresentation.Body.String())
+}
+
+func TestGetAllTasks_NonEmptyIgnoresNoContentMode(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService, WithEmptyListNoContent(true))
+	mockService.On("GetAllTasks", models.ListFilter{}).Return([]*models.Task{{ID: 1, Title: "Task"}}, nil)
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.GetAllTasks(rr, httptest.NewRequest("GET", "/api/tasks", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"title":"Task"`)
+}
+
+func TestGetAllTasks_SummaryAndFullShapes(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService, WithSummaryList(true))
+	due := models.NewDate(time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC))
+	mockService.On("ListTaskSummaries", models.ListFilter{}).Return([]*models.TaskSummary{{ID: 1, Title: "Task", Status: "pending", Priority: "high", DueDate: &due}}, nil)
+	mockService.On("GetAllTasks", models.ListFilter{}).Return([]*models.Task{{ID: 1, Title: "Task", Description: "Long text", Status: "pending", DueDate: &due}}, nil)
+
+	list := func(url string) map[string]interface{} {
+		rr := httptest.NewRecorder()
+		h.GetAllTasks(rr, httptest.NewRequest("GET", url, nil))
+		require.Equal(t, http.StatusOK, rr.Code)
+		var body []map[string]interface{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		require.Len(t, body, 1)
+		return body[0]
+	}
+
+	// Act
+	summary := list("/api/tasks")
+	full := list("/api/tasks?full=true")
+
+	// Assert
+	assert.Equal(t, map[string]interface{}{"id": float64(1), "title": "Task", "status": "pending", "priority": "high", "due_date": "2024-06-01"}, summary)
+	assert.Equal(t, "Long text", full["description"])
+	assert.Contains(t, full, "created_at")
+}
+
+func TestGetAllTasks_FullParam(t *testing.T) {
+	// Arrange: without WithSummaryList complete tasks are the default
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("ListTaskSummaries", models.ListFilter{}).Return([]*models.TaskSummary{}, nil)
+
+	// Act
+	rrSummary := httptest.NewRecorder()
+	h.GetAllTasks(rrSummary, httptest.NewRequest("GET", "/api/tasks?full=false", nil))
+	rrInvalid := httptest.NewRecorder()
+	h.GetAllTasks(rrInvalid, httptest.NewRequest("GET", "/api/tasks?full=maybe", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rrSummary.Code)
+	assert.Equal(t, "[]\n", rrSummary.Body.String())
+	assert.Equal(t, http.StatusBadRequest, rrInvalid.Code)
+	mockService.AssertNotCalled(t, "GetAllTasks", mock.Anything)
+}
+
+// --- Test Cases for GetDailyMetrics ---
+func TestGetDailyMetrics(t *testing.T) {
+	from := models.NewDate(time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC))
+	to := models.NewDate(time.Date(2024, time.May, 2, 0, 0, 0, 0, time.UTC))
+	cases := map[string]struct {
+		query string
+		want  int
+	}{
+		"ok":           {"from=2024-05-01&to=2024-05-02", http.StatusOK},
+		"missing to":   {"from=2024-05-01", http.StatusBadRequest},
+		"bad from":     {"from=May-1&to=2024-05-02", http.StatusBadRequest},
+		"range denied": {"from=2024-05-02&to=2024-05-01", http.StatusBadRequest},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockTaskService)
+			h := NewTaskHandler(mockService)
+			mockService.On("DailyMetrics", from, to).Return([]*models.DailyMetrics{{Date: from, Created: 2}, {Date: to, Completed: 1}}, nil)
+			mockService.On("DailyMetrics", to, from).Return(nil, fmt.Errorf("%w: to must not be before from", service.ErrInvalidDateRange))
+
+			// Act
+			rr := httptest.NewRecorder()
+			h.GetDailyMetrics(rr, httptest.NewRequest("GET", "/api/tasks/metrics/daily?"+tc.query, nil))
+
+			// Assert
+			assert.Equal(t, tc.want, rr.Code)
+			if tc.want == http.StatusOK {
+				assert.JSONEq(t, `[{"date":"2024-05-01","created":2,"completed":0},{"date":"2024-05-02","created":0,"completed":1}]`, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetWorkload(t *testing.T) {
+	alice := "alice"
+	cases := map[string]struct {
+		query    string
+		statuses []string
+		want     int
+	}{
+		"all statuses":   {"", nil, http.StatusOK},
+		"status filter":  {"?status=pending,in_progress", []string{"in_progress", "pending"}, http.StatusOK},
+		"unknown status": {"?status=done", nil, http.StatusBadRequest},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockTaskService)
+			h := NewTaskHandler(mockService)
+			mockService.On("Workload", tc.statuses).Return([]*models.AssigneeWorkload{
+				{Assignee: &alice, Pending: 3, InProgress: 1},
+				{Pending: 2},
+			}, nil)
+
+			// Act
+			rr := httptest.NewRecorder()
+			h.GetWorkload(rr, httptest.NewRequest("GET", "/api/tasks/workload"+tc.query, nil))
+
+			// Assert
+			assert.Equal(t, tc.want, rr.Code)
+			if tc.want == http.StatusOK {
+				assert.JSONEq(t, `[
+					{"assignee":"alice","pending":3,"in_progress":1,"completed":0},
+					{"assignee":null,"pending":2,"in_progress":0,"completed":0}]`, rr.Body.String())
+				mockService.AssertExpectations(t)
+			} else {
+				mockService.AssertNotCalled(t, "Workload", mock.Anything)
+			}
+		})
+	}
+}
+
+// --- Test Cases for ClaimTask / ReleaseTask ---
+func TestClaimTask_NoneAvailable(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("ClaimTask", &models.ClaimTaskRequest{WorkerID: "w1"}).
+		Return(nil, fmt.Errorf("failed to claim task: %w", repository.ErrNoTaskAvailable))
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.ClaimTask(rr, httptest.NewRequest("POST", "/api/tasks/claim", strings.NewReader(`{"worker_id":"w1"}`)))
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+// --- Test Cases for GetOldestPendingTask ---
+func TestGetOldestPendingTask(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("OldestPendingTask").
+		Return(&models.OldestPendingResponse{Task: &models.Task{ID: 3, Title: "Stuck", Status: "pending"}, AgeSeconds: 7200}, nil)
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.GetOldestPendingTask(rr, httptest.NewRequest("GET", "/api/tasks/oldest-pending", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var body struct {
+		Task       models.Task `json:"task"`
+		AgeSeconds int64       `json:"age_seconds"`
+	}
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+	assert.Equal(t, 3, body.Task.ID)
+	assert.Equal(t, int64(7200), body.AgeSeconds)
+}
+
+func TestGetOldestPendingTask_NonePending(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("OldestPendingTask").
+		Return(nil, fmt.Errorf("failed to get oldest pending task from repository: %w", repository.ErrNoTaskAvailable))
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.GetOldestPendingTask(rr, httptest.NewRequest("GET", "/api/tasks/oldest-pending", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Empty(t, rr.Body.String())
+}
+
+// --- Test Cases for GetRandomTask ---
+func TestGetRandomTask(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	filter := models.ListFilter{Statuses: []string{"pending"}, Assignee: "bob"}
+	mockService.On("RandomTask", filter).Return(&models.Task{ID: 8, Title: "Pick me", Status: "pending"}, nil)
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.GetRandomTask(rr, httptest.NewRequest("GET", "/api/tasks/random?status=pending&assignee=bob", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var task models.Task
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&task))
+	assert.Equal(t, 8, task.ID)
+	assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+}
+
+func TestGetRandomTask_NoneMatch(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("RandomTask", models.ListFilter{Statuses: []string{"pending"}}).
+		Return(nil, fmt.Errorf("failed to get random task from repository: %w", repository.ErrNoTaskAvailable))
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.GetRandomTask(rr, httptest.NewRequest("GET", "/api/tasks/random?status=pending", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Empty(t, rr.Body.String())
+}
+
+func TestGetRandomTask_InvalidFilter(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.GetRandomTask(rr, httptest.NewRequest("GET", "/api/tasks/random?status=done", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNotCalled(t, "RandomTask", mock.Anything)
+}
+
+func TestReleaseTask_EmptyBodyAllowed(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("ReleaseTask", 7, &models.ReleaseTaskRequest{}).Return(&models.Task{ID: 7, Status: "pending"}, nil)
+
+	req := httptest.NewRequest("POST", "/api/tasks/7/release", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "7"})
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.ReleaseTask(rr, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestReleaseTask_NotClaimedConflict(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("ReleaseTask", 7, &models.ReleaseTaskRequest{}).
+		Return(nil, fmt.Errorf("failed to release task: %w", repository.ErrTaskNotClaimed))
+
+	req := mux.SetURLVars(httptest.NewRequest("POST", "/api/tasks/7/release", nil), map[string]string{"id": "7"})
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.ReleaseTask(rr, req)
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, rr.Code)
+}
+
+// --- Test Cases for strict query parameters ---
+func TestGetAllTasks_UnknownParamsIgnoredByDefault(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("GetAllTasks", models.ListFilter{}).Return([]*models.Task{}, nil)
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.GetAllTasks(rr, httptest.NewRequest("GET", "/api/tasks?statuss=pending", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAllTasks_StrictParamsRejectsUnknown(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.GetAllTasks(rr, httptest.NewRequest("GET", "/api/tasks?strict_params=true&statuss=pending&limt=5&meta_team=a", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "unrecognized query parameters: limt, statuss\n", rr.Body.String())
+	mockService.AssertNotCalled(t, "GetAllTasks", mock.Anything)
+}
+
+func TestGetAllTasks_StrictParamsConfigured(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService, WithStrictParams(true))
+	mockService.On("GetAllTasks", mock.Anything).Return([]*models.Task{}, nil)
+
+	// Act
+	rrUnknown := httptest.NewRecorder()
+	h.GetAllTasks(rrUnknown, httptest.NewRequest("GET", "/api/tasks?statuss=pending", nil))
+	rrKnown := httptest.NewRecorder()
+	h.GetAllTasks(rrKnown, httptest.NewRequest("GET", "/api/tasks?has=team&metadata.area=api", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, rrUnknown.Code)
+	assert.Equal(t, http.StatusOK, rrKnown.Code)
+}
+
+// --- Test Cases for BatchGetTasks ---
+func TestBatchGetTasks_Success(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("BatchGetTasks", &models.BatchGetRequest{IDs: []int{1, 99}}).
+		Return(&models.BatchGetResponse{Found: []*models.Task{{ID: 1, Title: "One"}}, Missing: []int{99}}, nil)
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.BatchGetTasks(rr, httptest.NewRequest("POST", "/api/tasks/batch-get", strings.NewReader(`{"ids":[1,99]}`)))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var result models.BatchGetResponse
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&result))
+	assert.Len(t, result.Found, 1)
+	assert.Equal(t, []int{99}, result.Missing)
+}
+
+func TestBatchGetTasks_InvalidBatch(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("BatchGetTasks", &models.BatchGetRequest{IDs: []int{}}).
+		Return(nil, fmt.Errorf("%w: ids must not be empty", service.ErrInvalidBatch))
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.BatchGetTasks(rr, httptest.NewRequest("POST", "/api/tasks/batch-get", strings.NewReader(`{"ids":[]}`)))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+// --- Test Cases for ?tz ---
+func TestGetTask_TimezoneParam(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	created := time.Date(2024, 5, 1, 14, 3, 0, 0, time.UTC)
+	task := func() *models.Task {
+		at := func(hour int) *time.Time {
+			t := created.Add(time.Duration(hour) * time.Hour)
+			return &t
+		}
+		return &models.Task{ID: 1, CreatedAt: created, UpdatedAt: *at(5),
+			LeaseExpiresAt: at(1), StartedAt: at(2), CompletedAt: at(3), DeletedAt: at(4)}
+	}
+	mockService.On("GetTask", 1).Return(task(), nil).Once()
+	mockService.On("GetTask", 1).Return(task(), nil).Once()
+
+	// Act
+	rrUTC := httptest.NewRecorder()
+	h.GetTask(rrUTC, mux.SetURLVars(httptest.NewRequest("GET", "/api/tasks/1", nil), map[string]string{"id": "1"}))
+	rrNY := httptest.NewRecorder()
+	h.GetTask(rrNY, mux.SetURLVars(httptest.NewRequest("GET", "/api/tasks/1?tz=America/New_York", nil), map[string]string{"id": "1"}))
+
+	// Assert
+	assert.Contains(t, rrUTC.Body.String(), `"created_at":"2024-05-01T14:03:00Z"`)
+	assert.Contains(t, rrNY.Body.String(), `"created_at":"2024-05-01T10:03:00-04:00"`)
+	assert.Contains(t, rrNY.Body.String(), `"lease_expires_at":"2024-05-01T11:03:00-04:00"`)
+	assert.Contains(t, rrNY.Body.String(), `"started_at":"2024-05-01T12:03:00-04:00"`)
+	assert.Contains(t, rrNY.Body.String(), `"completed_at":"2024-05-01T13:03:00-04:00"`)
+	assert.Contains(t, rrNY.Body.String(), `"deleted_at":"2024-05-01T14:03:00-04:00"`)
+	assert.Contains(t, rrNY.Body.String(), `"updated_at":"2024-05-01T15:03:00-04:00"`)
+	assert.NotContains(t, rrNY.Body.String(), `Z"`, "no timestamp is left in UTC")
+}
+
+func TestGetAllTasks_UnknownTimezone(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.GetAllTasks(rr, httptest.NewRequest("GET", "/api/tasks?tz=Nowhere/Special", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNotCalled(t, "GetAllTasks", mock.Anything)
+}
+
+// --- Test Cases for GetChanges ---
+func TestGetChanges_RequiresSinceOrCursor(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+
+	for _, target := range []string{"/api/tasks/changes", "/api/tasks/changes?since=yesterday", "/api/tasks/changes?since=2024-05-01T00:00:00Z&limit=0"} {
+		// Act
+		rr := httptest.NewRecorder()
+		h.GetChanges(rr, httptest.NewRequest("GET", target, nil))
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, rr.Code, target)
+	}
+	mockService.AssertNotCalled(t, "GetChanges", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// --- Test Cases for GetRecentTasks ---
+func TestGetRecentTasks_Limit(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("GetRecentTasks", 0).Return([]*models.Task{{ID: 2}, {ID: 1}}, nil)
+	mockService.On("GetRecentTasks", 5).Return([]*models.Task{}, nil)
+	get := func(target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.GetRecentTasks(rr, httptest.NewRequest("GET", target, nil))
+		return rr
+	}
+
+	// Act & Assert
+	rr := get("/api/tasks/recent")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[2, 1]`, idsJSON(t, rr.Body.Bytes()))
+	assert.Equal(t, http.StatusOK, get("/api/tasks/recent?limit=5").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/tasks/recent?limit=0").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/tasks/recent?limit=ten").Code)
+	mockService.AssertExpectations(t)
+}
+
+// idsJSON reduces an encoded task list to a JSON array of its IDs
+func idsJSON(t *testing.T, body []byte) string {
+	var tasks []models.Task
+	assert.NoError(t, json.Unmarshal(body, &tasks))
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	out, _ := json.Marshal(ids)
+	return string(out)
+}
+
+func TestGetChanges_PassesParams(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	mockService.On("GetChanges", since, "", 50).Return(&models.ChangesResponse{Changes: []models.TaskChange{}}, nil)
+	mockService.On("GetChanges", time.Time{}, "abc", 0).Return(nil, service.ErrInvalidCursor)
+
+	// Act
+	rrSince := httptest.NewRecorder()
+	h.GetChanges(rrSince, httptest.NewRequest("GET", "/api/tasks/changes?since=2024-05-01T00:00:00Z&limit=50", nil))
+	rrCursor := httptest.NewRecorder()
+	h.GetChanges(rrCursor, httptest.NewRequest("GET", "/api/tasks/changes?cursor=abc", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rrSince.Code)
+	assert.Contains(t, rrSince.Body.String(), `"changes":[]`)
+	assert.Equal(t, http.StatusBadRequest, rrCursor.Code)
+	mockService.AssertExpectations(t)
+}
+
+// --- Test Cases for duplicate titles ---
+func TestCreateTask_IfNotExists(t *testing.T) {
+	cases := map[string]struct {
+		created bool
+		want    int
+	}{
+		"created":        {true, http.StatusCreated},
+		"already exists": {false, http.StatusOK},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockTaskService)
+			h := NewTaskHandler(mockService)
+			mockService.On("CreateTaskIfNotExists", &models.CreateTaskRequest{Title: "Deploy"}).
+				Return(&models.Task{ID: 4, Title: "Deploy", Status: "pending"}, tc.created, nil)
+
+			// Act
+			rr := httptest.NewRecorder()
+			h.CreateTask(rr, httptest.NewRequest("POST", "/api/tasks?if_not_exists=true", strings.NewReader(`{"title":"Deploy"}`)))
+
+			// Assert
+			assert.Equal(t, tc.want, rr.Code)
+			assert.Contains(t, rr.Body.String(), `"id":4`)
+			mockService.AssertNotCalled(t, "CreateTask", mock.Anything)
+		})
+	}
+}
+
+func TestCreateTask_IfNotExistsInvalid(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.CreateTask(rr, httptest.NewRequest("POST", "/api/tasks?if_not_exists=sure", strings.NewReader(`{"title":"Deploy"}`)))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	mockService.AssertNotCalled(t, "CreateTaskIfNotExists", mock.Anything)
+}
+
+func TestCreateTask_DuplicateTitleConflict(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("CreateTask", &models.CreateTaskRequest{Title: "Taken"}).
+		Return(nil, fmt.Errorf("failed to create task in repository: %w", repository.ErrDuplicateTask))
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.CreateTask(rr, httptest.NewRequest("POST", "/api/tasks", strings.NewReader(`{"title":"Taken"}`)))
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, "a task with this title already exists\n", rr.Body.String())
+}
+
+func TestCreateTask_InvalidDueInDays(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	days := -1
+	mockService.On("CreateTask", &models.CreateTaskRequest{Title: "Plan", DueInDays: &days}).
+		Return(nil, fmt.Errorf("%w: due_in_days must be between 0 and 3650", service.ErrInvalidDueDate))
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.CreateTask(rr, httptest.NewRequest("POST", "/api/tasks", strings.NewReader(`{"title":"Plan","due_in_days":-1}`)))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), "due_in_days")
+}
+
+func TestCreateTask_DescriptionTooLong(t *testing.T) {
+	for name, err := range map[string]error{
+		"service":    fmt.Errorf("%w: must be at most 10000 characters", service.ErrInvalidDescription),
+		"constraint": fmt.Errorf("failed to create task in repository: %w", repository.ErrDescriptionTooLong),
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockTaskService)
+			h := NewTaskHandler(mockService)
+			mockService.On("CreateTask", &models.CreateTaskRequest{Title: "T", Description: "long"}).Return(nil, err)
+
+			// Act
+			rr := httptest.NewRecorder()
+			h.CreateTask(rr, httptest.NewRequest("POST", "/api/tasks", strings.NewReader(`{"title":"T","description":"long"}`)))
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	}
+}
+
+// --- Test Cases for ReopenTask ---
+func TestTransitionTask_StatusCodes(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		want int
+	}{
+		"transitioned":   {nil, http.StatusOK},
+		"not allowed":    {fmt.Errorf("%w: pending -> completed", service.ErrInvalidTransition), http.StatusConflict},
+		"not found":      {fmt.Errorf("task with ID 3 not found: %w", repository.ErrTaskNotFound), http.StatusNotFound},
+		"unknown status": {fmt.Errorf("%w: invalid status value", service.ErrInvalidTransitionRequest), http.StatusBadRequest},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange: the actor comes from the header, not the body
+			mockService := new(MockTaskService)
+			h := NewTaskHandler(mockService)
+			var task *models.Task
+			if tc.err == nil {
+				task = &models.Task{ID: 3, Status: "in_progress"}
+			}
+			want := &models.TransitionTaskRequest{To: "in_progress", Note: "go", Actor: "alice"}
+			mockService.On("TransitionTask", 3, want).Return(task, tc.err)
+
+			// Act
+			body := `{"to":"in_progress","note":"go"}`
+			req := mux.SetURLVars(httptest.NewRequest("POST", "/api/tasks/3/transition", strings.NewReader(body)), map[string]string{"id": "3"})
+			req.Header.Set(ActorHeader, "alice")
+			rr := httptest.NewRecorder()
+			h.TransitionTask(rr, req)
+
+			// Assert
+			assert.Equal(t, tc.want, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSnoozeTask_StatusCodes(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		want int
+	}{
+		"snoozed":      {nil, http.StatusOK},
+		"completed":    {fmt.Errorf("%w: task 3 is done", service.ErrTaskCompleted), http.StatusConflict},
+		"not found":    {fmt.Errorf("failed to snooze task: %w", repository.ErrTaskNotFound), http.StatusNotFound},
+		"bad duration": {fmt.Errorf("%w: duration must be positive", service.ErrInvalidSnooze), http.StatusBadRequest},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange: the actor comes from the header, not the body
+			mockService := new(MockTaskService)
+			h := NewTaskHandler(mockService)
+			var task *models.Task
+			if tc.err == nil {
+				task = &models.Task{ID: 3, Status: "pending"}
+			}
+			want := &models.SnoozeTaskRequest{Duration: "2d", Actor: "alice"}
+			mockService.On("SnoozeTask", 3, want).Return(task, tc.err)
+
+			// Act
+			req := mux.SetURLVars(httptest.NewRequest("POST", "/api/tasks/3/snooze", strings.NewReader(`{"duration":"2d"}`)), map[string]string{"id": "3"})
+			req.Header.Set(ActorHeader, "alice")
+			rr := httptest.NewRecorder()
+			h.SnoozeTask(rr, req)
+
+			// Assert
+			assert.Equal(t, tc.want, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReopenTask_StatusCodes(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		want int
+	}{
+		"reopened":      {nil, http.StatusOK},
+		"not completed": {fmt.Errorf("failed to reopen task: %w", repository.ErrTaskNotCompleted), http.StatusConflict},
+		"not found":     {fmt.Errorf("failed to reopen task: %w", repository.ErrTaskNotFound), http.StatusNotFound},
+		"no reason":     {fmt.Errorf("%w: reason is required", service.ErrInvalidReopenReason), http.StatusBadRequest},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockTaskService)
+			h := NewTaskHandler(mockService)
+			var task *models.Task
+			if tc.err == nil {
+				task = &models.Task{ID: 3, Status: "in_progress", ReopenReason: "flaky"}
+			}
+			mockService.On("ReopenTask", 3, &models.ReopenTaskRequest{Reason: "flaky"}).Return(task, tc.err)
+
+			// Act
+			req := mux.SetURLVars(httptest.NewRequest("POST", "/api/tasks/3/reopen", strings.NewReader(`{"reason":"flaky"}`)), map[string]string{"id": "3"})
+			rr := httptest.NewRecorder()
+			h.ReopenTask(rr, req)
+
+			// Assert
+			assert.Equal(t, tc.want, rr.Code)
+		})
+	}
+}
+
+func TestUpdateTask_WrappedNotFound(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("UpdateTask", 5, &models.UpdateTaskRequest{Title: "T"}).
+		Return(nil, fmt.Errorf("task with ID 5 not found: %w", repository.ErrTaskNotFound))
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.UpdateTask(rr, mux.SetURLVars(httptest.NewRequest("PUT", "/api/tasks/5", strings.NewReader(`{"title":"T"}`)), map[string]string{"id": "5"}))
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestUpdateTask_PutCreates(t *testing.T) {
+	req := &models.UpdateTaskRequest{Title: "T"}
+	tests := map[string]struct {
+		putCreates bool
+		setup      func(m *MockTaskService)
+		want       int
+	}{
+		"off: missing ID is 404": {
+			setup: func(m *MockTaskService) {
+				m.On("UpdateTask", 5, req).Return(nil, fmt.Errorf("task with ID 5 not found: %w", repository.ErrTaskNotFound))
+			},
+			want: http.StatusNotFound,
+		},
+		"on: created": {
+			putCreates: true,
+			setup: func(m *MockTaskService) {
+				m.On("PutTask", 5, req).Return(&models.Task{ID: 5, Title: "T", Status: "pending"}, true, nil)
+			},
+			want: http.StatusCreated,
+		},
+		"on: updated": {
+			putCreates: true,
+			setup: func(m *MockTaskService) {
+				m.On("PutTask", 5, req).Return(&models.Task{ID: 5, Title: "T", Status: "pending"}, false, nil)
+			},
+			want: http.StatusOK,
+		},
+		"on: deleted ID": {
+			putCreates: true,
+			setup: func(m *MockTaskService) {
+				m.On("PutTask", 5, req).Return(nil, false, fmt.Errorf("failed to create task in repository: %w", repository.ErrTaskIDTaken))
+			},
+			want: http.StatusConflict,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockTaskService)
+			tc.setup(mockService)
+			h := NewTaskHandler(mockService, WithPutCreates(tc.putCreates))
+
+			// Act
+			rr := httptest.NewRecorder()
+			h.UpdateTask(rr, mux.SetURLVars(httptest.NewRequest("PUT", "/api/tasks/5", strings.NewReader(`{"title":"T"}`)), map[string]string{"id": "5"}))
+
+			// Assert
+			assert.Equal(t, tc.want, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+// --- Test Cases for AssignTask ---
+// --- Test Cases for ReassignTasks ---
+func TestReassignTasks(t *testing.T) {
+	req := &models.ReassignTasksRequest{From: "alice", To: "bob"}
+	cases := map[string]struct {
+		resp *models.ReassignTasksResponse
+		err  error
+		want int
+	}{
+		"moved":   {&models.ReassignTasksResponse{From: "alice", To: "bob", Moved: 3}, nil, http.StatusOK},
+		"invalid": {nil, fmt.Errorf("%w: from and to must be different", service.ErrInvalidAssignee), http.StatusBadRequest},
+		"failure": {nil, fmt.Errorf("connection refused"), http.StatusInternalServerError},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockTaskService)
+			h := NewTaskHandler(mockService)
+			mockService.On("ReassignTasks", req).Return(tc.resp, tc.err)
+
+			// Act
+			rr := httptest.NewRecorder()
+			h.ReassignTasks(rr, httptest.NewRequest("POST", "/api/tasks/reassign", strings.NewReader(`{"from":"alice","to":"bob"}`)))
+
+			// Assert
+			assert.Equal(t, tc.want, rr.Code)
+			if tc.resp != nil {
+				assert.JSONEq(t, `{"from":"alice","to":"bob","moved":3}`, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestAssignTask_Bodies(t *testing.T) {
+	bob := "bob"
+	cases := map[string]struct {
+		body string
+		req  *models.AssignTaskRequest // expected service call; nil when the body is rejected first
+		err  error
+		want int
+	}{
+		"assign":       {`{"assignee":"bob"}`, &models.AssignTaskRequest{Assignee: &bob}, nil, http.StatusOK},
+		"unassign":     {`{"assignee":null}`, &models.AssignTaskRequest{}, nil, http.StatusOK},
+		"not found":    {`{"assignee":"bob"}`, &models.AssignTaskRequest{Assignee: &bob}, fmt.Errorf("failed to get task from repository: %w", repository.ErrTaskNotFound), http.StatusNotFound},
+		"invalid":      {`{"assignee":"bob"}`, &models.AssignTaskRequest{Assignee: &bob}, fmt.Errorf("%w: too long", service.ErrInvalidAssignee), http.StatusBadRequest},
+		"missing key":  {`{"asignee":"bob"}`, nil, nil, http.StatusBadRequest},
+		"extra key":    {`{"assignee":"bob","title":"x"}`, nil, nil, http.StatusBadRequest},
+		"not a string": {`{"assignee":42}`, nil, nil, http.StatusBadRequest},
+		"empty body":   {``, nil, nil, http.StatusBadRequest},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockTaskService)
+			h := NewTaskHandler(mockService)
+			if tc.req != nil {
+				var task *models.Task
+				if tc.err == nil {
+					task = &models.Task{ID: 3, Title: "T"}
+				}
+				mockService.On("AssignTask", 3, tc.req).Return(task, tc.err)
+			}
+
+			// Act
+			req := mux.SetURLVars(httptest.NewRequest("POST", "/api/tasks/3/assign", strings.NewReader(tc.body)), map[string]string{"id": "3"})
+			rr := httptest.NewRecorder()
+			h.AssignTask(rr, req)
+
+			// Assert
+			assert.Equal(t, tc.want, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+// --- Test Cases for DeleteTask ---
+func TestDeleteTask_ReturnModes(t *testing.T) {
+	deleted := &models.Task{ID: 1, Title: "Gone", Status: "pending"}
+	tests := map[string]struct {
+		query, prefer string
+		wantCode      int
+		wantBody      string
+	}{
+		"default":                {wantCode: http.StatusNoContent},
+		"return=minimal":         {query: "?return=minimal", wantCode: http.StatusNoContent},
+		"return=representation":  {query: "?return=representation", wantCode: http.StatusOK, wantBody: `"title":"Gone"`},
+		"prefer header":          {prefer: "return=representation", wantCode: http.StatusOK, wantBody: `"title":"Gone"`},
+		"query overrides prefer": {query: "?return=minimal", prefer: "return=representation", wantCode: http.StatusNoContent},
+		"unknown return value":   {query: "?return=full", wantCode: http.StatusBadRequest},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockTaskService)
+			h := NewTaskHandler(mockService)
+			byAlice := &models.DeleteTaskRequest{Actor: "alice"}
+			mockService.On("DeleteTask", 1, byAlice).Return(nil)
+			mockService.On("DeleteTaskReturning", 1, byAlice).Return(deleted, nil)
+			req := mux.SetURLVars(httptest.NewRequest("DELETE", "/api/tasks/1"+tc.query, nil), map[string]string{"id": "1"})
+			req.Header.Set(ActorHeader, "alice")
+			if tc.prefer != "" {
+				req.Header.Set("Prefer", tc.prefer)
+			}
+
+			// Act
+			rr := httptest.NewRecorder()
+			h.DeleteTask(rr, req)
+
+			// Assert
+			assert.Equal(t, tc.wantCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tc.wantBody)
+			switch tc.wantCode {
+			case http.StatusOK:
+				mockService.AssertNotCalled(t, "DeleteTask", mock.Anything, mock.Anything)
+			case http.StatusNoContent:
+				mockService.AssertNotCalled(t, "DeleteTaskReturning", mock.Anything, mock.Anything)
+				assert.Empty(t, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestDeleteTask_RepresentationNotFound(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("DeleteTaskReturning", 9, &models.DeleteTaskRequest{}).Return(nil, fmt.Errorf("task with ID 9 not found: %w", repository.ErrTaskNotFound))
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.DeleteTask(rr, mux.SetURLVars(httptest.NewRequest("DELETE", "/api/tasks/9?return=representation", nil), map[string]string{"id": "9"}))
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestDeleteTask_NotFound(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("DeleteTask", 9, &models.DeleteTaskRequest{}).Return(fmt.Errorf("failed to delete task from repository: task with ID 9 not found for deletion: %w", repository.ErrTaskNotFound))
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.DeleteTask(rr, mux.SetURLVars(httptest.NewRequest("DELETE", "/api/tasks/9", nil), map[string]string{"id": "9"}))
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, string(CodeTaskNotFound), rr.Header().Get(ErrorCodeHeader))
+}
+
+// --- Test Cases for status transitions ---
+func TestUpdateTask_DisallowedTransitionConflict(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("UpdateTask", 4, &models.UpdateTaskRequest{Status: "completed"}).
+		Return(nil, fmt.Errorf("%w: pending -> completed", service.ErrInvalidTransition))
+
+	// Act
+	req := mux.SetURLVars(httptest.NewRequest("PUT", "/api/tasks/4", strings.NewReader(`{"status":"completed"}`)), map[string]string{"id": "4"})
+	rr := httptest.NewRecorder()
+	h.UpdateTask(rr, req)
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, "status transition not allowed: pending -> completed\n", rr.Body.String())
+}
+
+func TestCreateTask_DescriptionRequired(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("CreateTask", &models.CreateTaskRequest{Title: "T"}).
+		Return(nil, fmt.Errorf("%w: description is required", service.ErrInvalidDescription))
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.CreateTask(rr, httptest.NewRequest("POST", "/api/tasks", strings.NewReader(`{"title":"T"}`)))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "invalid description: description is required\n", rr.Body.String())
+}
+
+func TestPatchTask_LockedTaskConflict(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("PatchTask", 4, mock.AnythingOfType("*models.PatchTaskRequest")).
+		Return(nil, fmt.Errorf("%w: completed tasks can't be edited (title); reopen the task first", service.ErrTaskLocked))
+
+	// Act
+	req := mux.SetURLVars(httptest.NewRequest("PATCH", "/api/tasks/4", strings.NewReader(`{"title":"Renamed"}`)), map[string]string{"id": "4"})
+	rr := httptest.NewRecorder()
+	h.PatchTask(rr, req)
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), "reopen the task first")
+}
+
+func TestGetNextTransitions_StatusCodes(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("NextTransitions", 1).Return(&models.NextTransitionsResponse{
+		TaskID: 1, Status: "pending", Allowed: []string{"in_progress"}, Blocked: []string{},
+	}, nil)
+	mockService.On("NextTransitions", 2).Return(nil, fmt.Errorf("failed to get task from repository: %w", repository.ErrTaskNotFound))
+	get := func(id string) *httptest.ResponseRecorder {
+		req := mux.SetURLVars(httptest.NewRequest("GET", "/api/tasks/"+id+"/next-allowed-transitions", nil), map[string]string{"id": id})
+		rr := httptest.NewRecorder()
+		h.GetNextTransitions(rr, req)
+		return rr
+	}
+
+	// Act
+	found := get("1")
+	missing := get("2")
+
+	// Assert
+	assert.Equal(t, http.StatusOK, found.Code)
+	assert.JSONEq(t, `{"task_id":1,"status":"pending","allowed":["in_progress"],"blocked":[]}`, found.Body.String())
+	assert.Equal(t, http.StatusNotFound, missing.Code)
+	assert.Equal(t, http.StatusBadRequest, get("abc").Code)
+}
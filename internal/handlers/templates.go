@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+)
+
+// CreateTemplate handles POST /api/templates
+func (h *TaskHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateTemplateRequest
+	if err := h.decodeJSON(w, r, &req); err != nil {
+		h.writeDecodeError(w, err)
+		return
+	}
+
+	tmpl, err := h.service.CreateTemplate(&req)
+	if err != nil {
+		h.writeError(w, "create template", err)
+		return
+	}
+
+	h.respond(w, r, http.StatusCreated, tmpl)
+}
+
+// ListTemplates handles GET /api/templates
+func (h *TaskHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.service.ListTemplates()
+	if err != nil {
+		h.writeError(w, "list templates", err)
+		return
+	}
+
+	h.respond(w, r, http.StatusOK, templates)
+}
+
+// GetTemplate handles GET /api/templates/{id}
+func (h *TaskHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		h.writeParamError(w, err)
+		return
+	}
+
+	tmpl, err := h.service.GetTemplate(id)
+	if err != nil {
+		h.writeError(w, "get template", err)
+		return
+	}
+
+	h.respond(w, r, http.StatusOK, tmpl)
+}
+
+// DeleteTemplate handles DELETE /api/templates/{id}. Tasks already created from the template
+// are not affected.
+func (h *TaskHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		h.writeParamError(w, err)
+		return
+	}
+
+	if err := h.service.DeleteTemplate(id); err != nil {
+		h.writeError(w, "delete template", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateTaskFromTemplate handles POST /api/tasks/from-template/{templateId}. The body is
+// optional; any task fields it sets override the template's defaults.
+func (h *TaskHandler) CreateTaskFromTemplate(w http.ResponseWriter, r *http.Request) {
+	templateID, err := parseIDParam(r, "templateId")
+	if err != nil {
+		h.writeParamError(w, err)
+		return
+	}
+
+	var overrides models.CreateTaskRequest
+	if err := h.decodeJSON(w, r, &overrides); err != nil && !errors.Is(err, errEmptyBody) {
+		h.writeDecodeError(w, err)
+		return
+	}
+	overrides.Actor = r.Header.Get(ActorHeader)
+
+	task, err := h.service.CreateTaskFromTemplate(templateID, &overrides)
+	if err != nil {
+		h.writeError(w, "create task", err)
+		return
+	}
+
+	h.respond(w, r, http.StatusCreated, task)
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/cliffdoyle/task-api/internal/repository"
+	"github.com/cliffdoyle/task-api/internal/service"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateTemplate_InvalidIsBadRequest(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("CreateTemplate", &models.CreateTemplateRequest{Title: "T"}).
+		Return(nil, fmt.Errorf("%w: name is required", service.ErrInvalidTemplate))
+
+	// Act
+	rr := httptest.NewRecorder()
+	h.CreateTemplate(rr, httptest.NewRequest("POST", "/api/templates", strings.NewReader(`{"title":"T"}`)))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "invalid template: name is required\n", rr.Body.String())
+}
+
+func TestCreateTaskFromTemplate(t *testing.T) {
+	tests := map[string]struct {
+		body       string
+		overrides  *models.CreateTaskRequest
+		task       *models.Task
+		err        error
+		wantStatus int
+	}{
+		"no body": {
+			overrides:  &models.CreateTaskRequest{},
+			task:       &models.Task{ID: 1, Title: "Report 2024-05-06", Status: "pending"},
+			wantStatus: http.StatusCreated,
+		},
+		"overrides": {
+			body:       `{"title":"Special","assignee":"bob"}`,
+			overrides:  &models.CreateTaskRequest{Title: "Special", Assignee: "bob"},
+			task:       &models.Task{ID: 1, Title: "Special", Status: "pending", Assignee: "bob"},
+			wantStatus: http.StatusCreated,
+		},
+		"unknown template": {
+			overrides:  &models.CreateTaskRequest{},
+			err:        fmt.Errorf("failed to get template from repository: %w", repository.ErrTemplateNotFound),
+			wantStatus: http.StatusNotFound,
+		},
+		"invalid override": {
+			body:       `{"assignee":"none"}`,
+			overrides:  &models.CreateTaskRequest{Assignee: "none"},
+			err:        fmt.Errorf("%w: \"none\" is reserved", service.ErrInvalidAssignee),
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockTaskService)
+			h := NewTaskHandler(mockService)
+			if tc.err != nil {
+				mockService.On("CreateTaskFromTemplate", 3, tc.overrides).Return(nil, tc.err)
+			} else {
+				mockService.On("CreateTaskFromTemplate", 3, tc.overrides).Return(tc.task, nil)
+			}
+
+			// Act
+			req := mux.SetURLVars(httptest.NewRequest("POST", "/api/tasks/from-template/3", strings.NewReader(tc.body)), map[string]string{"templateId": "3"})
+			rr := httptest.NewRecorder()
+			h.CreateTaskFromTemplate(rr, req)
+
+			// Assert
+			assert.Equal(t, tc.wantStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteTemplate_NotFound(t *testing.T) {
+	// Arrange
+	mockService := new(MockTaskService)
+	h := NewTaskHandler(mockService)
+	mockService.On("DeleteTemplate", 8).Return(fmt.Errorf("failed to delete template from repository: %w", repository.ErrTemplateNotFound))
+
+	// Act
+	req := mux.SetURLVars(httptest.NewRequest("DELETE", "/api/templates/8", nil), map[string]string{"id": "8"})
+	rr := httptest.NewRecorder()
+	h.DeleteTemplate(rr, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, "template not found\n", rr.Body.String())
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeFormat selects how timestamps are written in task responses
+type TimeFormat string
+
+const (
+	// TimeFormatRFC3339 is Go's standard encoding: RFC 3339 with fractional seconds only when non-zero
+	TimeFormatRFC3339 TimeFormat = "rfc3339"
+	// TimeFormatRFC3339Nano always writes nine fractional digits, for fixed-width parsers
+	TimeFormatRFC3339Nano TimeFormat = "rfc3339nano"
+	// TimeFormatUnix writes whole seconds since the Unix epoch as a JSON number
+	TimeFormatUnix TimeFormat = "unix"
+)
+
+// TimeFormatHeader lets a client pick a TimeFormat per request
+const TimeFormatHeader = "Accept-Time-Format"
+
+// rfc3339FixedNano is RFC3339Nano without trailing-zero trimming
+const rfc3339FixedNano = "2006-01-02T15:04:05.000000000Z07:00"
+
+// ParseTimeFormat validates a TimeFormat name (case-insensitive)
+func ParseTimeFormat(s string) (TimeFormat, error) {
+	switch f := TimeFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case TimeFormatRFC3339, TimeFormatRFC3339Nano, TimeFormatUnix:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown time format %q (want rfc3339, rfc3339nano or unix)", s)
+}
+
+// WithTimeFormat sets the default timestamp format for task responses
+func WithTimeFormat(f TimeFormat) Option {
+	return func(h *TaskHandler) {
+		h.timeFormat = f
+	}
+}
+
+// requestTimeFormat returns the format asked for in Accept-Time-Format, falling back to the
+// handler's default when the header is absent or names an unknown format
+func (h *TaskHandler) requestTimeFormat(r *http.Request) TimeFormat {
+	if header := r.Header.Get(TimeFormatHeader); header != "" {
+		if f, err := ParseTimeFormat(header); err == nil {
+			return f
+		}
+	}
+	if h.timeFormat == "" {
+		return TimeFormatRFC3339
+	}
+	return h.timeFormat
+}
+
+// respond writes v as JSON with the given status, adding _links when asked for, rendering
+// timestamps and integer IDs in the requested formats and, in uuid mode, task UUIDs in place of
+// integer IDs
+func (h *TaskHandler) respond(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	// Reads that may be cached set their own policy first; nothing else should be stored
+	if w.Header().Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", noStore)
+	}
+	if h.wantLinks(r) {
+		v = h.addLinks(r, v)
+	}
+	format := h.requestTimeFormat(r)
+	idFormat := h.requestIDFormat(r)
+	if format == TimeFormatRFC3339 && h.idMode != IDModeUUID && idFormat == IDFormatNumber {
+		writeJSON(w, status, v)
+		return
+	}
+
+	data, err := json.Marshal(v)
+	if err == nil && format != TimeFormatRFC3339 {
+		data, err = applyTimeFormat(data, format)
+	}
+	if err == nil && h.idMode == IDModeUUID {
+		data, err = applyUUIDIDs(data)
+	}
+	if err == nil && idFormat == IDFormatString {
+		data, err = applyStringIDs(data)
+	}
+	if err != nil {
+		h.writeCodedError(w, http.StatusInternalServerError, CodeInternal, fmt.Sprintf("failed to encode response: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
+
+// applyTimeFormat re-renders the timestamps in an encoded response. Timestamps are recognised by
+// key: every field holding a time ends in "_at" or "_time" (created_at, server_time, ...).
+// Task metadata is client data and is left exactly as stored.
+func applyTimeFormat(data []byte, format TimeFormat) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // keep IDs and metadata numbers exactly as encoded
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(reformatTimes(doc, format))
+}
+
+// reformatTimes walks a decoded JSON document, converting timestamp fields in place
+func reformatTimes(v interface{}, format TimeFormat) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if key == "metadata" {
+				continue
+			}
+			if s, ok := value.(string); ok && isTimeKey(key) {
+				if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+					v[key] = formatTime(t, format)
+				}
+				continue
+			}
+			v[key] = reformatTimes(value, format)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = reformatTimes(v[i], format)
+		}
+	}
+	return v
+}
+
+// isTimeKey reports whether a JSON key names a timestamp field
+func isTimeKey(key string) bool {
+	return strings.HasSuffix(key, "_at") || strings.HasSuffix(key, "_time")
+}
+
+// formatTime renders t in format; unix yields a number, the others a string
+func formatTime(t time.Time, format TimeFormat) interface{} {
+	switch format {
+	case TimeFormatUnix:
+		return t.Unix()
+	case TimeFormatRFC3339Nano:
+		return t.Format(rfc3339FixedNano)
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// parseTimeValue accepts a timestamp in any TimeFormat: RFC 3339 (with or without fractional
+// seconds) or Unix epoch seconds
+func parseTimeValue(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	secs, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("not an RFC 3339 timestamp or Unix seconds: %q", s)
+	}
+	return time.Unix(secs, 0).UTC(), nil
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeFormat_RoundTrip(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 14, 3, 7, 120000000, time.UTC)
+
+	cases := map[TimeFormat]struct {
+		encoded string
+		parsed  time.Time
+	}{
+		TimeFormatRFC3339:     {`"2024-05-01T14:03:07.12Z"`, ts},
+		TimeFormatRFC3339Nano: {`"2024-05-01T14:03:07.120000000Z"`, ts},
+		TimeFormatUnix:        {`1714572187`, ts.Truncate(time.Second)}, // unix drops sub-second precision
+	}
+	for format, tc := range cases {
+		t.Run(string(format), func(t *testing.T) {
+			encoded, err := json.Marshal(formatTime(ts, format))
+			require.NoError(t, err)
+			assert.Equal(t, tc.encoded, string(encoded))
+
+			var raw interface{}
+			require.NoError(t, json.Unmarshal(encoded, &raw))
+			var s string
+			switch v := raw.(type) {
+			case string:
+				s = v
+			case float64:
+				s = string(encoded)
+			}
+			parsed, err := parseTimeValue(s)
+			require.NoError(t, err)
+			assert.True(t, tc.parsed.Equal(parsed), "%s parsed back as %s", format, parsed)
+		})
+	}
+}
+
+func TestParseTimeFormat(t *testing.T) {
+	f, err := ParseTimeFormat(" UNIX ")
+	assert.NoError(t, err)
+	assert.Equal(t, TimeFormatUnix, f)
+
+	_, err = ParseTimeFormat("iso8601")
+	assert.Error(t, err)
+}
+
+func TestParseTimeValue_Invalid(t *testing.T) {
+	for _, s := range []string{"", "yesterday", "2024-05-01", "1.5"} {
+		_, err := parseTimeValue(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestRespond_TimeFormatHeaderAndDefault(t *testing.T) {
+	created := time.Date(2024, 5, 1, 14, 3, 0, 0, time.UTC)
+	mockService := new(MockTaskService)
+	mockService.On("GetTask", 1).Return(&models.Task{ID: 1, Title: "T", CreatedAt: created, UpdatedAt: created,
+		Metadata: map[string]interface{}{"due_at": "2024-06-01T00:00:00Z", "points": 3}}, nil)
+
+	get := func(h *TaskHandler, header string) map[string]interface{} {
+		req := mux.SetURLVars(httptest.NewRequest("GET", "/api/tasks/1", nil), map[string]string{"id": "1"})
+		if header != "" {
+			req.Header.Set(TimeFormatHeader, header)
+		}
+		rr := httptest.NewRecorder()
+		h.GetTask(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		return body
+	}
+
+	h := NewTaskHandler(mockService)
+	assert.Equal(t, "2024-05-01T14:03:00Z", get(h, "")["created_at"])
+	assert.Equal(t, float64(1714572180), get(h, "unix")["created_at"])
+	assert.Equal(t, "2024-05-01T14:03:00Z", get(h, "bogus")["created_at"], "unknown header values fall back")
+
+	h = NewTaskHandler(mockService, WithTimeFormat(TimeFormatUnix))
+	body := get(h, "")
+	assert.Equal(t, float64(1714572180), body["updated_at"])
+	assert.Equal(t, "2024-05-01T14:03:00.000000000Z", get(h, "rfc3339nano")["created_at"])
+
+	// Metadata is client data and is never rewritten
+	assert.Equal(t, map[string]interface{}{"due_at": "2024-06-01T00:00:00Z", "points": float64(3)}, body["metadata"])
+}
@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"context"
+	"expvar"
+	"log"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/service"
+)
+
+// leasesReclaimed counts tasks returned to the queue because their lease expired.
+// It is published through expvar under "tasks_leases_reclaimed_total".
+var leasesReclaimed = expvar.NewInt("tasks_leases_reclaimed_total")
+
+// LeaseReaper periodically requeues claimed tasks whose worker let the lease expire
+type LeaseReaper struct {
+	service  service.TaskService
+	interval time.Duration
+}
+
+// NewLeaseReaper creates a LeaseReaper that runs every interval
+func NewLeaseReaper(service service.TaskService, interval time.Duration) *LeaseReaper {
+	return &LeaseReaper{service: service, interval: interval}
+}
+
+// Run reclaims expired leases every interval until ctx is cancelled.
+// It blocks, so callers typically run it in its own goroutine.
+func (r *LeaseReaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.RunOnce()
+		}
+	}
+}
+
+// RunOnce performs a single reclaim pass and returns how many tasks were requeued
+func (r *LeaseReaper) RunOnce() int {
+	count, err := r.service.ReclaimExpiredLeases()
+	if err != nil {
+		log.Printf("Lease reaper: %v", err)
+		return 0
+	}
+	if count > 0 {
+		leasesReclaimed.Add(int64(count))
+		log.Printf("Lease reaper: reclaimed %d task(s) with expired leases", count)
+	}
+	return count
+}
@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeReclaimer implements just enough of TaskService for the reaper
+type fakeReclaimer struct {
+	service.TaskService
+	counts []int
+	err    error
+	calls  int
+}
+
+func (f *fakeReclaimer) ReclaimExpiredLeases() (int, error) {
+	f.calls++
+	if f.err != nil {
+		return 0, f.err
+	}
+	if len(f.counts) == 0 {
+		return 0, nil
+	}
+	n := f.counts[0]
+	f.counts = f.counts[1:]
+	return n, nil
+}
+
+func TestLeaseReaper_RunOnceCountsReclaims(t *testing.T) {
+	fake := &fakeReclaimer{counts: []int{3}}
+	reaper := NewLeaseReaper(fake, time.Minute)
+	before := leasesReclaimed.Value()
+
+	assert.Equal(t, 3, reaper.RunOnce())
+	assert.Equal(t, before+3, leasesReclaimed.Value())
+}
+
+func TestLeaseReaper_RunOnceError(t *testing.T) {
+	fake := &fakeReclaimer{err: errors.New("db down")}
+	reaper := NewLeaseReaper(fake, time.Minute)
+
+	assert.Equal(t, 0, reaper.RunOnce())
+}
+
+func TestLeaseReaper_RunStopsOnCancel(t *testing.T) {
+	fake := &fakeReclaimer{}
+	reaper := NewLeaseReaper(fake, time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		reaper.Run(ctx)
+		close(done)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("reaper did not stop after context cancellation")
+	}
+	assert.Greater(t, fake.calls, 0)
+}
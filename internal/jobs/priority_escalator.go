@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"context"
+	"expvar"
+	"log"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/service"
+)
+
+// prioritiesEscalated counts tasks whose priority was raised because they were overdue.
+// It is published through expvar under "tasks_priorities_escalated_total".
+var prioritiesEscalated = expvar.NewInt("tasks_priorities_escalated_total")
+
+// PriorityEscalator periodically raises the priority of overdue tasks
+type PriorityEscalator struct {
+	service  service.TaskService
+	interval time.Duration
+}
+
+// NewPriorityEscalator creates a PriorityEscalator that runs every interval
+func NewPriorityEscalator(service service.TaskService, interval time.Duration) *PriorityEscalator {
+	return &PriorityEscalator{service: service, interval: interval}
+}
+
+// Run escalates overdue tasks every interval until ctx is cancelled.
+// It blocks, so callers typically run it in its own goroutine.
+func (e *PriorityEscalator) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.RunOnce()
+		}
+	}
+}
+
+// RunOnce performs a single escalation pass and returns how many tasks were raised
+func (e *PriorityEscalator) RunOnce() int {
+	count, err := e.service.EscalatePriorities()
+	if err != nil {
+		log.Printf("Priority escalator: %v", err)
+		return 0
+	}
+	if count > 0 {
+		prioritiesEscalated.Add(int64(count))
+		log.Printf("Priority escalator: raised the priority of %d overdue task(s)", count)
+	}
+	return count
+}
@@ -0,0 +1,67 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeEscalator implements just enough of TaskService for the escalator
+type fakeEscalator struct {
+	service.TaskService
+	count int
+	err   error
+	calls int
+}
+
+func (f *fakeEscalator) EscalatePriorities() (int, error) {
+	f.calls++
+	if f.err != nil {
+		return 0, f.err
+	}
+	return f.count, nil
+}
+
+func TestPriorityEscalator_RunOnceCountsEscalations(t *testing.T) {
+	fake := &fakeEscalator{count: 2}
+	escalator := NewPriorityEscalator(fake, time.Minute)
+	before := prioritiesEscalated.Value()
+
+	assert.Equal(t, 2, escalator.RunOnce())
+	assert.Equal(t, before+2, prioritiesEscalated.Value())
+}
+
+func TestPriorityEscalator_RunOnceError(t *testing.T) {
+	fake := &fakeEscalator{err: errors.New("db down")}
+	escalator := NewPriorityEscalator(fake, time.Minute)
+	before := prioritiesEscalated.Value()
+
+	assert.Equal(t, 0, escalator.RunOnce())
+	assert.Equal(t, before, prioritiesEscalated.Value())
+}
+
+func TestPriorityEscalator_RunStopsOnCancel(t *testing.T) {
+	fake := &fakeEscalator{}
+	escalator := NewPriorityEscalator(fake, time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		escalator.Run(ctx)
+		close(done)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("escalator did not stop after context cancellation")
+	}
+	assert.Greater(t, fake.calls, 0)
+}
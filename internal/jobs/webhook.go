@@ -0,0 +1,102 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+)
+
+// webhookQueueSize is how many events may wait for delivery before new ones are dropped
+const webhookQueueSize = 1000
+
+// Webhook delivery counters, published through expvar
+var (
+	webhookDelivered = expvar.NewInt("webhook_events_delivered_total")
+	webhookFailed    = expvar.NewInt("webhook_events_failed_total")
+	webhookDropped   = expvar.NewInt("webhook_events_dropped_total")
+)
+
+// Webhook POSTs each task event as JSON to a configured URL. It implements
+// service.EventPublisher: Publish only queues the event and Run does the delivery.
+// Failed deliveries are logged and counted, not retried.
+type Webhook struct {
+	url    string
+	client *http.Client
+	queue  chan models.TaskEvent
+}
+
+// NewWebhook creates a Webhook that delivers to url, giving up on a request after timeout
+func NewWebhook(url string, timeout time.Duration) *Webhook {
+	return &Webhook{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+		queue:  make(chan models.TaskEvent, webhookQueueSize),
+	}
+}
+
+// Publish queues an event for delivery, dropping it if the queue is full
+func (w *Webhook) Publish(event models.TaskEvent) {
+	select {
+	case w.queue <- event:
+	default:
+		webhookDropped.Add(1)
+		log.Printf("Webhook: queue full, dropped %s", describeEvent(event))
+	}
+}
+
+// Run delivers queued events one at a time, in order, until ctx is cancelled.
+// Events still queued at that point are not delivered.
+func (w *Webhook) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-w.queue:
+			if err := w.deliver(ctx, event); err != nil {
+				webhookFailed.Add(1)
+				log.Printf("Webhook: %s: %v", describeEvent(event), err)
+				continue
+			}
+			webhookDelivered.Add(1)
+		}
+	}
+}
+
+// describeEvent names an event for the log
+func describeEvent(event models.TaskEvent) string {
+	if event.Type == models.EventTasksBatch {
+		return fmt.Sprintf("%s event of %d task events", event.Type, len(event.Events))
+	}
+	return fmt.Sprintf("%s event for task %d", event.Type, event.TaskID)
+}
+
+// deliver POSTs a single event; any non-2xx response is an error
+func (w *Webhook) deliver(ctx context.Context, event models.TaskEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Task-Event", event.Type)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook_DeliversEvents(t *testing.T) {
+	received := make(chan models.TaskEvent, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, models.EventTaskUpdated, r.Header.Get("X-Task-Event"))
+		var event models.TaskEvent
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		received <- event
+	}))
+	defer srv.Close()
+
+	hook := NewWebhook(srv.URL, time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hook.Run(ctx)
+
+	before := webhookDelivered.Value()
+	hook.Publish(models.TaskEvent{
+		Type:    models.EventTaskUpdated,
+		TaskID:  7,
+		Changes: map[string]models.FieldChange{"status": {Old: "pending", New: "completed"}},
+	})
+
+	select {
+	case event := <-received:
+		assert.Equal(t, 7, event.TaskID)
+		assert.Equal(t, models.FieldChange{Old: "pending", New: "completed"}, event.Changes["status"])
+	case <-time.After(time.Second):
+		t.Fatal("webhook was not called")
+	}
+	assert.Eventually(t, func() bool { return webhookDelivered.Value() == before+1 }, time.Second, time.Millisecond)
+}
+
+func TestWebhook_DeliversBatchAsOnePost(t *testing.T) {
+	var posts int
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts++
+		assert.Equal(t, models.EventTasksBatch, r.Header.Get("X-Task-Event"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+	}))
+	defer srv.Close()
+
+	batch := models.TaskEvent{Type: models.EventTasksBatch, Events: []models.TaskEvent{
+		{Type: models.EventTaskUpdated, TaskID: 1}, {Type: models.EventTaskUpdated, TaskID: 2},
+	}}
+	require.NoError(t, NewWebhook(srv.URL, time.Second).deliver(context.Background(), batch))
+
+	assert.Equal(t, 1, posts)
+	assert.NotContains(t, body, "task_id", "a batch has no task of its own")
+	assert.Len(t, body["events"], 2)
+	assert.Equal(t, "tasks.batch event of 2 task events", describeEvent(batch))
+}
+
+func TestWebhook_DeliverErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	err := NewWebhook(srv.URL, time.Second).deliver(context.Background(), models.TaskEvent{Type: models.EventTaskDeleted, TaskID: 1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+}
+
+func TestWebhook_PublishDropsWhenFull(t *testing.T) {
+	hook := NewWebhook("http://unused.invalid", time.Second)
+	before := webhookDropped.Value()
+
+	for i := 0; i < webhookQueueSize+2; i++ {
+		hook.Publish(models.TaskEvent{Type: models.EventTaskCreated, TaskID: i})
+	}
+
+	assert.Equal(t, before+2, webhookDropped.Value())
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+type contextKey string
+
+const clientIPKey contextKey = "client_ip"
+
+// ParseTrustedProxies converts a list of CIDRs or bare IPs into networks.
+// A bare IP is treated as a single-host network (/32 or /128).
+func ParseTrustedProxies(entries []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		nets = append(nets, network)
+	}
+	return nets, nil
+}
+
+// ClientIP returns middleware that resolves the real client IP and stores it in the request context.
+// Forwarding headers are only consulted when the direct peer is a trusted proxy, so clients
+// cannot spoof their address by sending X-Forwarded-For themselves.
+func ClientIP(trusted []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := ResolveClientIP(r, trusted)
+			ctx := context.WithValue(r.Context(), clientIPKey, ip)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ClientIPFromContext returns the IP resolved by the ClientIP middleware, or "" if it did not run
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey).(string)
+	return ip
+}
+
+// ResolveClientIP determines the client IP for a request.
+// X-Forwarded-For is walked from right to left, skipping trusted proxies, and the first
+// untrusted address is returned. X-Real-IP is used when X-Forwarded-For is absent.
+// If the peer is not trusted, or the headers carry nothing usable, RemoteAddr is returned.
+func ResolveClientIP(r *http.Request, trusted []*net.IPNet) string {
+	remote := remoteIP(r.RemoteAddr)
+	if !isTrusted(net.ParseIP(remote), trusted) {
+		return remote
+	}
+
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		hops := strings.Split(xff, ",")
+		var leftmost string
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				// A malformed hop means we can no longer trust anything to its left
+				break
+			}
+			leftmost = ip.String()
+			if !isTrusted(ip, trusted) {
+				return leftmost
+			}
+		}
+		if leftmost != "" {
+			return leftmost
+		}
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+
+	return remote
+}
+
+// remoteIP strips the port from a RemoteAddr value
+func remoteIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// isTrusted reports whether ip falls within any of the trusted networks
+func isTrusted(ip net.IP, trusted []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
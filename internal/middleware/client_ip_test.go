@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRequest(remoteAddr string, headers map[string]string) *http.Request {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = remoteAddr
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+func TestResolveClientIP_UntrustedPeerIgnoresHeaders(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	// A client connecting directly cannot spoof its address
+	req := newRequest("203.0.113.7:5555", map[string]string{"X-Forwarded-For": "1.2.3.4"})
+
+	assert.Equal(t, "203.0.113.7", ResolveClientIP(req, trusted))
+}
+
+func TestResolveClientIP_TrustedPeerUsesForwardedFor(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	// The rightmost untrusted hop is the client; anything left of it is client-controlled
+	req := newRequest("10.0.0.1:443", map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.9, 10.0.0.2"})
+
+	assert.Equal(t, "198.51.100.9", ResolveClientIP(req, trusted))
+}
+
+func TestResolveClientIP_TrustedPeerUsesRealIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.1"})
+	require.NoError(t, err)
+
+	req := newRequest("10.0.0.1:443", map[string]string{"X-Real-IP": "198.51.100.9"})
+
+	assert.Equal(t, "198.51.100.9", ResolveClientIP(req, trusted))
+}
+
+func TestResolveClientIP_MalformedHeaderFallsBack(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	req := newRequest("10.0.0.1:443", map[string]string{"X-Forwarded-For": "not-an-ip"})
+
+	assert.Equal(t, "10.0.0.1", ResolveClientIP(req, trusted))
+}
+
+func TestParseTrustedProxies_Invalid(t *testing.T) {
+	_, err := ParseTrustedProxies([]string{"10.0.0.0/99"})
+	assert.Error(t, err)
+
+	_, err = ParseTrustedProxies([]string{"proxy.local"})
+	assert.Error(t, err)
+}
+
+func TestClientIPMiddleware_SetsContext(t *testing.T) {
+	var got string
+	handler := ClientIP(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = ClientIPFromContext(r.Context())
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), newRequest("192.0.2.10:1234", nil))
+
+	assert.Equal(t, "192.0.2.10", got)
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"expvar"
+	"net/http"
+	"strings"
+)
+
+// inFlightRequests is the number of requests currently being served under the concurrency limit.
+// It is published through expvar under "http_requests_in_flight".
+var inFlightRequests = expvar.NewInt("http_requests_in_flight")
+
+// rejectedRequests counts requests turned away because the limit was reached
+var rejectedRequests = expvar.NewInt("http_requests_rejected_total")
+
+// concurrencyRetryAfter is the Retry-After value, in seconds, sent with a 503
+const concurrencyRetryAfter = "1"
+
+// ConcurrencyLimit returns middleware that serves at most max requests at once. A request that
+// arrives while all slots are taken gets 503 Service Unavailable with Retry-After straight away
+// instead of queueing, so a burst can't pile up behind the database pool. Requests whose path
+// starts with one of the exempt prefixes (health checks, metrics) bypass the limit, so probes
+// keep working while the API is saturated. A max of zero or less disables the limit.
+func ConcurrencyLimit(max int, exempt ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if max <= 0 {
+			return next
+		}
+		slots := make(chan struct{}, max)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, prefix := range exempt {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			select {
+			case slots <- struct{}{}:
+			default:
+				rejectedRequests.Add(1)
+				w.Header().Set("Retry-After", concurrencyRetryAfter)
+				http.Error(w, "server is busy, retry later", http.StatusServiceUnavailable)
+				return
+			}
+			inFlightRequests.Add(1)
+			defer func() {
+				inFlightRequests.Add(-1)
+				<-slots
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
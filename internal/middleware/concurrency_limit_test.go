@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrencyLimit_RejectsOverflow(t *testing.T) {
+	const limit = 2
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := ConcurrencyLimit(limit, "/health")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/tasks" {
+			started <- struct{}{}
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Saturate the limit with requests that block until released
+	before := inFlightRequests.Value()
+	var wg sync.WaitGroup
+	codes := make([]int, limit)
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest("GET", "/api/tasks", nil))
+			codes[i] = rr.Code
+		}(i)
+		<-started
+	}
+	assert.Equal(t, before+limit, inFlightRequests.Value())
+
+	rejectedBefore := rejectedRequests.Value()
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/api/tasks/1", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+	assert.Equal(t, rejectedBefore+1, rejectedRequests.Value())
+
+	// Exempt paths are still served while saturated
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/health/live", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK}, codes)
+	assert.Equal(t, before, inFlightRequests.Value())
+
+	// Slots are freed once the requests finish
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/api/tasks/1", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestConcurrencyLimit_DisabledWhenZero(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	handler := ConcurrencyLimit(0)(next)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/api/tasks", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
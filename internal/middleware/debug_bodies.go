@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/cliffdoyle/task-api/internal/redact"
+)
+
+// redactedHeaders are never written to the debug log
+var redactedHeaders = []string{"Authorization", "Cookie"}
+
+// withheldBody is logged in place of a body that can't be searched for redacted fields
+const withheldBody = "[WITHHELD: not JSON]"
+
+// DebugBodies returns middleware that logs each request's headers and body and the response
+// status and body, tagged with the request ID. Bodies are truncated to maxBytes, after the value of
+// every redacted field is replaced by redact.Mask. While any field is redacted, a body that isn't
+// complete JSON, including one longer than maxBytes, is withheld rather than logged, since a
+// redacted value in it couldn't be found.
+//
+// It is meant for diagnosing client problems and must stay off in normal operation: bodies can
+// contain anything a client sends.
+func DebugBodies(maxBytes int, logger *log.Logger, redacted redact.Fields) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := RequestIDFromContext(r.Context())
+
+			// Only the logged prefix is buffered; the rest is streamed from the original body,
+			// so the handler's own size limits still apply
+			var reqBody []byte
+			if r.Body != nil {
+				reqBody, _ = io.ReadAll(io.LimitReader(r.Body, int64(maxBytes)+1))
+				r.Body = readCloser{io.MultiReader(bytes.NewReader(reqBody), r.Body), r.Body}
+			}
+			logger.Printf("[%s] request %s %s headers=%s body=%s",
+				id, r.Method, r.URL.RequestURI(), formatHeaders(r.Header), logBody(reqBody, maxBytes, redacted))
+
+			rec := &bodyRecorder{ResponseWriter: w, status: http.StatusOK, max: maxBytes}
+			next.ServeHTTP(rec, r)
+
+			logger.Printf("[%s] response %d body=%s", id, rec.status, logBody(rec.body.Bytes(), maxBytes, redacted))
+		})
+	}
+}
+
+// readCloser pairs a replacement reader with the original body's Close
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyRecorder passes writes through while keeping the status and up to max+1 body bytes
+type bodyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+	max    int
+}
+
+func (b *bodyRecorder) WriteHeader(status int) {
+	b.status = status
+	b.ResponseWriter.WriteHeader(status)
+}
+
+func (b *bodyRecorder) Write(p []byte) (int, error) {
+	if room := b.max + 1 - b.body.Len(); room > 0 {
+		if len(p) < room {
+			room = len(p)
+		}
+		b.body.Write(p[:room])
+	}
+	return b.ResponseWriter.Write(p)
+}
+
+// logBody renders body for the log with redacted fields masked. A body of more than max bytes
+// was only partly read, so with redaction on it is never valid JSON and is withheld.
+func logBody(body []byte, max int, redacted redact.Fields) string {
+	if !redacted.Enabled() {
+		return truncate(body, max)
+	}
+	if len(body) > max {
+		return withheldBody
+	}
+	masked, ok := redacted.JSON(body)
+	if !ok {
+		return withheldBody
+	}
+	return truncate(masked, max)
+}
+
+// truncate renders body for the log, marking it when more than max bytes were seen
+func truncate(body []byte, max int) string {
+	if len(body) > max {
+		return string(body[:max]) + "...(truncated)"
+	}
+	return string(body)
+}
+
+// formatHeaders renders headers on one line with credentials redacted
+func formatHeaders(h http.Header) string {
+	clone := h.Clone()
+	for _, name := range redactedHeaders {
+		if clone.Get(name) != "" {
+			clone.Set(name, "[REDACTED]")
+		}
+	}
+	var b strings.Builder
+	clone.Write(&b)
+	return strings.ReplaceAll(strings.TrimSpace(b.String()), "\r\n", "; ")
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cliffdoyle/task-api/internal/redact"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugBodies_LogsAndRestoresBodies(t *testing.T) {
+	var logs bytes.Buffer
+	var handlerSaw string
+	handler := RequestID(DebugBodies(1024, log.New(&logs, "", 0), nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		handlerSaw = string(b)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":1}`))
+	})))
+
+	req := httptest.NewRequest("POST", "/api/tasks", strings.NewReader(`{"title":"x"}`))
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set(RequestIDHeader, "req-1")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, `{"title":"x"}`, handlerSaw, "the handler must still see the full body")
+	assert.Equal(t, `{"id":1}`, rr.Body.String())
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	out := logs.String()
+	assert.Contains(t, out, `[req-1] request POST /api/tasks`)
+	assert.Contains(t, out, `body={"title":"x"}`)
+	assert.Contains(t, out, `[req-1] response 201 body={"id":1}`)
+	assert.Contains(t, out, "Authorization: [REDACTED]")
+	assert.NotContains(t, out, "secret-token")
+}
+
+func TestDebugBodies_TruncatesLargeBodies(t *testing.T) {
+	var logs bytes.Buffer
+	large := strings.Repeat("a", 100)
+	var handlerSaw int
+	handler := DebugBodies(10, log.New(&logs, "", 0), nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		handlerSaw = len(b)
+		w.Write([]byte(large))
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("POST", "/", strings.NewReader(large)))
+
+	assert.Equal(t, 100, handlerSaw)
+	assert.Equal(t, large, rr.Body.String())
+	assert.Equal(t, 2, strings.Count(logs.String(), "body=aaaaaaaaaa...(truncated)"))
+}
+
+func TestDebugBodies_RedactsFields(t *testing.T) {
+	var logs bytes.Buffer
+	var handlerSaw string
+	redacted := redact.New([]string{"description", "ssn"})
+	handler := DebugBodies(1024, log.New(&logs, "", 0), redacted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		handlerSaw = string(b)
+		w.Write([]byte(`{"id":1,"description":"top-secret","metadata":{"SSN":"123-45-6789"}}`))
+	}))
+
+	body := `{"title":"x","description":"top-secret","metadata":{"ssn":"123-45-6789","team":"a"}}`
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("POST", "/api/tasks", strings.NewReader(body)))
+
+	assert.Equal(t, body, handlerSaw, "redaction must only affect the log")
+	assert.Contains(t, rr.Body.String(), "top-secret", "redaction must only affect the log")
+
+	out := logs.String()
+	assert.NotContains(t, out, "top-secret")
+	assert.NotContains(t, out, "123-45-6789")
+	assert.Contains(t, out, `"description":"***"`)
+	assert.Contains(t, out, `"metadata":{"ssn":"***","team":"a"}`)
+	assert.Contains(t, out, `"SSN":"***"`)
+}
+
+func TestDebugBodies_WithholdsUnsearchableBodies(t *testing.T) {
+	redacted := redact.New([]string{"description"})
+	tests := map[string]struct {
+		body     string
+		maxBytes int
+	}{
+		"not JSON":  {body: `description=top-secret`, maxBytes: 1024},
+		"truncated": {body: `{"description":"top-secret"}`, maxBytes: 10},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var logs bytes.Buffer
+			handler := DebugBodies(tc.maxBytes, log.New(&logs, "", 0), redacted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(tc.body)))
+
+			assert.NotContains(t, logs.String(), "top-secret")
+			assert.Contains(t, logs.String(), "body="+withheldBody)
+		})
+	}
+}
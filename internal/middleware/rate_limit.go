@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"encoding/json"
+	"expvar"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimitedRequests counts requests answered with 429 by RateLimit
+var rateLimitedRequests = expvar.NewInt("http_requests_rate_limited_total")
+
+// rateLimitSweepInterval is how often buckets that have refilled completely are forgotten.
+// A full bucket is the same as no bucket, so dropping it changes nothing for the client.
+const rateLimitSweepInterval = time.Minute
+
+// rateLimitBody is the JSON body of a 429 response
+type rateLimitBody struct {
+	Error             string `json:"error"`
+	RetryAfterSeconds int    `json:"retry_after_seconds"`
+	Limit             int    `json:"limit"`
+}
+
+// bucket is one client's token bucket
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter holds a token bucket per client IP
+type rateLimiter struct {
+	rate  float64 // tokens added per second
+	burst int     // bucket size
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// rateDecision is the outcome of taking a token, with the values reported in the headers
+type rateDecision struct {
+	allowed    bool
+	remaining  int
+	retryAfter int       // whole seconds until a token is available; only set when not allowed
+	reset      time.Time // when the bucket will be full again
+}
+
+// RateLimit returns middleware that limits each client IP (as resolved by ClientIP) to perMinute
+// requests a minute, with bursts of up to burst requests. Every response carries
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset (Unix seconds when the bucket
+// is full again). A request over the limit gets 429 Too Many Requests with Retry-After and a
+// JSON body. Paths starting with an exempt prefix bypass the limit. A perMinute of zero or less
+// disables it.
+func RateLimit(perMinute, burst int, exempt ...string) func(http.Handler) http.Handler {
+	return rateLimit(perMinute, burst, time.Now, exempt...)
+}
+
+// rateLimit is RateLimit with an injectable clock
+func rateLimit(perMinute, burst int, now func() time.Time, exempt ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if perMinute <= 0 {
+			return next
+		}
+		if burst <= 0 {
+			burst = 1
+		}
+		limiter := &rateLimiter{
+			rate:    float64(perMinute) / 60,
+			burst:   burst,
+			now:     now,
+			buckets: make(map[string]*bucket),
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, prefix := range exempt {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			key := ClientIPFromContext(r.Context())
+			if key == "" {
+				key = remoteIP(r.RemoteAddr)
+			}
+			d := limiter.take(key)
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(burst))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(d.remaining))
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(d.reset.Unix(), 10))
+			if !d.allowed {
+				rateLimitedRequests.Add(1)
+				w.Header().Set("Retry-After", strconv.Itoa(d.retryAfter))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusTooManyRequests)
+				json.NewEncoder(w).Encode(rateLimitBody{Error: "rate limited", RetryAfterSeconds: d.retryAfter, Limit: burst})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// take refills key's bucket for the time since it was last used and takes a token if there is one
+func (l *rateLimiter) take(key string) rateDecision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(l.burst), b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	d := rateDecision{allowed: b.tokens >= 1}
+	if d.allowed {
+		b.tokens--
+	} else {
+		d.retryAfter = int(math.Ceil((1 - b.tokens) / l.rate))
+	}
+	d.remaining = int(math.Floor(b.tokens))
+	refill := time.Duration((float64(l.burst) - b.tokens) / l.rate * float64(time.Second))
+	d.reset = now.Add(refill)
+	// Round up so a client waiting until Reset never finds the bucket short
+	if d.reset.Truncate(time.Second) != d.reset {
+		d.reset = d.reset.Truncate(time.Second).Add(time.Second)
+	}
+	return d
+}
+
+// sweep forgets buckets that have refilled completely. The caller holds l.mu.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= float64(l.burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
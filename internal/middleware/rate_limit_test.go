@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit_HeadersAndBody(t *testing.T) {
+	// 30 a minute is one token every 2s, with bursts of 2
+	now := time.Unix(1_700_000_000, 0)
+	handler := rateLimit(30, 2, func() time.Time { return now }, "/health")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	do := func(path, addr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = addr
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do("/api/tasks", "10.0.0.1:1234")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", rr.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, strconv.FormatInt(now.Unix()+2, 10), rr.Header().Get("X-RateLimit-Reset"))
+
+	rr = do("/api/tasks", "10.0.0.1:1234")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "0", rr.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, strconv.FormatInt(now.Unix()+4, 10), rr.Header().Get("X-RateLimit-Reset"))
+
+	// The bucket is empty: half a second later a token is still 1.5s away
+	now = now.Add(500 * time.Millisecond)
+	rejectedBefore := rateLimitedRequests.Value()
+	rr = do("/api/tasks", "10.0.0.1:1234")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get("Retry-After"))
+	assert.Equal(t, "0", rr.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, strconv.FormatInt(now.Unix()+4, 10), rr.Header().Get("X-RateLimit-Reset"), "3.5s away, rounded up")
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, map[string]interface{}{"error": "rate limited", "retry_after_seconds": float64(2), "limit": float64(2)}, body)
+	assert.Equal(t, rejectedBefore+1, rateLimitedRequests.Value())
+
+	// Other clients and exempt paths are unaffected
+	assert.Equal(t, http.StatusOK, do("/api/tasks", "10.0.0.2:1234").Code)
+	assert.Equal(t, http.StatusOK, do("/health", "10.0.0.1:1234").Code)
+
+	// Once a token has been refilled the client can continue
+	now = now.Add(1500 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, do("/api/tasks", "10.0.0.1:1234").Code)
+}
+
+func TestRateLimit_UsesResolvedClientIP(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	limited := rateLimit(60, 1, func() time.Time { return now })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	_, proxy, _ := net.ParseCIDR("10.0.0.0/8")
+	handler := ClientIP([]*net.IPNet{proxy})(limited)
+
+	// Two clients behind the same trusted proxy get a bucket each
+	for _, client := range []string{"203.0.113.1", "203.0.113.2"} {
+		req := httptest.NewRequest("GET", "/api/tasks", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", client)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code, client)
+	}
+}
+
+func TestRateLimit_Disabled(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := RateLimit(0, 10)(next)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/api/tasks", nil))
+	assert.Empty(t, rr.Header().Get("X-RateLimit-Limit"))
+}
+
+func TestRateLimiter_SweepForgetsFullBuckets(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := &rateLimiter{rate: 1, burst: 5, now: func() time.Time { return now }, buckets: map[string]*bucket{}, lastSweep: now}
+	l.take("a")
+	l.take("b")
+	l.take("b")
+	l.take("b")
+	l.take("b")
+	l.take("b")
+
+	// After a minute both buckets have refilled, so the next take sweeps them
+	now = now.Add(rateLimitSweepInterval)
+	l.take("c")
+	assert.Len(t, l.buckets, 1)
+	assert.Contains(t, l.buckets, "c")
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// RequireReady returns middleware that answers requests whose path starts with one of the gated
+// prefixes with 503 Service Unavailable until ready is true, with Retry-After set to retryAfter
+// seconds (left off when retryAfter is zero or less). Startup sets ready
+// once the database is reachable and the schema check has passed, so load balancers see a clean
+// "not yet" instead of database errors. Other paths, such as /health/live, are always served.
+// It wraps the router rather than being added with Router.Use, so unknown gated paths get 503 too.
+func RequireReady(ready *atomic.Bool, retryAfter int, gated ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !ready.Load() {
+				for _, prefix := range gated {
+					if strings.HasPrefix(r.URL.Path, prefix) {
+						if retryAfter > 0 {
+							w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+						}
+						http.Error(w, "server is starting, retry later", http.StatusServiceUnavailable)
+						return
+					}
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireReady(t *testing.T) {
+	// Arrange
+	var ready atomic.Bool
+	handler := RequireReady(&ready, 5, "/api")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		return rr
+	}
+
+	// Act & Assert: before readiness only /api is turned away
+	rr := serve("/api/tasks")
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "5", rr.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, serve("/health/live").Code)
+
+	ready.Store(true)
+
+	rr = serve("/api/tasks")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("Retry-After"))
+}
+
+func TestRequireReady_NoRetryAfter(t *testing.T) {
+	var ready atomic.Bool
+	handler := RequireReady(&ready, 0, "/api")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/api/tasks", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	_, set := rr.Header()["Retry-After"]
+	assert.False(t, set)
+}
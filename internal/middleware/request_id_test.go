@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID_GeneratesAndPropagates(t *testing.T) {
+	var seen string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+
+	assert.Len(t, seen, 32)
+	assert.Equal(t, seen, rr.Header().Get(RequestIDHeader))
+}
+
+func TestRequestID_KeepsValidIncomingID(t *testing.T) {
+	var seen string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}))
+
+	for incoming, keep := range map[string]bool{
+		"abc-123":                true,
+		"has space":              false,
+		"line\nbreak":            false,
+		strings.Repeat("x", 129): false,
+	} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(RequestIDHeader, incoming)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, keep, seen == incoming, incoming)
+	}
+}
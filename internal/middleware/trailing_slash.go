@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TrailingSlashMode selects how TrailingSlash treats a path ending in "/"
+type TrailingSlashMode string
+
+const (
+	// TrailingSlashStrip serves /api/tasks/ exactly as /api/tasks
+	TrailingSlashStrip TrailingSlashMode = "strip"
+	// TrailingSlashRedirect redirects /api/tasks/ to /api/tasks: 301 for GET and HEAD, 308 for
+	// other methods so clients resend the same method and body
+	TrailingSlashRedirect TrailingSlashMode = "redirect"
+	// TrailingSlashStrict leaves paths alone, so /api/tasks/ is 404
+	TrailingSlashStrict TrailingSlashMode = "strict"
+)
+
+// ParseTrailingSlashMode validates a TrailingSlashMode name (case-insensitive)
+func ParseTrailingSlashMode(s string) (TrailingSlashMode, error) {
+	switch m := TrailingSlashMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case TrailingSlashStrip, TrailingSlashRedirect, TrailingSlashStrict:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown trailing slash mode %q (want strip, redirect or strict)", s)
+}
+
+// TrailingSlash returns middleware that makes a path with trailing slashes behave like the same
+// path without them, by rewriting or redirecting according to mode. The root path "/" is left
+// alone. It must wrap the router itself rather than be added with Router.Use, because mux only
+// runs Use middleware once a route has matched.
+func TrailingSlash(mode TrailingSlashMode) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if mode == TrailingSlashStrict {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := r.URL.Path
+			if len(path) <= 1 || !strings.HasSuffix(path, "/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			canonical := strings.TrimRight(path, "/")
+			if canonical == "" {
+				canonical = "/"
+			}
+
+			if mode == TrailingSlashRedirect {
+				target := *r.URL
+				target.Path = canonical
+				target.RawPath = ""
+				status := http.StatusPermanentRedirect
+				if r.Method == http.MethodGet || r.Method == http.MethodHead {
+					status = http.StatusMovedPermanently
+				}
+				http.Redirect(w, r, target.RequestURI(), status)
+				return
+			}
+
+			r2 := r.Clone(r.Context())
+			r2.URL.Path = canonical
+			r2.URL.RawPath = strings.TrimRight(r.URL.RawPath, "/")
+			next.ServeHTTP(w, r2)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// trailingSlashRouter has the same shape of routes as the API: a collection and an item
+func trailingSlashRouter() *mux.Router {
+	r := mux.NewRouter()
+	r.HandleFunc("/api/tasks", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("list"))
+	}).Methods("GET", "POST")
+	r.HandleFunc("/api/tasks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("task " + mux.Vars(r)["id"]))
+	}).Methods("GET")
+	return r
+}
+
+func TestTrailingSlash_Strip(t *testing.T) {
+	handler := TrailingSlash(TrailingSlashStrip)(trailingSlashRouter())
+
+	cases := map[string]string{
+		"/api/tasks":         "list",
+		"/api/tasks/":        "list",
+		"/api/tasks/5/":      "task 5",
+		"/api/tasks//":       "list",
+		"/api/tasks/?full=1": "list",
+	}
+	for path, want := range cases {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, http.StatusOK, rr.Code, path)
+		assert.Equal(t, want, rr.Body.String(), path)
+	}
+}
+
+func TestTrailingSlash_Redirect(t *testing.T) {
+	handler := TrailingSlash(TrailingSlashRedirect)(trailingSlashRouter())
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/api/tasks/5/?tz=UTC", nil))
+	assert.Equal(t, http.StatusMovedPermanently, rr.Code)
+	assert.Equal(t, "/api/tasks/5?tz=UTC", rr.Header().Get("Location"))
+
+	// Other methods get 308 so the client repeats the method and body
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("POST", "/api/tasks/", nil))
+	assert.Equal(t, http.StatusPermanentRedirect, rr.Code)
+	assert.Equal(t, "/api/tasks", rr.Header().Get("Location"))
+
+	// Canonical paths are served directly
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/api/tasks/5", nil))
+	assert.Equal(t, "task 5", rr.Body.String())
+}
+
+func TestTrailingSlash_Strict(t *testing.T) {
+	handler := TrailingSlash(TrailingSlashStrict)(trailingSlashRouter())
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/api/tasks/", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestTrailingSlash_RootUntouched(t *testing.T) {
+	var got string
+	handler := TrailingSlash(TrailingSlashRedirect)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Path
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "/", got)
+}
+
+func TestParseTrailingSlashMode(t *testing.T) {
+	m, err := ParseTrailingSlashMode(" Redirect ")
+	assert.NoError(t, err)
+	assert.Equal(t, TrailingSlashRedirect, m)
+
+	_, err = ParseTrailingSlashMode("ignore")
+	assert.Error(t, err)
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+    "encoding/json"
+    "fmt"
+    "time"
+)
+
+// DateLayout is how a Date is written in JSON
+const DateLayout = "2006-01-02"
+
+// Date is a calendar day with no time of day or zone, encoded in JSON as "YYYY-MM-DD".
+// The embedded time is always midnight UTC.
+type Date struct {
+    time.Time
+}
+
+// NewDate returns the calendar day t falls on in t's own location
+func NewDate(t time.Time) Date {
+    return Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+// ParseDate parses a "YYYY-MM-DD" string
+func ParseDate(s string) (Date, error) {
+    t, err := time.Parse(DateLayout, s)
+    if err != nil {
+        return Date{}, fmt.Errorf("invalid date %q, want YYYY-MM-DD", s)
+    }
+    return Date{t}, nil
+}
+
+func (d Date) String() string {
+    return d.Format(DateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+    return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+    var s string
+    if err := json.Unmarshal(data, &s); err != nil {
+        return fmt.Errorf("date must be a string in YYYY-MM-DD form")
+    }
+    parsed, err := ParseDate(s)
+    if err != nil {
+        return err
+    }
+    *d = parsed
+    return nil
+}
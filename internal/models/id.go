@@ -0,0 +1,50 @@
+package models
+
+import (
+    "encoding/json"
+    "fmt"
+    "strconv"
+)
+
+// IDList is a list of task IDs. It decodes from JSON numbers or strings of digits, so clients
+// that receive IDs as strings (Accept-ID-Format: string) can send them back unchanged. It always
+// encodes as numbers.
+type IDList []int
+
+func (l *IDList) UnmarshalJSON(data []byte) error {
+    var raw []json.RawMessage
+    if err := json.Unmarshal(data, &raw); err != nil {
+        return fmt.Errorf("ids must be an array")
+    }
+    if raw == nil {
+        *l = nil
+        return nil
+    }
+    ids := make(IDList, len(raw))
+    for i, r := range raw {
+        id, err := parseID(r)
+        if err != nil {
+            return err
+        }
+        ids[i] = id
+    }
+    *l = ids
+    return nil
+}
+
+// parseID decodes one ID given as a JSON integer or a string holding one
+func parseID(data json.RawMessage) (int, error) {
+    var s string
+    if err := json.Unmarshal(data, &s); err == nil {
+        id, err := strconv.Atoi(s)
+        if err != nil {
+            return 0, fmt.Errorf("invalid ID %q", s)
+        }
+        return id, nil
+    }
+    var id int
+    if err := json.Unmarshal(data, &id); err != nil {
+        return 0, fmt.Errorf("invalid ID %s", data)
+    }
+    return id, nil
+}
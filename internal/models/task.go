@@ -1,23 +1,437 @@
 package models
 
-import "time"
+import (
+    "fmt"
+    "strings"
+    "time"
+)
 
 type Task struct {
-    ID          int       `json:"id"`
-    Title       string    `json:"title"`
-    Description string    `json:"description"`
-    Status      string    `json:"status"` // "pending", "in_progress", "completed"
-    CreatedAt   time.Time `json:"created_at"`
-    UpdatedAt   time.Time `json:"updated_at"`
+    ID          int                    `json:"id"`
+    UUID        string                 `json:"uuid,omitempty"` // random public ID; replaces id in responses when ID_MODE=uuid
+    Title       string                 `json:"title"`
+    Description string                 `json:"description"`
+    Status      string                 `json:"status"` // "pending", "in_progress", "completed"
+    Priority    string                 `json:"priority"` // "low", "medium" or "high"
+    Metadata    map[string]interface{} `json:"metadata"` // flat custom attributes, stored as jsonb
+    CreatedAt   time.Time              `json:"created_at"`
+    UpdatedAt   time.Time              `json:"updated_at"`
+
+    // Set while a worker holds the task via POST /api/tasks/claim
+    ClaimedBy      *string    `json:"claimed_by,omitempty"`
+    LeaseExpiresAt *time.Time `json:"lease_expires_at,omitempty"`
+    DeletedAt      *time.Time `json:"deleted_at,omitempty"` // only set in the changes feed, DELETE ?return=representation and the admin list
+    Deleted        bool       `json:"deleted,omitempty"`    // true when DeletedAt is set
+    StartedAt      *time.Time `json:"started_at,omitempty"` // when the task first moved to in_progress
+    CompletedAt    *time.Time `json:"completed_at,omitempty"`
+    ReopenReason   string     `json:"reopen_reason,omitempty"` // why the task was last reopened
+    Assignee       string     `json:"assignee,omitempty"`      // who the task is assigned to; empty when unassigned
+    DueDate        *Date      `json:"due_date,omitempty"`
+}
+
+// TaskSummary is the trimmed task returned by the list endpoint unless ?full=true is given
+type TaskSummary struct {
+    ID      int    `json:"id"`
+    UUID    string `json:"uuid,omitempty"`
+    Title    string `json:"title"`
+    Status   string `json:"status"`
+    Priority string `json:"priority"`
+    DueDate  *Date  `json:"due_date,omitempty"`
+}
+
+// DailyMetrics is one day of GET /api/tasks/metrics/daily: how many tasks were created and how
+// many completed on that (UTC) day
+type DailyMetrics struct {
+    Date      Date `json:"date"`
+    Created   int  `json:"created"`
+    Completed int  `json:"completed"`
+}
+
+// AssigneeWorkload is one row of GET /api/tasks/workload: how many live tasks an assignee has
+// in each status. Assignee is nil for unassigned tasks.
+type AssigneeWorkload struct {
+    Assignee   *string `json:"assignee"`
+    Pending    int     `json:"pending"`
+    InProgress int     `json:"in_progress"`
+    Completed  int     `json:"completed"`
+}
+
+// OldestPendingResponse is the body of GET /api/tasks/oldest-pending
+type OldestPendingResponse struct {
+    Task       *Task `json:"task"`
+    AgeSeconds int64 `json:"age_seconds"` // whole seconds since the task was created
 }
 
 type CreateTaskRequest struct {
-    Title       string `json:"title"`
-    Description string `json:"description"`
+    Title       string                 `json:"title"`
+    Description string                 `json:"description"`
+    Metadata    map[string]interface{} `json:"metadata,omitempty"`
+    Assignee    string                 `json:"assignee,omitempty"`
+    DueDate     *Date                  `json:"due_date,omitempty"`
+    DueInDays   *int                   `json:"due_in_days,omitempty"` // overrides the configured default offset; 0 means no due date
+    Priority    string                 `json:"priority,omitempty"`    // defaults to DefaultPriority
+    Actor       string                 `json:"-"`                     // taken from the X-Actor header, not the body
 }
 
 type UpdateTaskRequest struct {
-    Title       string `json:"title,omitempty"`
-    Description string `json:"description,omitempty"`
-    Status      string `json:"status,omitempty"`
-}
\ No newline at end of file
+    Title       string                 `json:"title,omitempty"`
+    Description string                 `json:"description,omitempty"`
+    Status      string                 `json:"status,omitempty"`
+    Metadata    map[string]interface{} `json:"metadata,omitempty"` // replaces the existing metadata when present
+    Assignee    string                 `json:"assignee,omitempty"`
+    DueDate     *Date                  `json:"due_date,omitempty"`
+    Priority    string                 `json:"priority,omitempty"`
+    Actor       string                 `json:"-"` // taken from the X-Actor header, not the body
+}
+
+// PatchTaskRequest is a decoded JSON Merge Patch (RFC 7386). A nil field was absent from the
+// patch and is left untouched.
+type PatchTaskRequest struct {
+    Title         *string
+    Description   *string                // "description": null clears it to ""
+    Status        *string
+    Metadata      map[string]interface{} // merged into the existing metadata; nil values delete keys
+    ClearMetadata bool                   // "metadata": null removes all metadata
+    Assignee      *string                // "assignee": null unassigns the task
+    DueDate       *Date
+    ClearDueDate  bool                   // "due_date": null removes the due date
+    Priority      *string
+    Actor         string                 // taken from the X-Actor header
+}
+
+type ClaimTaskRequest struct {
+    WorkerID string `json:"worker_id"`
+}
+
+type ReleaseTaskRequest struct {
+    WorkerID string `json:"worker_id,omitempty"` // when set, only the claiming worker may release
+}
+
+type ReopenTaskRequest struct {
+    Reason string `json:"reason"`
+    Actor  string `json:"-"` // taken from the X-Actor header, not the body
+}
+
+// TransitionTaskRequest is the body of POST /api/tasks/{id}/transition
+type TransitionTaskRequest struct {
+    To    string `json:"to"`
+    Note  string `json:"note,omitempty"`
+    Actor string `json:"-"` // taken from the X-Actor header, not the body
+}
+
+// AuditActionTransition is the AuditEntry action for a status change made through the
+// transition endpoint
+const AuditActionTransition = "transition"
+
+// AuditActionSnooze is the AuditEntry action for a due date pushed back through the snooze
+// endpoint; the note records the old and new dates
+const AuditActionSnooze = "snooze"
+
+// AuditActionCreate is the AuditEntry action for a newly created task
+const AuditActionCreate = "create"
+
+// AuditActionUpdate is the AuditEntry action for a PUT; the note lists the fields it changed
+const AuditActionUpdate = "update"
+
+// AuditActionPatch is the AuditEntry action for a PATCH; the note lists the fields it changed
+const AuditActionPatch = "patch"
+
+// AuditActionReassign is the AuditEntry action for a task moved by the bulk reassign
+// endpoint; the note records the old and new assignees
+const AuditActionReassign = "reassign"
+
+// AuditActionAssign is the AuditEntry action for an assignment made through the assign
+// endpoint; the note records the old and new assignees
+const AuditActionAssign = "assign"
+
+// AuditActionEscalate is the AuditEntry action for a priority raised by the escalation job
+// because the task is overdue; the note records the old and new priorities
+const AuditActionEscalate = "escalate"
+
+// AuditActionReopen is the AuditEntry action for a completed task reopened; the note is the reason
+const AuditActionReopen = "reopen"
+
+// AuditActionDelete is the AuditEntry action for a task soft-deleted through DELETE
+const AuditActionDelete = "delete"
+
+// DeleteTaskRequest describes a DELETE /api/tasks/{id}. The request has no body, so it only
+// carries who made it.
+type DeleteTaskRequest struct {
+    Actor string `json:"-"` // taken from the X-Actor header
+}
+
+// SnoozeTaskRequest is the body of POST /api/tasks/{id}/snooze. Exactly one of Duration and
+// Until is given.
+type SnoozeTaskRequest struct {
+    Duration string `json:"duration,omitempty"` // e.g. "48h" or "3d", added to the current due date
+    Until    string `json:"until,omitempty"`    // the new due date, as RFC 3339 or YYYY-MM-DD
+    Actor    string `json:"-"`                  // taken from the X-Actor header, not the body
+}
+
+// AuditEntry is one row of the task_audit table
+type AuditEntry struct {
+    ID         int       `json:"id"`
+    TaskID     int       `json:"task_id"`
+    Actor      string    `json:"actor,omitempty"`
+    Action     string    `json:"action"`
+    FromStatus string    `json:"from_status,omitempty"`
+    ToStatus   string    `json:"to_status,omitempty"`
+    Note       string    `json:"note,omitempty"`
+    CreatedAt  time.Time `json:"created_at"`
+}
+
+// AssignTaskRequest is the body of POST /api/tasks/{id}/assign
+type AssignTaskRequest struct {
+    Assignee *string `json:"assignee"` // nil (JSON null) unassigns the task
+    Actor    string  `json:"-"`        // taken from the X-Actor header, not the body
+}
+
+// ReassignTasksRequest is the body of POST /api/tasks/reassign
+type ReassignTasksRequest struct {
+    From  string `json:"from"`
+    To    string `json:"to"`
+    Actor string `json:"-"` // taken from the X-Actor header, not the body
+}
+
+// ReassignTasksResponse reports how many tasks POST /api/tasks/reassign moved
+type ReassignTasksResponse struct {
+    From  string `json:"from"`
+    To    string `json:"to"`
+    Moved int    `json:"moved"`
+}
+
+type BatchGetRequest struct {
+    IDs IDList `json:"ids"`
+}
+
+// BatchGetResponse reports which of the requested tasks exist
+type BatchGetResponse struct {
+    Found   []*Task `json:"found"`
+    Missing []int   `json:"missing"`
+}
+
+// TaskChange is one entry in the changes feed; deleted tasks are included so clients can drop them
+type TaskChange struct {
+    *Task
+    Deleted bool `json:"deleted"`
+}
+
+// ChangesResponse is a page of the changes feed
+type ChangesResponse struct {
+    Changes    []TaskChange `json:"changes"`
+    NextCursor string       `json:"next_cursor,omitempty"` // set when more changes are waiting
+    ServerTime time.Time    `json:"server_time"`           // pass as ?since on the next sync
+}
+
+// ChangesCursor is the keyset position in the changes feed, ordered by (updated_at, id)
+type ChangesCursor struct {
+    UpdatedAt time.Time
+    ID        int
+}
+
+// NextTransitionsResponse lists the statuses a task can move to from its current one
+type NextTransitionsResponse struct {
+    TaskID   int      `json:"task_id"`
+    TaskUUID string   `json:"task_uuid,omitempty"`
+    Status   string   `json:"status"`
+    Allowed []string `json:"allowed"`
+    // Blocked lists allowed statuses that are currently blocked by dependencies. Tasks have
+    // no dependencies yet, so it is always empty; it is part of the response so clients can
+    // rely on the field.
+    Blocked []string `json:"blocked"`
+}
+
+// ListFilter narrows the tasks returned when listing
+type ListFilter struct {
+    Statuses       []string          // status must be one of these
+    Metadata       map[string]string // metadata key -> value it must equal (compared as text)
+    MetadataKeys   []string          // metadata keys that must be present
+    Assignee       string            // assignee must equal this
+    Unassigned     bool              // only tasks with no assignee; takes precedence over Assignee
+    HasDueDate     bool              // only tasks with a due date
+    Open           bool              // leave out completed tasks, whatever Statuses says
+    CreatedAfter   *time.Time        // only tasks created after this
+    CreatedBefore  *time.Time        // only tasks created before this
+    UpdatedBy      string            // only tasks with an audited change by this actor
+    UpdatedAfter   *time.Time        // only tasks changed after this (by UpdatedBy, when set)
+    UpdatedBefore  *time.Time        // only tasks changed before this (by UpdatedBy, when set)
+    All            bool              // skip the default max-age window (?all=true)
+    Limit          int               // return at most this many tasks; 0 returns them all
+    IncludeDeleted bool              // also return soft-deleted tasks (admin only)
+    SortBy         SortField         // field to order by; empty is SortByCreatedAt
+    Order          SortOrder         // direction to order SortBy in; empty uses the configured default
+    NullsFirst     bool              // list tasks without a SortBy value first instead of last
+}
+
+// SortField is a field tasks can be listed in order of
+type SortField string
+
+const (
+    SortByCreatedAt   SortField = "created_at"
+    SortByDueDate     SortField = "due_date"
+    SortByCompletedAt SortField = "completed_at"
+)
+
+// Nullable reports whether a task can lack a value for the field, so ordering by it has to say
+// where those tasks go
+func (f SortField) Nullable() bool {
+    return f == SortByDueDate || f == SortByCompletedAt
+}
+
+// ParseSortField validates a SortField name
+func ParseSortField(s string) (SortField, error) {
+    switch f := SortField(s); f {
+    case SortByCreatedAt, SortByDueDate, SortByCompletedAt:
+        return f, nil
+    }
+    return "", fmt.Errorf("unknown sort field %q (want created_at, due_date or completed_at)", s)
+}
+
+// ParseSortDirections parses "field=direction" pairs such as "due_date=asc" into the default
+// direction of each field named
+func ParseSortDirections(pairs []string) (map[SortField]SortOrder, error) {
+    dirs := make(map[SortField]SortOrder, len(pairs))
+    for _, pair := range pairs {
+        name, dir, ok := strings.Cut(pair, "=")
+        if !ok {
+            return nil, fmt.Errorf("invalid sort direction %q (want field=asc or field=desc)", pair)
+        }
+        field, err := ParseSortField(strings.TrimSpace(name))
+        if err != nil {
+            return nil, err
+        }
+        order, err := ParseSortOrder(dir)
+        if err != nil {
+            return nil, err
+        }
+        dirs[field] = order
+    }
+    return dirs, nil
+}
+
+// SortOrder is the direction tasks are listed in; for creation time, desc is newest first
+type SortOrder string
+
+const (
+    SortNewestFirst SortOrder = "desc"
+    SortOldestFirst SortOrder = "asc"
+)
+
+// ParseSortOrder validates a SortOrder name (case-insensitive)
+func ParseSortOrder(s string) (SortOrder, error) {
+    switch o := SortOrder(strings.ToLower(strings.TrimSpace(s))); o {
+    case SortNewestFirst, SortOldestFirst:
+        return o, nil
+    }
+    return "", fmt.Errorf("unknown sort order %q (want desc or asc)", s)
+}
+
+// UnassignedFilterValue is the ?assignee= value that lists unassigned tasks. It is reserved and
+// can't be used as an actual assignee.
+const UnassignedFilterValue = "none"
+
+// Statuses lists every status a task can have
+var Statuses = []string{"pending", "in_progress", "completed"}
+
+// IsValidStatus reports whether status is one a task can have
+func IsValidStatus(status string) bool {
+    for _, s := range Statuses {
+        if s == status {
+            return true
+        }
+    }
+    return false
+}
+
+// Priorities lists every priority a task can have, lowest first
+var Priorities = []string{"low", "medium", "high"}
+
+// DefaultPriority is the priority of a task created without one
+const DefaultPriority = "medium"
+
+// IsValidPriority reports whether priority is one a task can have
+func IsValidPriority(priority string) bool {
+    return priorityRank(priority) >= 0
+}
+
+// NextPriority returns the priority one above priority, or "" when it is already the highest
+func NextPriority(priority string) string {
+    if i := priorityRank(priority); i >= 0 && i < len(Priorities)-1 {
+        return Priorities[i+1]
+    }
+    return ""
+}
+
+// PreviousPriority returns the priority one below priority, or "" when it is already the lowest
+func PreviousPriority(priority string) string {
+    if i := priorityRank(priority); i > 0 {
+        return Priorities[i-1]
+    }
+    return ""
+}
+
+// priorityRank is priority's index in Priorities, or -1 when it isn't one
+func priorityRank(priority string) int {
+    for i, p := range Priorities {
+        if p == priority {
+            return i
+        }
+    }
+    return -1
+}
+
+// CanonicalStatus matches status against Statuses ignoring case, returning the canonical
+// lowercase form and whether there was a match
+func CanonicalStatus(status string) (string, bool) {
+    for _, s := range Statuses {
+        if strings.EqualFold(s, status) {
+            return s, true
+        }
+    }
+    return status, false
+}
+
+// Task event types
+const (
+    EventTaskCreated = "task.created"
+    EventTaskUpdated = "task.updated"
+    EventTaskDeleted = "task.deleted"
+    // EventTasksBatch carries every event from one bulk operation in Events
+    EventTasksBatch = "tasks.batch"
+)
+
+// TaskEvent is published after a task is created, updated or deleted. Created events carry the
+// full task, updated events the task and the fields that changed, and deleted events just the ID.
+// A batch event has no task of its own, only the per-task events it groups.
+type TaskEvent struct {
+    Type       string                 `json:"type"`
+    TaskID     int                    `json:"task_id,omitempty"`
+    Task       *Task                  `json:"task,omitempty"`
+    Changes    map[string]FieldChange `json:"changes,omitempty"` // keyed by JSON field name
+    Events     []TaskEvent            `json:"events,omitempty"`  // only in a batch event
+    OccurredAt time.Time              `json:"occurred_at"`
+}
+
+// FieldChange is one field's value before and after an update; a cleared field's new value is null
+type FieldChange struct {
+    Old interface{} `json:"old"`
+    New interface{} `json:"new"`
+}
+
+// MaintenanceRequest is the optional body of POST /admin/maintenance. Operations are run in the
+// order given; empty runs every enabled operation.
+type MaintenanceRequest struct {
+    Operations []string `json:"operations"`
+}
+
+// MaintenanceReport is the response to POST /admin/maintenance
+type MaintenanceReport struct {
+    Results    []MaintenanceResult `json:"results"`
+    DurationMS int64               `json:"duration_ms"`
+}
+
+// MaintenanceResult is how long one maintenance operation took
+type MaintenanceResult struct {
+    Operation  string `json:"operation"`
+    DurationMS int64  `json:"duration_ms"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// TaskTemplate is a saved set of defaults for creating one kind of task. Title may contain
+// {date}, which is replaced with the UTC date the task is created on.
+type TaskTemplate struct {
+    ID          int                    `json:"id"`
+    Name        string                 `json:"name"`
+    Title       string                 `json:"title"`
+    Description string                 `json:"description"`
+    Metadata    map[string]interface{} `json:"metadata"`
+    Assignee    string                 `json:"assignee,omitempty"`
+    DueInDays   *int                   `json:"due_in_days,omitempty"` // due date offset from the day a task is created
+    Priority    string                 `json:"priority,omitempty"`    // empty leaves it to DefaultPriority
+    CreatedAt   time.Time              `json:"created_at"`
+}
+
+// CreateTemplateRequest is the body of POST /api/templates
+type CreateTemplateRequest struct {
+    Name        string                 `json:"name"`
+    Title       string                 `json:"title"`
+    Description string                 `json:"description"`
+    Metadata    map[string]interface{} `json:"metadata,omitempty"`
+    Assignee    string                 `json:"assignee,omitempty"`
+    DueInDays   *int                   `json:"due_in_days,omitempty"`
+    Priority    string                 `json:"priority,omitempty"`
+}
@@ -0,0 +1,76 @@
+// Package redact hides the values of sensitive task fields before they reach a log
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Mask replaces every redacted value
+const Mask = "***"
+
+// Fields is a set of field names whose values are masked wherever they appear in logged data:
+// as a JSON key at any depth (so task metadata keys count too) or as a database column. Names
+// are matched ignoring case. The zero value redacts nothing.
+type Fields map[string]bool
+
+// New builds a Fields from names; blank names are ignored
+func New(names []string) Fields {
+	f := Fields{}
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			f[strings.ToLower(name)] = true
+		}
+	}
+	return f
+}
+
+// Enabled reports whether anything is redacted
+func (f Fields) Enabled() bool {
+	return len(f) > 0
+}
+
+// Has reports whether name is redacted
+func (f Fields) Has(name string) bool {
+	return f[strings.ToLower(name)]
+}
+
+// JSON returns data with the value of every redacted key replaced by Mask. ok is false when
+// data isn't a complete JSON document, such as a truncated body; the caller must then not log
+// it, since redacted values can't be found in it.
+func (f Fields) JSON(data []byte) (redacted []byte, ok bool) {
+	if !f.Enabled() || len(bytes.TrimSpace(data)) == 0 {
+		return data, true
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // keep numbers exactly as sent
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil || dec.More() {
+		return nil, false
+	}
+	out, err := json.Marshal(f.Value(doc))
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+// Value masks redacted keys in a decoded JSON value, in place
+func (f Fields) Value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if f.Has(key) {
+				v[key] = Mask
+				continue
+			}
+			v[key] = f.Value(value)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = f.Value(v[i])
+		}
+	}
+	return v
+}
@@ -0,0 +1,48 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFields_JSON(t *testing.T) {
+	f := New([]string{" Description ", "", "token"})
+	tests := map[string]struct {
+		in     string
+		want   string
+		wantOK bool
+	}{
+		"top level":        {in: `{"title":"x","description":"secret"}`, want: `{"description":"***","title":"x"}`, wantOK: true},
+		"nested and cased": {in: `{"metadata":{"Token":{"v":1}},"n":12345678901234567890}`, want: `{"metadata":{"Token":"***"},"n":12345678901234567890}`, wantOK: true},
+		"array":            {in: `[{"description":"a"},{"title":"b"}]`, want: `[{"description":"***"},{"title":"b"}]`, wantOK: true},
+		"empty body":       {in: ``, want: ``, wantOK: true},
+		"invalid":          {in: `{"description":"sec`, wantOK: false},
+		"trailing data":    {in: `{} {"description":"secret"}`, wantOK: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			got, ok := f.JSON([]byte(tc.in))
+
+			// Assert
+			assert.Equal(t, tc.wantOK, ok)
+			if tc.wantOK {
+				assert.Equal(t, tc.want, string(got))
+			}
+		})
+	}
+}
+
+func TestFields_Disabled(t *testing.T) {
+	var f Fields
+	in := []byte(`not json {"description":"secret"}`)
+
+	got, ok := f.JSON(in)
+
+	assert.True(t, ok)
+	assert.Equal(t, in, got)
+	assert.False(t, New(nil).Enabled())
+	assert.False(t, f.Has("description"))
+}
@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+)
+
+// cachedTaskRepository decorates a TaskRepository with an in-process LRU cache for GetByID.
+// Every method is implemented explicitly (rather than embedding the interface) so that any
+// new write method has to decide how it invalidates the cache.
+type cachedTaskRepository struct {
+	inner TaskRepository
+	size  int
+	ttl   time.Duration
+	now   func() time.Time
+
+	mu    sync.Mutex
+	order *list.List // front = most recently used
+	items map[int]*list.Element
+
+	// txWrites is only set on the view handed to a WithTransaction callback. That view bypasses
+	// the cache, so the transaction reads its own writes, and records the IDs it writes so the
+	// parent can evict them once the transaction is over.
+	txWrites *[]int
+}
+
+// cacheEntry is the value stored in each element of the LRU list
+type cacheEntry struct {
+	task      models.Task
+	expiresAt time.Time
+}
+
+// NewCachedTaskRepository wraps inner with an LRU cache holding up to size tasks for ttl each.
+// A size of zero or less returns inner unchanged; a ttl of zero or less never expires entries.
+func NewCachedTaskRepository(inner TaskRepository, size int, ttl time.Duration) TaskRepository {
+	if size <= 0 {
+		return inner
+	}
+	return &cachedTaskRepository{
+		inner: inner,
+		size:  size,
+		ttl:   ttl,
+		now:   time.Now,
+		order: list.New(),
+		items: make(map[int]*list.Element),
+	}
+}
+
+// Create passes through; a new task is only cached once it is read
+func (c *cachedTaskRepository) Create(task *models.Task) error {
+	return c.inner.Create(task)
+}
+
+// CreateWithID passes through and evicts the ID, in case a read of the deleted task it
+// collided with is cached
+func (c *cachedTaskRepository) CreateWithID(task *models.Task) error {
+	defer c.evict(task.ID)
+	return c.inner.CreateWithID(task)
+}
+
+// GetByID serves the task from the cache when present and unexpired, otherwise loads and caches it
+func (c *cachedTaskRepository) GetByID(id int) (*models.Task, error) {
+	if task, ok := c.get(id); ok {
+		return task, nil
+	}
+
+	task, err := c.inner.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	c.put(task)
+	return task, nil
+}
+
+// GetIDByUUID passes through; it is only used to resolve IDs in uuid mode
+func (c *cachedTaskRepository) GetIDByUUID(uuid string) (int, error) {
+	return c.inner.GetIDByUUID(uuid)
+}
+
+// GetByIDs passes through; batch reads are rare enough not to be worth merging with the cache
+func (c *cachedTaskRepository) GetByIDs(ids []int) ([]*models.Task, error) {
+	return c.inner.GetByIDs(ids)
+}
+
+// GetAll is not cached: list results would go stale on any write
+func (c *cachedTaskRepository) GetAll(filter models.ListFilter) ([]*models.Task, error) {
+	return c.inner.GetAll(filter)
+}
+
+// GetSummaries passes through; like GetAll, lists aren't cached
+func (c *cachedTaskRepository) GetSummaries(filter models.ListFilter) ([]*models.TaskSummary, error) {
+	return c.inner.GetSummaries(filter)
+}
+
+// GetChanges is not cached, for the same reason as GetAll
+func (c *cachedTaskRepository) GetChanges(after models.ChangesCursor, limit int) ([]*models.Task, error) {
+	return c.inner.GetChanges(after, limit)
+}
+
+// ChangesWatermark is not cached; it must be read fresh before every GetChanges
+func (c *cachedTaskRepository) ChangesWatermark() (time.Time, error) {
+	return c.inner.ChangesWatermark()
+}
+
+// GetRecent is not cached, for the same reason as GetAll
+func (c *cachedTaskRepository) GetRecent(limit int) ([]*models.Task, error) {
+	return c.inner.GetRecent(limit)
+}
+
+// CountByAssignee passes through; it reads no single task
+func (c *cachedTaskRepository) CountByAssignee(statuses []string) ([]*models.AssigneeWorkload, error) {
+	return c.inner.CountByAssignee(statuses)
+}
+
+// GetOldestPending is not cached: the answer changes whenever any task is created or claimed
+func (c *cachedTaskRepository) GetOldestPending() (*models.Task, error) {
+	return c.inner.GetOldestPending()
+}
+
+// GetRandom is not cached: each call should pick again
+func (c *cachedTaskRepository) GetRandom(filter models.ListFilter, sample bool) (*models.Task, error) {
+	return c.inner.GetRandom(filter, sample)
+}
+
+// Update writes through and evicts the task so the next read sees the persisted state
+func (c *cachedTaskRepository) Update(task *models.Task) error {
+	defer c.evict(task.ID)
+	return c.inner.Update(task)
+}
+
+// Delete removes the task and evicts it from the cache
+func (c *cachedTaskRepository) Delete(id int) error {
+	defer c.evict(id)
+	return c.inner.Delete(id)
+}
+
+// Revive passes through and evicts the task; deleted tasks are never cached, so this only
+// guards against a read racing the revive
+func (c *cachedTaskRepository) Revive(id int) error {
+	defer c.evict(id)
+	return c.inner.Revive(id)
+}
+
+// CountByDay passes through; it reads no single task
+func (c *cachedTaskRepository) CountByDay(from, to time.Time) ([]*models.DailyMetrics, error) {
+	return c.inner.CountByDay(from, to)
+}
+
+// GetByTitle passes through; only lookups by ID are cached
+func (c *cachedTaskRepository) GetByTitle(title string) (*models.Task, error) {
+	return c.inner.GetByTitle(title)
+}
+
+// LockTitle passes through
+func (c *cachedTaskRepository) LockTitle(title string) error {
+	return c.inner.LockTitle(title)
+}
+
+// AddAuditEntry passes through; audit rows aren't part of a cached task
+func (c *cachedTaskRepository) AddAuditEntry(entry *models.AuditEntry) error {
+	return c.inner.AddAuditEntry(entry)
+}
+
+// CreateTemplate passes through; templates aren't cached
+func (c *cachedTaskRepository) CreateTemplate(tmpl *models.TaskTemplate) error {
+	return c.inner.CreateTemplate(tmpl)
+}
+
+// GetTemplate passes through; templates aren't cached
+func (c *cachedTaskRepository) GetTemplate(id int) (*models.TaskTemplate, error) {
+	return c.inner.GetTemplate(id)
+}
+
+// ListTemplates passes through; templates aren't cached
+func (c *cachedTaskRepository) ListTemplates() ([]*models.TaskTemplate, error) {
+	return c.inner.ListTemplates()
+}
+
+// DeleteTemplate passes through; templates aren't cached
+func (c *cachedTaskRepository) DeleteTemplate(id int) error {
+	return c.inner.DeleteTemplate(id)
+}
+
+// Claim passes through and evicts the claimed task, whose status and lease just changed
+func (c *cachedTaskRepository) Claim(workerID string, lease time.Duration) (*models.Task, error) {
+	task, err := c.inner.Claim(workerID, lease)
+	if task != nil {
+		c.evict(task.ID)
+	}
+	return task, err
+}
+
+// Release passes through and evicts the released task
+func (c *cachedTaskRepository) Release(id int, workerID string) (*models.Task, error) {
+	defer c.evict(id)
+	return c.inner.Release(id, workerID)
+}
+
+// Reopen passes through and evicts the reopened task
+func (c *cachedTaskRepository) Reopen(id int, status, reason string) (*models.Task, error) {
+	defer c.evict(id)
+	return c.inner.Reopen(id, status, reason)
+}
+
+// ReclaimExpiredLeases passes through and evicts every reclaimed task
+func (c *cachedTaskRepository) ReclaimExpiredLeases() ([]int, error) {
+	ids, err := c.inner.ReclaimExpiredLeases()
+	for _, id := range ids {
+		c.evict(id)
+	}
+	return ids, err
+}
+
+// Reassign passes through and evicts every reassigned task
+func (c *cachedTaskRepository) Reassign(from, to string) ([]*models.Task, error) {
+	tasks, err := c.inner.Reassign(from, to)
+	for _, task := range tasks {
+		c.evict(task.ID)
+	}
+	return tasks, err
+}
+
+// EscalatePriorities passes through and evicts every escalated task
+func (c *cachedTaskRepository) EscalatePriorities(statuses []string, dueBefore models.Date) ([]*models.Task, error) {
+	tasks, err := c.inner.EscalatePriorities(statuses, dueBefore)
+	for _, task := range tasks {
+		c.evict(task.ID)
+	}
+	return tasks, err
+}
+
+// RunMaintenance passes through; maintenance doesn't change task contents
+func (c *cachedTaskRepository) RunMaintenance(ctx context.Context, op MaintenanceOp) error {
+	return c.inner.RunMaintenance(ctx, op)
+}
+
+// WithTransaction runs fn in a transaction on the wrapped repository. Tasks written inside it
+// are evicted afterwards, whether it committed or rolled back, so a reader racing the commit
+// can't leave a stale entry behind.
+func (c *cachedTaskRepository) WithTransaction(ctx context.Context, fn func(repo TaskRepository) error) error {
+	var written []int
+	defer func() {
+		for _, id := range written {
+			c.evict(id)
+		}
+	}()
+	return c.inner.WithTransaction(ctx, func(tx TaskRepository) error {
+		return fn(&cachedTaskRepository{inner: tx, txWrites: &written})
+	})
+}
+
+// Close releases the wrapped repository's resources
+func (c *cachedTaskRepository) Close() error {
+	return c.inner.Close()
+}
+
+// get returns a copy of the cached task, dropping it if it has expired.
+// Copies are handed out so callers (e.g. the service mutating a task before Update)
+// can never modify the cached value.
+func (c *cachedTaskRepository) get(id int) (*models.Task, bool) {
+	if c.txWrites != nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[id]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if c.ttl > 0 && !c.now().Before(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.items, id)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	task := cloneTask(entry.task)
+	return &task, true
+}
+
+// put stores a copy of task, evicting the least recently used entry when full
+func (c *cachedTaskRepository) put(task *models.Task) {
+	if c.txWrites != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &cacheEntry{task: cloneTask(*task), expiresAt: c.now().Add(c.ttl)}
+	if elem, ok := c.items[task.ID]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[task.ID] = c.order.PushFront(entry)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).task.ID)
+	}
+}
+
+// evict drops the cached entry for id, if any
+func (c *cachedTaskRepository) evict(id int) {
+	if c.txWrites != nil {
+		*c.txWrites = append(*c.txWrites, id)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[id]; ok {
+		c.order.Remove(elem)
+		delete(c.items, id)
+	}
+}
+
+// cloneTask copies a task including its metadata map, which would otherwise be shared.
+// Metadata is flat, so copying the top-level map is a deep copy.
+func cloneTask(task models.Task) models.Task {
+	if task.Metadata != nil {
+		metadata := make(map[string]interface{}, len(task.Metadata))
+		for k, v := range task.Metadata {
+			metadata[k] = v
+		}
+		task.Metadata = metadata
+	}
+	return task
+}
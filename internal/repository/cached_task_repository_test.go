@@ -0,0 +1,447 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubTaskRepository is a minimal in-memory TaskRepository that counts GetByID calls
+type stubTaskRepository struct {
+	tasks    map[int]*models.Task
+	getCalls int
+}
+
+func newStubTaskRepository(tasks ...*models.Task) *stubTaskRepository {
+	s := &stubTaskRepository{tasks: make(map[int]*models.Task)}
+	for _, t := range tasks {
+		s.tasks[t.ID] = t
+	}
+	return s
+}
+
+func (s *stubTaskRepository) Create(task *models.Task) error {
+	task.ID = len(s.tasks) + 1
+	s.tasks[task.ID] = task
+	return nil
+}
+
+func (s *stubTaskRepository) CreateWithID(task *models.Task) error {
+	if _, ok := s.tasks[task.ID]; ok {
+		return ErrTaskIDTaken
+	}
+	s.tasks[task.ID] = task
+	return nil
+}
+
+func (s *stubTaskRepository) GetByID(id int) (*models.Task, error) {
+	s.getCalls++
+	task, ok := s.tasks[id]
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+	copied := *task
+	return &copied, nil
+}
+
+func (s *stubTaskRepository) GetIDByUUID(uuid string) (int, error) {
+	for id, task := range s.tasks {
+		if task.UUID == uuid {
+			return id, nil
+		}
+	}
+	return 0, ErrTaskNotFound
+}
+
+func (s *stubTaskRepository) GetByIDs(ids []int) ([]*models.Task, error) {
+	tasks := []*models.Task{}
+	for _, id := range ids {
+		if task, ok := s.tasks[id]; ok {
+			copied := *task
+			tasks = append(tasks, &copied)
+		}
+	}
+	return tasks, nil
+}
+
+func (s *stubTaskRepository) GetAll(filter models.ListFilter) ([]*models.Task, error) {
+	tasks := []*models.Task{}
+	for _, t := range s.tasks {
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+func (s *stubTaskRepository) GetChanges(after models.ChangesCursor, limit int) ([]*models.Task, error) {
+	return []*models.Task{}, nil
+}
+
+func (s *stubTaskRepository) ChangesWatermark() (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (s *stubTaskRepository) GetRecent(limit int) ([]*models.Task, error) {
+	return []*models.Task{}, nil
+}
+
+func (s *stubTaskRepository) CountByAssignee(statuses []string) ([]*models.AssigneeWorkload, error) {
+	return []*models.AssigneeWorkload{}, nil
+}
+
+func (s *stubTaskRepository) GetOldestPending() (*models.Task, error) {
+	return nil, ErrNoTaskAvailable
+}
+
+func (s *stubTaskRepository) GetRandom(filter models.ListFilter, sample bool) (*models.Task, error) {
+	return nil, ErrNoTaskAvailable
+}
+
+func (s *stubTaskRepository) Update(task *models.Task) error {
+	copied := *task
+	s.tasks[task.ID] = &copied
+	return nil
+}
+
+func (s *stubTaskRepository) Delete(id int) error {
+	delete(s.tasks, id)
+	return nil
+}
+
+func (s *stubTaskRepository) Revive(id int) error {
+	return ErrTaskNotFound
+}
+
+func (s *stubTaskRepository) GetSummaries(filter models.ListFilter) ([]*models.TaskSummary, error) {
+	return nil, nil
+}
+
+func (s *stubTaskRepository) CountByDay(from, to time.Time) ([]*models.DailyMetrics, error) {
+	return nil, nil
+}
+
+func (s *stubTaskRepository) GetByTitle(title string) (*models.Task, error) {
+	return nil, ErrTaskNotFound
+}
+
+func (s *stubTaskRepository) LockTitle(title string) error {
+	return nil
+}
+
+func (s *stubTaskRepository) AddAuditEntry(entry *models.AuditEntry) error {
+	return nil
+}
+
+func (s *stubTaskRepository) RunMaintenance(ctx context.Context, op MaintenanceOp) error {
+	return nil
+}
+
+func (s *stubTaskRepository) CreateTemplate(tmpl *models.TaskTemplate) error {
+	return nil
+}
+
+func (s *stubTaskRepository) GetTemplate(id int) (*models.TaskTemplate, error) {
+	return nil, ErrTemplateNotFound
+}
+
+func (s *stubTaskRepository) ListTemplates() ([]*models.TaskTemplate, error) {
+	return []*models.TaskTemplate{}, nil
+}
+
+func (s *stubTaskRepository) DeleteTemplate(id int) error {
+	return ErrTemplateNotFound
+}
+
+func (s *stubTaskRepository) Claim(workerID string, lease time.Duration) (*models.Task, error) {
+	for _, task := range s.tasks {
+		if task.Status == "pending" {
+			task.Status = "in_progress"
+			task.ClaimedBy = &workerID
+			copied := *task
+			return &copied, nil
+		}
+	}
+	return nil, ErrNoTaskAvailable
+}
+
+func (s *stubTaskRepository) Release(id int, workerID string) (*models.Task, error) {
+	task, ok := s.tasks[id]
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+	task.Status = "pending"
+	task.ClaimedBy = nil
+	copied := *task
+	return &copied, nil
+}
+
+func (s *stubTaskRepository) Reopen(id int, status, reason string) (*models.Task, error) {
+	task, ok := s.tasks[id]
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+	if task.Status != "completed" {
+		return nil, ErrTaskNotCompleted
+	}
+	task.Status = status
+	task.CompletedAt = nil
+	task.ReopenReason = reason
+	copied := *task
+	return &copied, nil
+}
+
+func (s *stubTaskRepository) Reassign(from, to string) ([]*models.Task, error) {
+	var moved []*models.Task
+	for _, task := range s.tasks {
+		if task.Assignee == from {
+			task.Assignee = to
+			copied := *task
+			moved = append(moved, &copied)
+		}
+	}
+	return moved, nil
+}
+
+func (s *stubTaskRepository) EscalatePriorities(statuses []string, dueBefore models.Date) ([]*models.Task, error) {
+	var escalated []*models.Task
+	for _, task := range s.tasks {
+		next := models.NextPriority(task.Priority)
+		if next != "" && slices.Contains(statuses, task.Status) && task.DueDate != nil && task.DueDate.Before(dueBefore.Time) {
+			task.Priority = next
+			copied := *task
+			escalated = append(escalated, &copied)
+		}
+	}
+	return escalated, nil
+}
+
+func (s *stubTaskRepository) ReclaimExpiredLeases() ([]int, error) {
+	ids := []int{}
+	for id, task := range s.tasks {
+		if task.Status == "in_progress" && task.LeaseExpiresAt != nil && !task.LeaseExpiresAt.After(time.Now()) {
+			task.Status = "pending"
+			task.ClaimedBy = nil
+			task.LeaseExpiresAt = nil
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// WithTransaction has no real transaction to manage; it runs fn against the stub itself
+func (s *stubTaskRepository) WithTransaction(ctx context.Context, fn func(repo TaskRepository) error) error {
+	return fn(s)
+}
+
+func (s *stubTaskRepository) Close() error { return nil }
+
+func TestCachedRepository_Hit(t *testing.T) {
+	inner := newStubTaskRepository(&models.Task{ID: 1, Title: "Cached"})
+	repo := NewCachedTaskRepository(inner, 10, time.Minute)
+
+	first, err := repo.GetByID(1)
+	assert.NoError(t, err)
+	second, err := repo.GetByID(1)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Cached", second.Title)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, inner.getCalls, "second read should be served from the cache")
+}
+
+func TestCachedRepository_ReturnsCopies(t *testing.T) {
+	inner := newStubTaskRepository(&models.Task{ID: 1, Title: "Original"})
+	repo := NewCachedTaskRepository(inner, 10, time.Minute)
+
+	task, _ := repo.GetByID(1)
+	task.Title = "Mutated by caller"
+
+	again, _ := repo.GetByID(1)
+	assert.Equal(t, "Original", again.Title)
+}
+
+func TestCachedRepository_ReturnsMetadataCopies(t *testing.T) {
+	inner := newStubTaskRepository(&models.Task{ID: 1, Metadata: map[string]interface{}{"team": "backend"}})
+	repo := NewCachedTaskRepository(inner, 10, time.Minute)
+
+	task, _ := repo.GetByID(1)
+	task.Metadata["team"] = "frontend"
+
+	again, _ := repo.GetByID(1)
+	assert.Equal(t, "backend", again.Metadata["team"])
+}
+
+func TestCachedRepository_TTLExpiry(t *testing.T) {
+	inner := newStubTaskRepository(&models.Task{ID: 1, Title: "Task"})
+	repo := NewCachedTaskRepository(inner, 10, time.Minute).(*cachedTaskRepository)
+	now := time.Now()
+	repo.now = func() time.Time { return now }
+
+	repo.GetByID(1)
+	now = now.Add(59 * time.Second)
+	repo.GetByID(1)
+	assert.Equal(t, 1, inner.getCalls, "entry should still be fresh")
+
+	now = now.Add(2 * time.Second)
+	repo.GetByID(1)
+	assert.Equal(t, 2, inner.getCalls, "expired entry should be reloaded")
+}
+
+func TestCachedRepository_LRUEviction(t *testing.T) {
+	inner := newStubTaskRepository(&models.Task{ID: 1}, &models.Task{ID: 2}, &models.Task{ID: 3})
+	repo := NewCachedTaskRepository(inner, 2, time.Minute)
+
+	repo.GetByID(1)
+	repo.GetByID(2)
+	repo.GetByID(1) // 1 is now most recently used
+	repo.GetByID(3) // evicts 2
+	assert.Equal(t, 3, inner.getCalls)
+
+	repo.GetByID(1)
+	assert.Equal(t, 3, inner.getCalls, "1 should still be cached")
+	repo.GetByID(2)
+	assert.Equal(t, 4, inner.getCalls, "2 should have been evicted")
+}
+
+func TestCachedRepository_InvalidatedOnUpdate(t *testing.T) {
+	inner := newStubTaskRepository(&models.Task{ID: 1, Title: "Before"})
+	repo := NewCachedTaskRepository(inner, 10, time.Minute)
+
+	task, _ := repo.GetByID(1)
+	task.Title = "After"
+	assert.NoError(t, repo.Update(task))
+
+	updated, err := repo.GetByID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "After", updated.Title)
+	assert.Equal(t, 2, inner.getCalls)
+}
+
+func TestCachedRepository_InvalidatedOnDelete(t *testing.T) {
+	inner := newStubTaskRepository(&models.Task{ID: 1, Title: "Doomed"})
+	repo := NewCachedTaskRepository(inner, 10, time.Minute)
+
+	repo.GetByID(1)
+	assert.NoError(t, repo.Delete(1))
+
+	task, err := repo.GetByID(1)
+	assert.Nil(t, task)
+	assert.True(t, errors.Is(err, ErrTaskNotFound))
+}
+
+func TestCachedRepository_InvalidatedOnClaimAndRelease(t *testing.T) {
+	inner := newStubTaskRepository(&models.Task{ID: 1, Status: "pending"})
+	repo := NewCachedTaskRepository(inner, 10, time.Minute)
+
+	repo.GetByID(1)
+	_, err := repo.Claim("worker-1", time.Minute)
+	assert.NoError(t, err)
+
+	claimed, _ := repo.GetByID(1)
+	assert.Equal(t, "in_progress", claimed.Status)
+
+	_, err = repo.Release(1, "")
+	assert.NoError(t, err)
+
+	released, _ := repo.GetByID(1)
+	assert.Equal(t, "pending", released.Status)
+}
+
+func TestCachedRepository_InvalidatedOnReclaim(t *testing.T) {
+	expired := time.Now().Add(-time.Minute)
+	inner := newStubTaskRepository(&models.Task{ID: 1, Status: "in_progress", LeaseExpiresAt: &expired})
+	repo := NewCachedTaskRepository(inner, 10, time.Minute)
+
+	repo.GetByID(1)
+	ids, err := repo.ReclaimExpiredLeases()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, ids)
+
+	task, _ := repo.GetByID(1)
+	assert.Equal(t, "pending", task.Status)
+}
+
+func TestCachedRepository_InvalidatedOnEscalate(t *testing.T) {
+	due := models.NewDate(time.Now().AddDate(0, 0, -3))
+	inner := newStubTaskRepository(&models.Task{ID: 1, Status: "pending", Priority: "low", DueDate: &due})
+	repo := NewCachedTaskRepository(inner, 10, time.Minute)
+
+	repo.GetByID(1)
+	tasks, err := repo.EscalatePriorities([]string{"pending"}, models.NewDate(time.Now()))
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	task, _ := repo.GetByID(1)
+	assert.Equal(t, "medium", task.Priority)
+}
+
+func TestNewCachedTaskRepository_DisabledReturnsInner(t *testing.T) {
+	inner := newStubTaskRepository()
+
+	assert.Same(t, inner, NewCachedTaskRepository(inner, 0, time.Minute))
+}
+
+func TestCachedRepository_GetAllNotCached(t *testing.T) {
+	inner := newStubTaskRepository(&models.Task{ID: 1, Status: "pending"})
+	repo := NewCachedTaskRepository(inner, 10, time.Minute)
+
+	repo.GetByID(1)
+	// Change the task behind the cache's back, as a write from another path would
+	inner.tasks[1] = &models.Task{ID: 1, Status: "completed"}
+
+	tasks, err := repo.GetAll(models.ListFilter{})
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, "completed", tasks[0].Status, "list results must always come from the repository")
+}
+
+func TestCachedRepository_TransactionBypassesCacheAndEvictsAfterwards(t *testing.T) {
+	inner := newStubTaskRepository(&models.Task{ID: 1, Title: "Before"})
+	repo := NewCachedTaskRepository(inner, 10, 0)
+	_, _ = repo.GetByID(1) // warm the cache
+
+	err := repo.WithTransaction(context.Background(), func(tx TaskRepository) error {
+		// Reads inside the transaction go to the database so they see its own writes
+		task, err := tx.GetByID(1)
+		assert.NoError(t, err)
+		task.Title = "After"
+		assert.NoError(t, tx.Update(task))
+
+		reread, err := tx.GetByID(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "After", reread.Title)
+
+		// Until the transaction ends, other readers keep the committed (cached) value
+		cached, err := repo.GetByID(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "Before", cached.Title)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, inner.getCalls)
+
+	task, err := repo.GetByID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "After", task.Title)
+	assert.Equal(t, 4, inner.getCalls, "written task is evicted once the transaction ends")
+}
+
+func TestCachedRepository_FailedTransactionStillEvicts(t *testing.T) {
+	inner := newStubTaskRepository(&models.Task{ID: 1, Title: "Cached"})
+	repo := NewCachedTaskRepository(inner, 10, 0)
+	_, _ = repo.GetByID(1)
+
+	boom := errors.New("boom")
+	err := repo.WithTransaction(context.Background(), func(tx TaskRepository) error {
+		assert.NoError(t, tx.Delete(1))
+		return boom
+	})
+
+	assert.ErrorIs(t, err, boom)
+	_, err = repo.GetByID(1)
+	assert.ErrorIs(t, err, ErrTaskNotFound, "the stub can't roll back, but the cache must not serve the old entry")
+}
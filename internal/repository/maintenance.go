@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MaintenanceOp is a database maintenance operation on the tasks table
+type MaintenanceOp string
+
+const (
+	// MaintenanceAnalyze refreshes the planner statistics, which go stale after bulk loads
+	MaintenanceAnalyze MaintenanceOp = "analyze"
+	// MaintenanceReindex rebuilds the table's indexes. It blocks writes to the table while it runs.
+	MaintenanceReindex MaintenanceOp = "reindex"
+)
+
+// maintenanceQueries holds the statement for each MaintenanceOp
+var maintenanceQueries = map[MaintenanceOp]string{
+	MaintenanceAnalyze: `ANALYZE {tasks}`,
+	MaintenanceReindex: `REINDEX TABLE {tasks}`,
+}
+
+// RunMaintenance runs op against the tasks table, giving up when ctx ends. The statement isn't
+// prepared or cached since it runs rarely, and it is never part of a transaction.
+func (r *taskRepository) RunMaintenance(ctx context.Context, op MaintenanceOp) error {
+	query, ok := maintenanceQueries[op]
+	if !ok {
+		return fmt.Errorf("unknown maintenance operation %q", op)
+	}
+	query = expandTable(query, r.table)
+
+	start := time.Now()
+	_, err := r.db.ExecContext(ctx, query)
+	if r.queryHook != nil {
+		r.queryHook(QueryEvent{Query: query, Duration: time.Since(start), Err: err})
+	}
+	// The driver reports a cancelled statement in its own terms; the context says why
+	if err != nil && ctx.Err() != nil {
+		return fmt.Errorf("%s: %w", op, ctx.Err())
+	}
+	return err
+}
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/redact"
+)
+
+// QueryEvent describes one statement run by the repository
+type QueryEvent struct {
+	Query    string
+	Args     []interface{}
+	Duration time.Duration // until the database answered; for Query, not including reading the rows
+	Err      error
+}
+
+// QueryHook is called after every statement the repository runs
+type QueryHook func(QueryEvent)
+
+// Option configures optional taskRepository behaviour
+type Option func(*taskRepository)
+
+// WithQueryHook calls hook after every statement the repository runs, including those inside
+// transactions. It is meant for debugging and tests; see LogQueries and QueryRecorder.
+func WithQueryHook(hook QueryHook) Option {
+	return func(r *taskRepository) {
+		r.queryHook = hook
+	}
+}
+
+// LogQueries returns a QueryHook that logs each statement and its duration. Arguments hold task
+// content, so their values are only logged when showArgs is true, and even then an argument
+// bound to one of the redacted columns is logged as redact.Mask, as is any redacted key inside
+// a JSON argument such as metadata.
+func LogQueries(logger *log.Logger, showArgs bool, redacted redact.Fields) QueryHook {
+	return func(e QueryEvent) {
+		args := redactArgs(e.Args)
+		if showArgs {
+			args = maskArgs(e.Query, e.Args, redacted)
+		}
+		if e.Err != nil {
+			logger.Printf("query %q args=%v took %s: %v", compactQuery(e.Query), args, e.Duration, e.Err)
+			return
+		}
+		logger.Printf("query %q args=%v took %s", compactQuery(e.Query), args, e.Duration)
+	}
+}
+
+// QueryRecorder collects QueryEvents so tests can assert which statements ran, and how often.
+// Its zero value is ready to use; pass its Hook method to WithQueryHook.
+type QueryRecorder struct {
+	RedactArgs bool // record "?" in place of each argument
+
+	mu     sync.Mutex
+	events []QueryEvent
+}
+
+// Hook records e; it is a QueryHook
+func (q *QueryRecorder) Hook(e QueryEvent) {
+	if q.RedactArgs {
+		e.Args = redactArgs(e.Args)
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.events = append(q.events, e)
+}
+
+// Events returns a copy of everything recorded so far, oldest first
+func (q *QueryRecorder) Events() []QueryEvent {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]QueryEvent(nil), q.events...)
+}
+
+// Count returns how many recorded statements contain fragment. Whitespace in both is collapsed
+// first, so fragments needn't match the layout of the query constants.
+func (q *QueryRecorder) Count(fragment string) int {
+	fragment = compactQuery(fragment)
+	n := 0
+	for _, e := range q.Events() {
+		if strings.Contains(compactQuery(e.Query), fragment) {
+			n++
+		}
+	}
+	return n
+}
+
+// Reset forgets everything recorded so far
+func (q *QueryRecorder) Reset() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.events = nil
+}
+
+// compactQuery collapses runs of whitespace so multi-line queries read as one line
+func compactQuery(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// redactArgs replaces every argument with "?"
+func redactArgs(args []interface{}) []interface{} {
+	redacted := make([]interface{}, len(args))
+	for i := range args {
+		redacted[i] = "?"
+	}
+	return redacted
+}
+
+var (
+	// insertColumnsPattern captures the column list of an INSERT, up to the opening parenthesis
+	// of its VALUES list
+	insertColumnsPattern = regexp.MustCompile(`(?is)INSERT\s+INTO\s+\S+\s*\(([^)]*)\)\s*VALUES\s*\(`)
+	// comparedColumnPattern captures "column = $n", allowing function calls around either side
+	// (lower(btrim(title)) = lower(btrim($1)), assignee = NULLIF($6, ''))
+	comparedColumnPattern = regexp.MustCompile(`(?i)(\w+)\)*\s*(?:=|<>|!=|<=|>=|<|>)\s*(?:\w+\()*\$(\d+)`)
+	placeholderPattern    = regexp.MustCompile(`\$(\d+)`)
+)
+
+// boundColumns names the column of placeholders that neither pattern can see
+var boundColumns = map[string]map[int]string{
+	lockTitleQuery: {1: "title"},
+}
+
+// maskArgs returns a copy of args with every argument bound to a redacted column replaced by
+// redact.Mask, and redacted keys masked inside arguments holding a JSON object. Arguments are
+// matched to columns through an INSERT's column list, or a comparison or assignment like
+// "title = $1".
+func maskArgs(query string, args []interface{}, redacted redact.Fields) []interface{} {
+	if !redacted.Enabled() {
+		return args
+	}
+	masked := append([]interface{}(nil), args...)
+	for n, columns := range placeholderColumns(query) {
+		if n < 1 || n > len(masked) {
+			continue
+		}
+		for _, column := range columns {
+			if redacted.Has(column) {
+				masked[n-1] = redact.Mask
+			}
+		}
+	}
+	for i, arg := range masked {
+		if s, ok := arg.(string); ok && strings.HasPrefix(strings.TrimSpace(s), "{") {
+			if out, ok := redacted.JSON([]byte(s)); ok {
+				masked[i] = string(out)
+			} else {
+				masked[i] = redact.Mask
+			}
+		}
+	}
+	return masked
+}
+
+// placeholderColumns maps each placeholder number to the columns it is bound to
+func placeholderColumns(query string) map[int][]string {
+	bound := map[int][]string{}
+	for n, column := range boundColumns[query] {
+		bound[n] = append(bound[n], column)
+	}
+	if m := insertColumnsPattern.FindStringSubmatchIndex(query); m != nil {
+		names := strings.Split(query[m[2]:m[3]], ",")
+		for i, value := range splitValues(query[m[1]:]) {
+			if i >= len(names) {
+				break
+			}
+			for _, p := range placeholderPattern.FindAllStringSubmatch(value, -1) {
+				n, _ := strconv.Atoi(p[1])
+				bound[n] = append(bound[n], strings.TrimSpace(names[i]))
+			}
+		}
+	}
+	for _, m := range comparedColumnPattern.FindAllStringSubmatch(query, -1) {
+		n, _ := strconv.Atoi(m[2])
+		bound[n] = append(bound[n], m[1])
+	}
+	return bound
+}
+
+// splitValues splits the VALUES list that list starts with (just past its opening parenthesis)
+// into its items, stopping at the closing parenthesis
+func splitValues(list string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range list {
+		switch {
+		case c == '(':
+			depth++
+		case c == ')' && depth == 0:
+			return append(parts, list[start:i])
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, list[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, list[start:])
+}
+
+// hookedStmt is a prepared statement that reports each use to a QueryHook. It has the methods
+// of *sql.Stmt the repository uses, so call sites don't change when a hook is set.
+type hookedStmt struct {
+	*sql.Stmt
+	query string
+	hook  QueryHook
+}
+
+// report calls the hook, if any, for a statement that started at start
+func (s *hookedStmt) report(start time.Time, args []interface{}, err error) {
+	reportQuery(s.hook, s.query, start, args, err)
+}
+
+// reportQuery calls hook, if set, for query run with args from start
+func reportQuery(hook QueryHook, query string, start time.Time, args []interface{}, err error) {
+	if hook != nil {
+		hook(QueryEvent{Query: query, Args: args, Duration: time.Since(start), Err: err})
+	}
+}
+
+func (s *hookedStmt) Exec(args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := s.Stmt.Exec(args...)
+	s.report(start, args, err)
+	return result, err
+}
+
+func (s *hookedStmt) Query(args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := s.Stmt.Query(args...)
+	s.report(start, args, err)
+	return rows, err
+}
+
+func (s *hookedStmt) QueryRow(args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := s.Stmt.QueryRow(args...)
+	// sql.ErrNoRows only surfaces on Scan, so it isn't reported as a failure
+	s.report(start, args, row.Err())
+	return row
+}
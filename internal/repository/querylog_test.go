@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/redact"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryRecorder_Count(t *testing.T) {
+	rec := &QueryRecorder{}
+	getByID := expandTable(getTaskByIDQuery, DefaultTableName)
+	rec.Hook(QueryEvent{Query: getByID, Args: []interface{}{1}})
+	rec.Hook(QueryEvent{Query: getByID, Args: []interface{}{2}})
+	rec.Hook(QueryEvent{Query: expandTable(updateTaskQuery, DefaultTableName)})
+
+	// Whitespace is collapsed on both sides, so multi-line queries match a one-line fragment
+	assert.Equal(t, 2, rec.Count("FROM tasks WHERE id = $1"))
+	assert.Equal(t, 1, rec.Count("UPDATE tasks\n   SET title = $1"))
+	assert.Equal(t, 0, rec.Count("DELETE"))
+	assert.Equal(t, []interface{}{2}, rec.Events()[1].Args)
+
+	rec.Reset()
+	assert.Empty(t, rec.Events())
+}
+
+func TestQueryRecorder_RedactArgs(t *testing.T) {
+	rec := &QueryRecorder{RedactArgs: true}
+	rec.Hook(QueryEvent{Query: getTaskByIDQuery, Args: []interface{}{"secret", 7}})
+
+	assert.Equal(t, []interface{}{"?", "?"}, rec.Events()[0].Args)
+}
+
+func TestLogQueries(t *testing.T) {
+	var buf bytes.Buffer
+	event := QueryEvent{Query: "SELECT id\n    FROM tasks WHERE title = $1", Args: []interface{}{"secret"}, Duration: 3 * time.Millisecond}
+
+	LogQueries(log.New(&buf, "", 0), false, nil)(event)
+	assert.Equal(t, "query \"SELECT id FROM tasks WHERE title = $1\" args=[?] took 3ms\n", buf.String())
+
+	buf.Reset()
+	event.Err = errors.New("boom")
+	LogQueries(log.New(&buf, "", 0), true, nil)(event)
+	assert.Equal(t, "query \"SELECT id FROM tasks WHERE title = $1\" args=[secret] took 3ms: boom\n", buf.String())
+}
+
+func TestLogQueries_RedactsFields(t *testing.T) {
+	redacted := redact.New([]string{"Description", "title", "ssn"})
+	tests := map[string]struct {
+		query string
+		args  []interface{}
+		want  string
+	}{
+		"insert": {
+			query: expandTable(createTaskQuery, DefaultTableName),
+			args:  []interface{}{"top-secret", "top-secret", "pending", `{"ssn":"top-secret","team":"a"}`, "", nil, "u-1"},
+			want:  `args=[*** *** pending {"ssn":"***","team":"a"}  <nil> u-1]`,
+		},
+		"insert with id": {
+			query: expandTable(createTaskWithIDQuery, DefaultTableName),
+			args:  []interface{}{"top-secret", "top-secret", "pending", "{}", "", nil, "u-1", 9},
+			want:  `args=[*** *** pending {}  <nil> u-1 9]`,
+		},
+		"update": {
+			query: expandTable(updateTaskQuery, DefaultTableName),
+			args:  []interface{}{"top-secret", "top-secret", "pending", "{}", 9, "ann", nil},
+			want:  `args=[*** *** pending {} 9 ann <nil>]`,
+		},
+		"lookup wrapped in functions": {
+			query: expandTable(getTaskByTitleQuery, DefaultTableName),
+			args:  []interface{}{"top-secret"},
+			want:  `args=[***]`,
+		},
+		"title lock": {
+			query: lockTitleQuery,
+			args:  []interface{}{"top-secret"},
+			want:  `args=[***]`,
+		},
+		"unreadable JSON argument": {
+			query: "SELECT 1 WHERE $1::jsonb IS NOT NULL",
+			args:  []interface{}{`{"ssn":"top-secret"`},
+			want:  `args=[***]`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			LogQueries(log.New(&buf, "", 0), true, redacted)(QueryEvent{Query: tc.query, Args: tc.args})
+
+			assert.NotContains(t, buf.String(), "top-secret")
+			assert.Contains(t, buf.String(), tc.want)
+		})
+	}
+}
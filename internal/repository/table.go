@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultTableName is the table tasks are stored in unless WithTableName says otherwise
+const DefaultTableName = "tasks"
+
+// tableToken stands for the tasks table in the query constants; stmt replaces it with the
+// configured name before preparing. auditToken and templatesToken stand for the audit log and
+// templates tables that belong with it (see AuditTableName and TemplatesTableName).
+const (
+	tableToken     = "{tasks}"
+	auditToken     = "{audit}"
+	templatesToken = "{templates}"
+)
+
+// maxTableNameLength leaves room under Postgres' 63-byte identifier limit for the index and
+// constraint names scripts/setup-db.sh derives from the table name
+const maxTableNameLength = 40
+
+// tableNamePattern only admits plain lowercase identifiers, which need no quoting and can't
+// carry SQL into the queries they are pasted into
+var tableNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// ValidateTableName checks that name can be used as the tasks table: lowercase letters, digits
+// and underscores, not starting with a digit, and at most 40 bytes. Names that could be another
+// deployment's audit or templates table are refused too: "task", or any name ending in _audit
+// or _templates.
+func ValidateTableName(name string) error {
+	if len(name) > maxTableNameLength {
+		return fmt.Errorf("table name %q is longer than %d bytes", name, maxTableNameLength)
+	}
+	if !tableNamePattern.MatchString(name) {
+		return fmt.Errorf("table name %q must be lowercase letters, digits and underscores, not starting with a digit", name)
+	}
+	if name == "task" || strings.HasSuffix(name, "_audit") || strings.HasSuffix(name, "_templates") {
+		return fmt.Errorf("table name %q clashes with the audit and templates table names", name)
+	}
+	return nil
+}
+
+// AuditTableName is the audit log table that goes with the tasks table: task_audit for the
+// default table, kept for existing databases, and <table>_audit for any other. Deployments
+// sharing a schema each get their own, so audit rows reference only their own tasks.
+func AuditTableName(table string) string {
+	if table == DefaultTableName {
+		return "task_audit"
+	}
+	return table + "_audit"
+}
+
+// TemplatesTableName is the templates table that goes with the tasks table: task_templates for
+// the default table and <table>_templates for any other
+func TemplatesTableName(table string) string {
+	if table == DefaultTableName {
+		return "task_templates"
+	}
+	return table + "_templates"
+}
+
+// WithTableName stores tasks in table instead of DefaultTableName, with the audit log and
+// templates in the tables named after it. It panics if table fails ValidateTableName, so check
+// configuration with ValidateTableName first.
+func WithTableName(table string) Option {
+	if err := ValidateTableName(table); err != nil {
+		panic(err)
+	}
+	return func(r *taskRepository) {
+		r.table = table
+	}
+}
+
+// expandTable substitutes the table name, and the audit and templates tables named after it,
+// into a query constant
+func expandTable(query, table string) string {
+	return strings.NewReplacer(
+		tableToken, table,
+		auditToken, AuditTableName(table),
+		templatesToken, TemplatesTableName(table),
+	).Replace(query)
+}
+
+// Names of the constraints and indexes scripts/setup-db.sh creates on the tasks table, which
+// write errors are matched against
+func primaryKeyConstraint(table string) string        { return table + "_pkey" }
+func uniqueTitleIndex(table string) string            { return "idx_" + table + "_title_unique" }
+func descriptionLengthConstraint(table string) string { return table + "_description_length" }
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTableName(t *testing.T) {
+	for _, name := range []string{"tasks", "todo_tasks", "_tasks", "tasks2"} {
+		assert.NoError(t, ValidateTableName(name), name)
+	}
+	invalid := []string{
+		"",
+		"Tasks",
+		"2tasks",
+		"my-tasks",
+		"public.tasks",
+		`tasks"; DROP TABLE tasks; --`,
+		"tasks WHERE 1=1",
+		"a_table_name_that_is_far_too_long_for_us_",
+		"task",
+		"task_audit",
+		"todo_tasks_audit",
+		"todo_templates",
+	}
+	for _, name := range invalid {
+		assert.Error(t, ValidateTableName(name), name)
+	}
+}
+
+func TestWithTableName(t *testing.T) {
+	r := NewTaskRepository(nil, WithTableName("todo_tasks")).(*taskRepository)
+	assert.Equal(t, "todo_tasks", r.table)
+	assert.Equal(t, "SELECT id FROM todo_tasks WHERE uuid = $1 AND deleted_at IS NULL", expandTable(getIDByUUIDQuery, r.table))
+	assert.Equal(t, DefaultTableName, NewTaskRepository(nil).(*taskRepository).table)
+
+	assert.Panics(t, func() { WithTableName("tasks; DROP TABLE tasks") })
+}
+
+func TestSideBySideTableNames(t *testing.T) {
+	tables := map[string]struct{ audit, templates string }{
+		DefaultTableName: {"task_audit", "task_templates"},
+		"todo_tasks":     {"todo_tasks_audit", "todo_tasks_templates"},
+	}
+	for table, want := range tables {
+		assert.Equal(t, want.audit, AuditTableName(table), table)
+		assert.Equal(t, want.templates, TemplatesTableName(table), table)
+	}
+
+	// Two deployments in one schema write to disjoint tables
+	assert.Equal(t, "INSERT INTO task_audit (", expandTable("INSERT INTO {audit} (", DefaultTableName))
+	assert.Equal(t, "INSERT INTO todo_tasks_audit (", expandTable("INSERT INTO {audit} (", "todo_tasks"))
+	assert.Contains(t, expandTable(listTemplatesQuery, DefaultTableName), "FROM task_templates ORDER BY")
+	assert.Contains(t, expandTable(listTemplatesQuery, "todo_tasks"), "FROM todo_tasks_templates ORDER BY")
+}
+
+func TestTranslateWriteError_CustomTable(t *testing.T) {
+	dup := &pq.Error{Code: "23505", Constraint: "idx_todo_tasks_title_unique"}
+	assert.Equal(t, ErrDuplicateTask, translateWriteError(dup, "todo_tasks"))
+	assert.Equal(t, error(dup), translateWriteError(dup, DefaultTableName), "another table's index")
+	long := &pq.Error{Code: "23514", Constraint: "todo_tasks_description_length"}
+	assert.Equal(t, ErrDescriptionTooLong, translateWriteError(long, "todo_tasks"))
+}
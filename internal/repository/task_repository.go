@@ -1,52 +1,659 @@
 package repository
 
 import (
+	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"expvar"
 	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/lib/pq"
 )
 
 // TaskRepository defines the interface for task data operations
 type TaskRepository interface {
 	Create(task *models.Task) error
+	// CreateWithID inserts task under its own ID and its status, rather than a generated ID and
+	// pending, then moves the ID sequence past it. ErrTaskIDTaken is returned when any task,
+	// deleted or not, already has the ID.
+	CreateWithID(task *models.Task) error
 	GetByID(id int) (*models.Task, error)
-	GetAll() ([]*models.Task, error)
+	GetIDByUUID(uuid string) (int, error)
+	// GetByTitle returns the oldest live task whose normalized title (trimmed, case-insensitive)
+	// matches title's
+	GetByTitle(title string) (*models.Task, error)
+	// LockTitle holds a transaction-scoped lock on title's normalized form until the transaction
+	// ends, so check-then-create sequences for one title don't interleave. Only useful inside
+	// WithTransaction.
+	LockTitle(title string) error
+	GetByIDs(ids []int) ([]*models.Task, error)
+	GetAll(filter models.ListFilter) ([]*models.Task, error)
+	GetSummaries(filter models.ListFilter) ([]*models.TaskSummary, error)
+	GetChanges(after models.ChangesCursor, limit int) ([]*models.Task, error)
+	// ChangesWatermark returns a database time before which every change is already committed,
+	// so a GetChanges started after it sees all changes up to it (see changesWatermarkQuery)
+	ChangesWatermark() (time.Time, error)
+	GetRecent(limit int) ([]*models.Task, error)
+	// GetOldestPending returns the live pending task created first, or ErrNoTaskAvailable
+	GetOldestPending() (*models.Task, error)
+	// GetRandom returns one live task matching filter at random, or ErrNoTaskAvailable. With
+	// sample it probes the primary key from a random ID instead of shuffling every match.
+	GetRandom(filter models.ListFilter, sample bool) (*models.Task, error)
+	CountByDay(from, to time.Time) ([]*models.DailyMetrics, error)
+	// CountByAssignee counts live tasks per assignee and status, optionally only those with one
+	// of statuses
+	CountByAssignee(statuses []string) ([]*models.AssigneeWorkload, error)
 	Update(task *models.Task) error
 	Delete(id int) error
+	Revive(id int) error
+	Claim(workerID string, lease time.Duration) (*models.Task, error)
+	Release(id int, workerID string) (*models.Task, error)
+	Reopen(id int, status, reason string) (*models.Task, error)
+	ReclaimExpiredLeases() ([]int, error)
+	// Reassign moves every live task assigned to from over to to in one statement and returns
+	// the tasks as updated
+	Reassign(from, to string) ([]*models.Task, error)
+	// EscalatePriorities raises the priority of every live task in one of statuses that was due
+	// before dueBefore by one level, leaving those already at the highest, and returns the tasks
+	// as updated
+	EscalatePriorities(statuses []string, dueBefore models.Date) ([]*models.Task, error)
+	AddAuditEntry(entry *models.AuditEntry) error
+	CreateTemplate(tmpl *models.TaskTemplate) error
+	GetTemplate(id int) (*models.TaskTemplate, error)
+	ListTemplates() ([]*models.TaskTemplate, error)
+	DeleteTemplate(id int) error
+	// RunMaintenance runs a maintenance operation such as ANALYZE on the tasks table
+	RunMaintenance(ctx context.Context, op MaintenanceOp) error
+	// WithTransaction runs fn with a repository whose operations all belong to one database
+	// transaction. It commits if fn returns nil and rolls back if fn returns an error or panics.
+	// Calling WithTransaction on that repository again joins the same transaction.
+	WithTransaction(ctx context.Context, fn func(repo TaskRepository) error) error
+	Close() error
 }
 
 var ErrTaskNotFound = errors.New("task not found") //export a custom error
 
+var (
+	// ErrNoTaskAvailable is returned by Claim when no pending task can be claimed, by
+	// GetOldestPending when there are no pending tasks, and by GetRandom when no task matches
+	ErrNoTaskAvailable = errors.New("no pending task available")
+	// ErrTaskNotClaimed is returned by Release when the task isn't claimed (by that worker)
+	ErrTaskNotClaimed = errors.New("task is not claimed")
+	// ErrTaskNotCompleted is returned by Reopen when the task isn't completed
+	ErrTaskNotCompleted = errors.New("task is not completed")
+	// ErrDuplicateTask is returned by Create and Update when another live task has the same title
+	ErrDuplicateTask = errors.New("a task with this title already exists")
+	// ErrTaskIDTaken is returned by CreateWithID when a task, possibly a deleted one, has the ID
+	ErrTaskIDTaken = errors.New("task ID is already taken")
+	// ErrDescriptionTooLong is returned by Create and Update when the description breaks the
+	// tasks_description_length constraint
+	ErrDescriptionTooLong = errors.New("description is too long")
+)
+
+// timestampViolations counts tasks read with updated_at before created_at (see checkTimestamps).
+// It is published through expvar under "tasks_timestamp_violations_total".
+var timestampViolations = expvar.NewInt("tasks_timestamp_violations_total")
+
+// Postgres SQLSTATEs for the constraint violations translated below
+const (
+	uniqueViolation = "23505"
+	checkViolation  = "23514"
+)
+
+// translateWriteError maps constraint violations the API knows about onto repository errors.
+// The unique title index is the optional partial index created by scripts/setup-db.sh when
+// UNIQUE_TASK_TITLES=true; it only covers rows where deleted_at IS NULL.
+func translateWriteError(err error, table string) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch {
+	case pqErr.Code == uniqueViolation && pqErr.Constraint == uniqueTitleIndex(table):
+		return ErrDuplicateTask
+	case pqErr.Code == checkViolation && pqErr.Constraint == descriptionLengthConstraint(table):
+		return ErrDescriptionTooLong
+	}
+	return err
+}
+
+// listOrderNewestFirst and listOrderOldestFirst order the list endpoint by creation time; id
+// breaks ties so tasks created in the same instant always come back in the same order
+const (
+	listOrderNewestFirst = ` ORDER BY created_at DESC, id DESC`
+	listOrderOldestFirst = ` ORDER BY created_at ASC, id ASC`
+)
+
+// sortColumns maps each models.SortField to the column it orders by, so only these names are
+// ever interpolated into an ORDER BY
+var sortColumns = map[models.SortField]string{
+	models.SortByCreatedAt:   "created_at",
+	models.SortByDueDate:     "due_date",
+	models.SortByCompletedAt: "completed_at",
+}
+
+// taskColumns is the column list read by every query that returns full tasks; it must match scanTask
+const taskColumns = `id, title, description, status, metadata, created_at, updated_at, claimed_by, lease_expires_at, deleted_at,
+    completed_at, reopen_reason, assignee, due_date, uuid, started_at, priority`
+
+// Every query is prepared once and reused so Postgres doesn't re-parse it on every call, except
+// those completed by buildListWhere, whose text varies with the filter (see queryFiltered).
+// Deleting only sets deleted_at, so every query other than the changes feed skips those rows.
+// An unassigned task is always stored with a NULL assignee, never an empty string.
+// Writes set updated_at to GREATEST(NOW(), created_at), so a database clock that has stepped
+// back since the task was created can't leave it earlier than created_at.
+const (
+	createTaskQuery = `
+        INSERT INTO {tasks} (title, description, status, metadata, assignee, due_date, uuid, priority, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, NOW(), NOW())
+        RETURNING id, created_at, updated_at
+    `
+	// createTaskWithIDQuery stamps completed_at and started_at as updateTaskQuery would for the status
+	createTaskWithIDQuery = `
+        INSERT INTO {tasks} (id, title, description, status, metadata, assignee, due_date, uuid, priority, created_at, updated_at,
+            completed_at, started_at)
+        VALUES ($8, $1, $2, $3, $4, NULLIF($5, ''), $6, $7, $9, NOW(), NOW(),
+            CASE WHEN $3 = 'completed' THEN NOW() END, CASE WHEN $3 = 'in_progress' THEN NOW() END)
+        RETURNING ` + taskColumns + `
+    `
+	// advanceIDSequenceQuery moves the id sequence up to $1 so generated IDs don't collide with an
+	// explicit one. It never moves the sequence back. The read and the setval aren't atomic, so a
+	// concurrent insert can still take an ID the sequence goes on to hand out again; that insert
+	// then fails with a duplicate key rather than overwriting anything.
+	advanceIDSequenceQuery = `
+        SELECT setval(pg_get_serial_sequence('{tasks}', 'id'),
+            GREATEST($1, COALESCE(pg_sequence_last_value(pg_get_serial_sequence('{tasks}', 'id')::regclass), 0)))
+    `
+	getTaskByIDQuery   = `SELECT ` + taskColumns + ` FROM {tasks} WHERE id = $1 AND deleted_at IS NULL`
+	getIDByUUIDQuery   = `SELECT id FROM {tasks} WHERE uuid = $1 AND deleted_at IS NULL`
+	getTasksByIDsQuery = `SELECT ` + taskColumns + ` FROM {tasks} WHERE id = ANY($1) AND deleted_at IS NULL ORDER BY id`
+	getAllTasksQuery   = `SELECT ` + taskColumns + ` FROM {tasks}`
+	// getTaskSummariesQuery reads only the TaskSummary columns, leaving descriptions and metadata behind
+	getTaskSummariesQuery = `SELECT id, uuid, title, status, priority, due_date FROM {tasks}`
+	getTaskByTitleQuery   = `
+        SELECT ` + taskColumns + ` FROM {tasks}
+        WHERE lower(btrim(title)) = lower(btrim($1)) AND deleted_at IS NULL
+        ORDER BY id LIMIT 1`
+	// lockTitleQuery takes an advisory lock keyed on the normalized title; hash collisions only
+	// make unrelated titles wait for each other
+	lockTitleQuery = `SELECT pg_advisory_xact_lock(hashtext('task-title:' || lower(btrim($1))))`
+	// updateTaskQuery stamps completed_at when a task first becomes completed and clears it otherwise.
+	// started_at is stamped the first time a task is in progress and then kept. An empty
+	// priority keeps the stored one.
+	updateTaskQuery = `
+        UPDATE {tasks}
+        SET title = $1, description = $2, status = $3, metadata = $4, assignee = NULLIF($6, ''), due_date = $7,
+            priority = COALESCE(NULLIF($8, ''), priority),
+            updated_at = GREATEST(NOW(), created_at),
+            completed_at = CASE WHEN $3 = 'completed' THEN COALESCE(completed_at, NOW()) END,
+            started_at = CASE WHEN $3 = 'in_progress' THEN COALESCE(started_at, NOW()) ELSE started_at END
+        WHERE id = $5 AND deleted_at IS NULL
+        RETURNING ` + taskColumns + `
+    `
+	// deleteTaskQuery soft-deletes; bumping updated_at puts the deletion in the changes feed
+	deleteTaskQuery = `UPDATE {tasks} SET deleted_at = NOW(), updated_at = GREATEST(NOW(), created_at) WHERE id = $1 AND deleted_at IS NULL`
+	// reviveTaskQuery undoes a soft delete; the updated_at bump shows the task again in the changes feed
+	reviveTaskQuery = `UPDATE {tasks} SET deleted_at = NULL, updated_at = GREATEST(NOW(), created_at) WHERE id = $1 AND deleted_at IS NOT NULL`
+	// getChangesQuery pages through every task, deleted or not, in (updated_at, id) order
+	getChangesQuery = `
+        SELECT ` + taskColumns + ` FROM {tasks}
+        WHERE (updated_at, id) > ($1, $2)
+        ORDER BY updated_at, id
+        LIMIT $3
+    `
+	// changesWatermarkQuery finds the time before which no uncommitted change can land. Writes
+	// stamp updated_at with NOW(), their transaction's start, so a transaction that is still open
+	// can add a row dated before the current time. Taking the start of the oldest open
+	// transaction (or the current time when there is none) bounds every such row from below.
+	// The microsecond off keeps a row stamped exactly at the watermark in the next sync, whose
+	// first page excludes rows updated exactly at since. Sessions of other roles show no
+	// xact_start and are not counted.
+	changesWatermarkQuery = `
+        SELECT LEAST(statement_timestamp(), MIN(xact_start)) - interval '1 microsecond'
+        FROM pg_stat_activity
+        WHERE datname = current_database() AND backend_type = 'client backend'
+    `
+	// getRecentTasksQuery reads idx_tasks_updated_at backwards, so it stops after limit rows
+	getRecentTasksQuery = `
+        SELECT ` + taskColumns + ` FROM {tasks}
+        WHERE deleted_at IS NULL
+        ORDER BY updated_at DESC, id DESC
+        LIMIT $1
+    `
+	// getOldestPendingQuery reads the head of idx_tasks_pending_queue
+	getOldestPendingQuery = `
+        SELECT ` + taskColumns + ` FROM {tasks}
+        WHERE status = 'pending' AND deleted_at IS NULL
+        ORDER BY created_at ASC, id ASC
+        LIMIT 1
+    `
+
+	// randomOrder shuffles every matching row, so it costs a full scan and sort of the matches
+	randomOrder = ` ORDER BY random() LIMIT 1`
+	// randomPivot starts a sampled pick at a random ID up to the largest one. The subquery runs
+	// once and max(id) is read from the primary key index.
+	randomPivot = `id >= (SELECT floor(random() * COALESCE(max(id), 0))::bigint + 1 FROM {tasks})`
+	// firstByID finishes a sampled pick: the first match in ID order from the pivot, if any
+	firstByID = ` ORDER BY id LIMIT 1`
+
+	// claimTaskQuery atomically takes the oldest pending task. SKIP LOCKED lets concurrent
+	// workers each grab a different row instead of blocking on the same one.
+	claimTaskQuery = `
+        UPDATE {tasks}
+        SET status = 'in_progress', claimed_by = $1,
+            lease_expires_at = NOW() + make_interval(secs => $2::double precision), updated_at = GREATEST(NOW(), created_at),
+            started_at = COALESCE(started_at, NOW())
+        WHERE id = (
+            SELECT id FROM {tasks}
+            WHERE status = 'pending' AND deleted_at IS NULL
+              AND (lease_expires_at IS NULL OR lease_expires_at <= NOW())
+            ORDER BY created_at, id
+            LIMIT 1
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING ` + taskColumns
+	// reclaimExpiredLeasesQuery requeues tasks whose worker stopped renewing (e.g. crashed)
+	reclaimExpiredLeasesQuery = `
+        UPDATE {tasks}
+        SET status = 'pending', claimed_by = NULL, lease_expires_at = NULL, updated_at = GREATEST(NOW(), created_at)
+        WHERE status = 'in_progress' AND lease_expires_at <= NOW() AND deleted_at IS NULL
+        RETURNING id
+    `
+	// reassignTasksQuery hands all of one assignee's live tasks to another
+	reassignTasksQuery = `
+        UPDATE {tasks}
+        SET assignee = $2, updated_at = GREATEST(NOW(), created_at)
+        WHERE assignee = $1 AND deleted_at IS NULL
+        RETURNING ` + taskColumns
+	// escalatePrioritiesQuery raises overdue tasks one priority level; the CASE and the IN list
+	// follow models.Priorities. status <> 'completed' repeats what $1 already ensures, so the
+	// planner can use the partial due_date index.
+	escalatePrioritiesQuery = `
+        UPDATE {tasks}
+        SET priority = CASE priority WHEN 'low' THEN 'medium' ELSE 'high' END, updated_at = GREATEST(NOW(), created_at)
+        WHERE status = ANY($1) AND due_date < $2 AND priority IN ('low', 'medium')
+            AND status <> 'completed' AND deleted_at IS NULL
+        RETURNING ` + taskColumns
+	// reopenTaskQuery moves a completed task back to an open status, recording why
+	reopenTaskQuery = `
+        UPDATE {tasks}
+        SET status = $2, completed_at = NULL, reopen_reason = $3, updated_at = GREATEST(NOW(), created_at),
+            started_at = CASE WHEN $2 = 'in_progress' THEN COALESCE(started_at, NOW()) ELSE started_at END
+        WHERE id = $1 AND status = 'completed' AND deleted_at IS NULL
+        RETURNING ` + taskColumns
+	// releaseTaskQuery requeues a claimed task; an empty worker ID releases regardless of owner
+	releaseTaskQuery = `
+        UPDATE {tasks}
+        SET status = 'pending', claimed_by = NULL, lease_expires_at = NULL, updated_at = GREATEST(NOW(), created_at)
+        WHERE id = $1 AND status = 'in_progress' AND claimed_by IS NOT NULL AND deleted_at IS NULL
+          AND ($2::text = '' OR claimed_by = $2::text)
+        RETURNING ` + taskColumns
+	// dailyCreatedQuery and dailyCompletedQuery count live tasks per UTC day in [$1, $2)
+	dailyCreatedQuery = `
+        SELECT date_trunc('day', created_at AT TIME ZONE 'UTC') AS day, COUNT(*) FROM {tasks}
+        WHERE deleted_at IS NULL AND created_at >= $1 AND created_at < $2
+        GROUP BY day`
+	dailyCompletedQuery = `
+        SELECT date_trunc('day', completed_at AT TIME ZONE 'UTC') AS day, COUNT(*) FROM {tasks}
+        WHERE deleted_at IS NULL AND completed_at >= $1 AND completed_at < $2
+        GROUP BY day`
+	// workloadQuery is completed by buildListWhere; the grouping follows the WHERE clause
+	workloadQuery         = `SELECT NULLIF(assignee, ''), status, COUNT(*) FROM {tasks}`
+	workloadGroupBy       = ` GROUP BY 1, 2`
+	insertAuditEntryQuery = `
+        INSERT INTO {audit} (task_id, actor, action, from_status, to_status, note)
+        VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''))
+        RETURNING id, created_at`
+)
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTask reads a row selected with taskColumns into a Task
+func scanTask(row rowScanner) (*models.Task, error) {
+	task := &models.Task{}
+	var metadata []byte
+	var claimedBy, reopenReason, assignee, uuid sql.NullString
+	var leaseExpiresAt, deletedAt, completedAt, dueDate, startedAt sql.NullTime
+	if err := row.Scan(
+		&task.ID, &task.Title, &task.Description, &task.Status, &metadata, &task.CreatedAt, &task.UpdatedAt,
+		&claimedBy, &leaseExpiresAt, &deletedAt, &completedAt, &reopenReason, &assignee, &dueDate, &uuid,
+		&startedAt, &task.Priority,
+	); err != nil {
+		return nil, err
+	}
+	if claimedBy.Valid {
+		task.ClaimedBy = &claimedBy.String
+	}
+	if leaseExpiresAt.Valid {
+		task.LeaseExpiresAt = &leaseExpiresAt.Time
+	}
+	if deletedAt.Valid {
+		task.DeletedAt = &deletedAt.Time
+		task.Deleted = true
+	}
+	if completedAt.Valid {
+		task.CompletedAt = &completedAt.Time
+	}
+	if startedAt.Valid {
+		task.StartedAt = &startedAt.Time
+	}
+	task.ReopenReason = reopenReason.String
+	task.Assignee = assignee.String
+	task.UUID = uuid.String
+	if dueDate.Valid {
+		d := models.NewDate(dueDate.Time)
+		task.DueDate = &d
+	}
+	normalizeTimes(task)
+	checkTimestamps(task)
+	task.Metadata = map[string]interface{}{}
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &task.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to decode metadata for task %d: %w", task.ID, err)
+		}
+	}
+	return task, nil
+}
+
+// checkTimestamps enforces updated_at >= created_at on a task read from the database. The
+// queries never write a row that breaks it, so one that does was corrupted or imported: it is
+// logged and counted, and updated_at is raised to created_at rather than returned as it is.
+func checkTimestamps(task *models.Task) {
+	if !task.UpdatedAt.Before(task.CreatedAt) {
+		return
+	}
+	timestampViolations.Add(1)
+	log.Printf("Task %d: updated_at %s is before created_at %s; using created_at",
+		task.ID, task.UpdatedAt.Format(time.RFC3339Nano), task.CreatedAt.Format(time.RFC3339Nano))
+	task.UpdatedAt = task.CreatedAt
+}
+
+// normalizeTimes converts a task's timestamps to UTC. lib/pq returns timestamptz values in the
+// session's zone, and the API always reports UTC.
+func normalizeTimes(task *models.Task) {
+	task.CreatedAt = task.CreatedAt.UTC()
+	task.UpdatedAt = task.UpdatedAt.UTC()
+	if task.LeaseExpiresAt != nil {
+		t := task.LeaseExpiresAt.UTC()
+		task.LeaseExpiresAt = &t
+	}
+	if task.DeletedAt != nil {
+		t := task.DeletedAt.UTC()
+		task.DeletedAt = &t
+	}
+	if task.CompletedAt != nil {
+		t := task.CompletedAt.UTC()
+		task.CompletedAt = &t
+	}
+	if task.StartedAt != nil {
+		t := task.StartedAt.UTC()
+		task.StartedAt = &t
+	}
+}
+
+// dueDateParam turns an optional due date into a DATE parameter
+func dueDateParam(d *models.Date) interface{} {
+	if d == nil {
+		return nil
+	}
+	return d.String()
+}
+
+// encodeMetadata serialises metadata for a jsonb parameter. It is passed as a string because
+// lib/pq sends []byte as bytea, which Postgres won't cast to jsonb.
+func encodeMetadata(metadata map[string]interface{}) (string, error) {
+	if metadata == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	return string(b), nil
+}
+
 // taskRepository is an implementation of TaskRepository that interacts with a SQL database
 type taskRepository struct {
 	db *sql.DB
+
+	mu    sync.RWMutex
+	stmts map[string]*sql.Stmt // prepared statements keyed by query text
+
+	// tx and base are only set on the copy handed to a WithTransaction callback. Statements
+	// are still prepared and cached on base, then bound to tx for each use.
+	tx   *sql.Tx
+	base *taskRepository
+
+	// queryHook, when set, is told about every statement run (see WithQueryHook)
+	queryHook QueryHook
+	// table is the tasks table queries run against (see WithTableName)
+	table string
 }
 
 // NewTaskRepository creates a new instance of TaskRepository
-func NewTaskRepository(db *sql.DB) TaskRepository {
-	return &taskRepository{db: db}
+func NewTaskRepository(db *sql.DB, opts ...Option) TaskRepository {
+	r := &taskRepository{db: db, stmts: make(map[string]*sql.Stmt), table: DefaultTableName}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// stmt returns the prepared statement for query, with the tasks table name filled in, bound to
+// the transaction if there is one and reporting to the query hook if one is set
+func (r *taskRepository) stmt(query string) (*hookedStmt, error) {
+	query = expandTable(query, r.table)
+	base := r
+	if r.tx != nil {
+		base = r.base
+	}
+	stmt, err := base.prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	if r.tx != nil {
+		stmt = r.tx.Stmt(stmt)
+	}
+	return &hookedStmt{Stmt: stmt, query: query, hook: r.queryHook}, nil
+}
+
+// queryer is what runs an unprepared query: the database, or the transaction if there is one
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// conn returns the transaction if there is one, and the database otherwise
+func (r *taskRepository) conn() queryer {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+// queryFiltered runs a query whose text depends on a ListFilter (list, summary, workload and
+// random queries). It is not prepared: each combination of filters is new SQL text, and caching
+// a statement per combination would let clients grow the prepared statements held here and in
+// Postgres without limit. Queries with fixed text go through stmt.
+func (r *taskRepository) queryFiltered(query string, args []interface{}) (*sql.Rows, error) {
+	query = expandTable(query, r.table)
+	start := time.Now()
+	rows, err := r.conn().Query(query, args...)
+	reportQuery(r.queryHook, query, start, args, err)
+	return rows, err
 }
 
-// Create inserts a new task into the database
+// queryRowFiltered is queryFiltered for a query read with Scan
+func (r *taskRepository) queryRowFiltered(query string, args []interface{}) *sql.Row {
+	query = expandTable(query, r.table)
+	start := time.Now()
+	row := r.conn().QueryRow(query, args...)
+	reportQuery(r.queryHook, query, start, args, row.Err())
+	return row
+}
+
+// prepare returns the prepared statement for query, preparing it on first use.
+// The read lock keeps the common (already prepared) path cheap under concurrency.
+func (r *taskRepository) prepare(query string) (*sql.Stmt, error) {
+
+	r.mu.RLock()
+	stmt, ok := r.stmts[query]
+	r.mu.RUnlock()
+	if ok {
+		return stmt, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Another goroutine may have prepared it while we waited for the write lock
+	if stmt, ok := r.stmts[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := r.db.PrepareContext(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	r.stmts[query] = stmt
+	return stmt, nil
+}
+
+// WithTransaction runs fn inside a database transaction; see TaskRepository
+func (r *taskRepository) WithTransaction(ctx context.Context, fn func(repo TaskRepository) error) (err error) {
+	if r.tx != nil {
+		return fn(r)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(&taskRepository{db: r.db, tx: tx, base: r, queryHook: r.queryHook, table: r.table}); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to roll back transaction: %w", rbErr))
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Close releases all prepared statements. It does not close the underlying *sql.DB.
+// On a transaction's repository it does nothing, since the statements belong to the base.
+func (r *taskRepository) Close() error {
+	if r.tx != nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []error
+	for query, stmt := range r.stmts {
+		if err := stmt.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(r.stmts, query)
+	}
+	return errors.Join(errs...)
+}
+
+// Create inserts a new task into the database, giving it a random UUID, and the default
+// priority if it has none
 func (r *taskRepository) Create(task *models.Task) error {
-	query := `
-        INSERT INTO tasks (title, description, status, created_at, updated_at)
-        VALUES ($1, $2, $3, NOW(), NOW())
-        RETURNING id, created_at, updated_at
-    `
-	return r.db.QueryRow(query, task.Title, task.Description, task.Status).
-		Scan(&task.ID, &task.CreatedAt, &task.UpdatedAt)
+	metadata, err := encodeMetadata(task.Metadata)
+	if err != nil {
+		return err
+	}
+	if task.UUID == "" {
+		if task.UUID, err = newUUID(); err != nil {
+			return err
+		}
+	}
+	if task.Priority == "" {
+		task.Priority = models.DefaultPriority
+	}
+	stmt, err := r.stmt(createTaskQuery)
+	if err != nil {
+		return err
+	}
+	if err := stmt.QueryRow(task.Title, task.Description, task.Status, metadata, task.Assignee, dueDateParam(task.DueDate), task.UUID, task.Priority).
+		Scan(&task.ID, &task.CreatedAt, &task.UpdatedAt); err != nil {
+		return translateWriteError(err, r.table)
+	}
+	normalizeTimes(task)
+	return nil
+}
+
+// CreateWithID inserts task with the ID it already has. Call it inside WithTransaction so the
+// insert and the sequence update commit together.
+func (r *taskRepository) CreateWithID(task *models.Task) error {
+	if task.ID <= 0 {
+		return fmt.Errorf("invalid task ID %d", task.ID)
+	}
+	metadata, err := encodeMetadata(task.Metadata)
+	if err != nil {
+		return err
+	}
+	if task.UUID == "" {
+		if task.UUID, err = newUUID(); err != nil {
+			return err
+		}
+	}
+	if task.Priority == "" {
+		task.Priority = models.DefaultPriority
+	}
+	stmt, err := r.stmt(createTaskWithIDQuery)
+	if err != nil {
+		return err
+	}
+	stored, err := scanTask(stmt.QueryRow(task.Title, task.Description, task.Status, metadata, task.Assignee, dueDateParam(task.DueDate), task.UUID, task.ID, task.Priority))
+	if err != nil {
+		// Only an explicit ID can collide with the primary key, so this isn't in translateWriteError
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == primaryKeyConstraint(r.table) {
+			return ErrTaskIDTaken
+		}
+		return translateWriteError(err, r.table)
+	}
+	*task = *stored
+
+	seq, err := r.stmt(advanceIDSequenceQuery)
+	if err != nil {
+		return err
+	}
+	if _, err := seq.Exec(task.ID); err != nil {
+		return fmt.Errorf("failed to advance task ID sequence: %w", err)
+	}
+	return nil
 }
 
 // GetByID retrieves a task by its ID from the database
 func (r *taskRepository) GetByID(id int) (*models.Task, error) {
-	task := &models.Task{}
-	query := `SELECT id, title, description, status, created_at, updated_at FROM tasks WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(
-		&task.ID, &task.Title, &task.Description, &task.Status, &task.CreatedAt, &task.UpdatedAt,
-	)
+	stmt, err := r.stmt(getTaskByIDQuery)
+	if err != nil {
+		return nil, err
+	}
+	task, err := scanTask(stmt.QueryRow(id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrTaskNotFound
@@ -56,9 +663,65 @@ func (r *taskRepository) GetByID(id int) (*models.Task, error) {
 	return task, nil
 }
 
-// GetAll retrieves all tasks from the database
-func (r *taskRepository) GetAll() ([]*models.Task, error) {
-	rows, err := r.db.Query(`SELECT id, title, description, status, created_at, updated_at FROM tasks ORDER BY created_at DESC`)
+// GetIDByUUID returns the ID of the live task with the given UUID
+func (r *taskRepository) GetIDByUUID(uuid string) (int, error) {
+	stmt, err := r.stmt(getIDByUUIDQuery)
+	if err != nil {
+		return 0, err
+	}
+	var id int
+	if err := stmt.QueryRow(uuid).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrTaskNotFound
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// newUUID returns a random (version 4) UUID in canonical form
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate UUID: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// GetByIDs retrieves the tasks with the given IDs in a single query, ordered by ID.
+// IDs that don't exist are simply absent from the result.
+func (r *taskRepository) GetByIDs(ids []int) ([]*models.Task, error) {
+	stmt, err := r.stmt(getTasksByIDsQuery)
+	if err != nil {
+		return nil, err
+	}
+	ids64 := make([]int64, len(ids))
+	for i, id := range ids {
+		ids64[i] = int64(id)
+	}
+	rows, err := stmt.Query(pq.Array(ids64))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []*models.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+// GetAll retrieves all tasks matching the filter from the database
+func (r *taskRepository) GetAll(filter models.ListFilter) ([]*models.Task, error) {
+	query, args := buildListQuery(getAllTasksQuery, filter)
+	rows, err := r.queryFiltered(query, args)
 	if err != nil {
 		return nil, err
 	}
@@ -66,8 +729,8 @@ func (r *taskRepository) GetAll() ([]*models.Task, error) {
 
 	tasks := []*models.Task{}
 	for rows.Next() {
-		task := &models.Task{}
-		if err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.Status, &task.CreatedAt, &task.UpdatedAt); err != nil {
+		task, err := scanTask(rows)
+		if err != nil {
 			return nil, err
 		}
 		tasks = append(tasks, task)
@@ -75,21 +738,326 @@ func (r *taskRepository) GetAll() ([]*models.Task, error) {
 	return tasks, nil
 }
 
-// Update modifies an existing task in the database
+// buildListQuery completes a list query with the filter's WHERE clause, its order (see
+// listOrder) and, when the filter has one, a LIMIT
+func buildListQuery(base string, filter models.ListFilter) (string, []interface{}) {
+	where, args := buildListWhere(filter)
+	query := base + where + listOrder(filter)
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	return query, args
+}
+
+// listOrder is the ORDER BY for a list: newest first unless the filter asks for oldest first,
+// or by the filter's SortBy field, descending unless it asks for ascending. Postgres puts NULLs
+// last in ascending order but first in descending, so a nullable field always gets an explicit
+// NULLS LAST, or NULLS FIRST when the filter asks for it.
+func listOrder(filter models.ListFilter) string {
+	column, ok := sortColumns[filter.SortBy]
+	if !ok || column == "created_at" {
+		if filter.Order == models.SortOldestFirst {
+			return listOrderOldestFirst
+		}
+		return listOrderNewestFirst
+	}
+	dir := "DESC"
+	if filter.Order == models.SortOldestFirst {
+		dir = "ASC"
+	}
+	nulls := "LAST"
+	if filter.NullsFirst {
+		nulls = "FIRST"
+	}
+	return fmt.Sprintf(" ORDER BY %s %s NULLS %s, id %s", column, dir, nulls, dir)
+}
+
+// buildListWhere turns a ListFilter into a WHERE clause and its positional arguments.
+// Only placeholders are interpolated into the SQL; keys and values are always passed as
+// parameters, so the query text depends only on the shape of the filter.
+func buildListWhere(filter models.ListFilter) (string, []interface{}) {
+	var conds []string
+	if !filter.IncludeDeleted {
+		conds = append(conds, "deleted_at IS NULL")
+	}
+	var args []interface{}
+
+	if len(filter.Statuses) > 0 {
+		args = append(args, pq.Array(filter.Statuses))
+		conds = append(conds, fmt.Sprintf("status = ANY($%d)", len(args)))
+	}
+
+	// Rows written before NULLIF was applied on write may still hold ''
+	if filter.Unassigned {
+		conds = append(conds, "(assignee IS NULL OR assignee = '')")
+	} else if filter.Assignee != "" {
+		args = append(args, filter.Assignee)
+		conds = append(conds, fmt.Sprintf("assignee = $%d", len(args)))
+	}
+
+	if filter.HasDueDate {
+		conds = append(conds, "due_date IS NOT NULL")
+	}
+	// Spelled out rather than left to Statuses so the planner can match the partial due_date index
+	if filter.Open {
+		conds = append(conds, "status <> 'completed'")
+	}
+
+	if filter.CreatedAfter != nil {
+		args = append(args, *filter.CreatedAfter)
+		conds = append(conds, fmt.Sprintf("created_at > $%d", len(args)))
+	}
+	if filter.CreatedBefore != nil {
+		args = append(args, *filter.CreatedBefore)
+		conds = append(conds, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+
+	// Only audited changes carry an actor, so an actor filter is answered from the audit table, with
+	// the date bounds applying to that actor's changes rather than to the task's updated_at
+	if filter.UpdatedBy != "" {
+		args = append(args, filter.UpdatedBy)
+		audit := []string{fmt.Sprintf("actor = $%d", len(args))}
+		if filter.UpdatedAfter != nil {
+			args = append(args, *filter.UpdatedAfter)
+			audit = append(audit, fmt.Sprintf("created_at > $%d", len(args)))
+		}
+		if filter.UpdatedBefore != nil {
+			args = append(args, *filter.UpdatedBefore)
+			audit = append(audit, fmt.Sprintf("created_at < $%d", len(args)))
+		}
+		conds = append(conds, "id IN (SELECT task_id FROM {audit} WHERE "+strings.Join(audit, " AND ")+")")
+	} else {
+		if filter.UpdatedAfter != nil {
+			args = append(args, *filter.UpdatedAfter)
+			conds = append(conds, fmt.Sprintf("updated_at > $%d", len(args)))
+		}
+		if filter.UpdatedBefore != nil {
+			args = append(args, *filter.UpdatedBefore)
+			conds = append(conds, fmt.Sprintf("updated_at < $%d", len(args)))
+		}
+	}
+
+	// Sort keys so the same filter always yields the same SQL, which keeps query logs comparable
+	// and lets tests assert on the exact text
+	keys := make([]string, 0, len(filter.Metadata))
+	for k := range filter.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, k, filter.Metadata[k])
+		// The ? existence check lets Postgres use the GIN index on metadata; ->> then
+		// compares as text so numbers and booleans match their query-string form
+		conds = append(conds, fmt.Sprintf("metadata ? $%d AND metadata->>$%d = $%d", len(args)-1, len(args)-1, len(args)))
+	}
+
+	for _, k := range filter.MetadataKeys {
+		args = append(args, k)
+		conds = append(conds, fmt.Sprintf("metadata ? $%d", len(args)))
+	}
+
+	if len(conds) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// Update modifies an existing task in the database and overwrites task with the row as stored,
+// so values changed by the database (defaults, triggers, NULLIF) are what the caller sees
 func (r *taskRepository) Update(task *models.Task) error {
-	query := `
-        UPDATE tasks
-        SET title = $1, description = $2, status = $3, updated_at = NOW()
-        WHERE id = $4
-        RETURNING updated_at
-    `
-	return r.db.QueryRow(query, task.Title, task.Description, task.Status, task.ID).
-		Scan(&task.UpdatedAt)
+	metadata, err := encodeMetadata(task.Metadata)
+	if err != nil {
+		return err
+	}
+	stmt, err := r.stmt(updateTaskQuery)
+	if err != nil {
+		return err
+	}
+	stored, err := scanTask(stmt.QueryRow(task.Title, task.Description, task.Status, metadata, task.ID, task.Assignee, dueDateParam(task.DueDate), task.Priority))
+	if err != nil {
+		return translateWriteError(err, r.table)
+	}
+	*task = *stored
+	return nil
 }
 
-// Delete removes a task by its ID from the database
+// CountByDay counts the tasks created and completed on each UTC day in [from, to), oldest day
+// first. Days on which nothing was created or completed are left out. Deleted tasks aren't counted.
+func (r *taskRepository) CountByDay(from, to time.Time) ([]*models.DailyMetrics, error) {
+	days := map[string]*models.DailyMetrics{}
+	count := func(query string, field func(*models.DailyMetrics) *int) error {
+		stmt, err := r.stmt(query)
+		if err != nil {
+			return err
+		}
+		rows, err := stmt.Query(from, to)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var day time.Time
+			var n int
+			if err := rows.Scan(&day, &n); err != nil {
+				return err
+			}
+			date := models.NewDate(day)
+			if days[date.String()] == nil {
+				days[date.String()] = &models.DailyMetrics{Date: date}
+			}
+			*field(days[date.String()]) = n
+		}
+		return rows.Err()
+	}
+	if err := count(dailyCreatedQuery, func(m *models.DailyMetrics) *int { return &m.Created }); err != nil {
+		return nil, err
+	}
+	if err := count(dailyCompletedQuery, func(m *models.DailyMetrics) *int { return &m.Completed }); err != nil {
+		return nil, err
+	}
+
+	metrics := make([]*models.DailyMetrics, 0, len(days))
+	for _, m := range days {
+		metrics = append(metrics, m)
+	}
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Date.Before(metrics[j].Date.Time) })
+	return metrics, nil
+}
+
+// CountByAssignee counts live tasks per assignee and status in one grouped query, returning a
+// row per assignee sorted by name with unassigned tasks last. An empty statuses counts every status.
+func (r *taskRepository) CountByAssignee(statuses []string) ([]*models.AssigneeWorkload, error) {
+	where, args := buildListWhere(models.ListFilter{Statuses: statuses})
+	rows, err := r.queryFiltered(workloadQuery+where+workloadGroupBy, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byAssignee := map[string]*models.AssigneeWorkload{}
+	var unassigned *models.AssigneeWorkload
+	for rows.Next() {
+		var assignee sql.NullString
+		var status string
+		var n int
+		if err := rows.Scan(&assignee, &status, &n); err != nil {
+			return nil, err
+		}
+		w := unassigned
+		if assignee.Valid {
+			w = byAssignee[assignee.String]
+		}
+		if w == nil {
+			w = &models.AssigneeWorkload{}
+			if assignee.Valid {
+				name := assignee.String
+				w.Assignee = &name
+				byAssignee[name] = w
+			} else {
+				unassigned = w
+			}
+		}
+		switch status {
+		case "pending":
+			w.Pending = n
+		case "in_progress":
+			w.InProgress = n
+		case "completed":
+			w.Completed = n
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	workload := make([]*models.AssigneeWorkload, 0, len(byAssignee)+1)
+	for _, w := range byAssignee {
+		workload = append(workload, w)
+	}
+	sort.Slice(workload, func(i, j int) bool { return *workload[i].Assignee < *workload[j].Assignee })
+	if unassigned != nil {
+		workload = append(workload, unassigned)
+	}
+	return workload, nil
+}
+
+// AddAuditEntry records an audited change to a task, filling in the entry's ID and CreatedAt.
+// Call it inside the transaction that makes the change so the two can't disagree.
+func (r *taskRepository) AddAuditEntry(entry *models.AuditEntry) error {
+	stmt, err := r.stmt(insertAuditEntryQuery)
+	if err != nil {
+		return err
+	}
+	if err := stmt.QueryRow(entry.TaskID, entry.Actor, entry.Action, entry.FromStatus, entry.ToStatus, entry.Note).
+		Scan(&entry.ID, &entry.CreatedAt); err != nil {
+		return err
+	}
+	entry.CreatedAt = entry.CreatedAt.UTC()
+	return nil
+}
+
+// GetSummaries returns the same tasks as GetAll, in the same order, reading only the summary
+// columns
+func (r *taskRepository) GetSummaries(filter models.ListFilter) ([]*models.TaskSummary, error) {
+	query, args := buildListQuery(getTaskSummariesQuery, filter)
+	rows, err := r.queryFiltered(query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := []*models.TaskSummary{}
+	for rows.Next() {
+		summary := &models.TaskSummary{}
+		var uuid sql.NullString
+		var dueDate sql.NullTime
+		if err := rows.Scan(&summary.ID, &uuid, &summary.Title, &summary.Status, &summary.Priority, &dueDate); err != nil {
+			return nil, err
+		}
+		summary.UUID = uuid.String
+		if dueDate.Valid {
+			d := models.NewDate(dueDate.Time)
+			summary.DueDate = &d
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, rows.Err()
+}
+
+// GetByTitle returns the oldest live task with the same normalized title, or ErrTaskNotFound
+func (r *taskRepository) GetByTitle(title string) (*models.Task, error) {
+	stmt, err := r.stmt(getTaskByTitleQuery)
+	if err != nil {
+		return nil, err
+	}
+	task, err := scanTask(stmt.QueryRow(title))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+// LockTitle takes a transaction-scoped advisory lock on title's normalized form; see TaskRepository
+func (r *taskRepository) LockTitle(title string) error {
+	stmt, err := r.stmt(lockTitleQuery)
+	if err != nil {
+		return err
+	}
+	_, err = stmt.Exec(title)
+	return err
+}
+
+// Delete soft-deletes a task by its ID; it stays in the table so the changes feed can report it
 func (r *taskRepository) Delete(id int) error {
-	result, err := r.db.Exec(`DELETE FROM tasks WHERE id = $1`, id)
+	stmt, err := r.stmt(deleteTaskQuery)
+	if err != nil {
+		return err
+	}
+	result, err := stmt.Exec(id)
 	if err != nil {
 		return err
 	}
@@ -98,7 +1066,266 @@ func (r *taskRepository) Delete(id int) error {
 		return err
 	}
 	if rowsAffected == 0 {
-		return fmt.Errorf("task with ID %d not found for deletion", id)
+		return fmt.Errorf("task with ID %d not found for deletion: %w", id, ErrTaskNotFound)
 	}
 	return nil
 }
+
+// Revive clears a soft-deleted task's deleted_at so it is live again. ErrTaskNotFound is
+// returned when no deleted task has the ID, and ErrDuplicateTask when a live task has since
+// taken its title.
+func (r *taskRepository) Revive(id int) error {
+	stmt, err := r.stmt(reviveTaskQuery)
+	if err != nil {
+		return err
+	}
+	result, err := stmt.Exec(id)
+	if err != nil {
+		return translateWriteError(err, r.table)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrTaskNotFound
+	}
+	return nil
+}
+
+// GetChanges returns up to limit tasks, including deleted ones, that come after the cursor
+// in (updated_at, id) order
+func (r *taskRepository) GetChanges(after models.ChangesCursor, limit int) ([]*models.Task, error) {
+	stmt, err := r.stmt(getChangesQuery)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := stmt.Query(after.UpdatedAt, after.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []*models.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+// ChangesWatermark returns the time up to which the changes feed is complete
+func (r *taskRepository) ChangesWatermark() (time.Time, error) {
+	stmt, err := r.stmt(changesWatermarkQuery)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var watermark time.Time
+	if err := stmt.QueryRow().Scan(&watermark); err != nil {
+		return time.Time{}, err
+	}
+	return watermark, nil
+}
+
+// GetRecent returns up to limit live tasks, most recently updated first
+func (r *taskRepository) GetRecent(limit int) ([]*models.Task, error) {
+	stmt, err := r.stmt(getRecentTasksQuery)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := stmt.Query(limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []*models.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+// GetRandom returns a random live task matching filter, or ErrNoTaskAvailable if none does.
+//
+// Without sample every match is shuffled with ORDER BY random(), which is uniform but reads and
+// sorts all of them. With sample the pick starts at a random ID and takes the first match from
+// there in ID order, wrapping around to the lowest match when there is none above it. That is a
+// short index scan however big the table is, but it isn't uniform: a task after a run of deleted
+// or non-matching IDs is picked more often.
+func (r *taskRepository) GetRandom(filter models.ListFilter, sample bool) (*models.Task, error) {
+	where, args := buildListWhere(filter)
+	if !sample {
+		return r.queryOne(getAllTasksQuery+where+randomOrder, args)
+	}
+
+	pivoted := where + " AND " + randomPivot
+	if where == "" {
+		pivoted = " WHERE " + randomPivot
+	}
+	task, err := r.queryOne(getAllTasksQuery+pivoted+firstByID, args)
+	if !errors.Is(err, ErrNoTaskAvailable) {
+		return task, err
+	}
+	return r.queryOne(getAllTasksQuery+where+firstByID, args)
+}
+
+// queryOne runs a query selecting taskColumns and returns its first row, or ErrNoTaskAvailable
+func (r *taskRepository) queryOne(query string, args []interface{}) (*models.Task, error) {
+	task, err := scanTask(r.queryRowFiltered(query, args))
+	if err == sql.ErrNoRows {
+		return nil, ErrNoTaskAvailable
+	}
+	return task, err
+}
+
+// GetOldestPending returns the oldest live pending task, or ErrNoTaskAvailable if there is none
+func (r *taskRepository) GetOldestPending() (*models.Task, error) {
+	stmt, err := r.stmt(getOldestPendingQuery)
+	if err != nil {
+		return nil, err
+	}
+	task, err := scanTask(stmt.QueryRow())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNoTaskAvailable
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+// Claim marks the oldest pending task as in progress for workerID, holding it for lease
+func (r *taskRepository) Claim(workerID string, lease time.Duration) (*models.Task, error) {
+	stmt, err := r.stmt(claimTaskQuery)
+	if err != nil {
+		return nil, err
+	}
+	task, err := scanTask(stmt.QueryRow(workerID, lease.Seconds()))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNoTaskAvailable
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+// Release returns a claimed task to pending. If workerID is non-empty it must match the claimant.
+func (r *taskRepository) Release(id int, workerID string) (*models.Task, error) {
+	stmt, err := r.stmt(releaseTaskQuery)
+	if err != nil {
+		return nil, err
+	}
+	task, err := scanTask(stmt.QueryRow(id, workerID))
+	if err == nil {
+		return task, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	// Nothing was updated: tell "doesn't exist" apart from "exists but isn't claimed"
+	if _, err := r.GetByID(id); err != nil {
+		return nil, err
+	}
+	return nil, ErrTaskNotClaimed
+}
+
+// Reopen moves a completed task to status, clearing completed_at and recording reason
+func (r *taskRepository) Reopen(id int, status, reason string) (*models.Task, error) {
+	stmt, err := r.stmt(reopenTaskQuery)
+	if err != nil {
+		return nil, err
+	}
+	task, err := scanTask(stmt.QueryRow(id, status, reason))
+	if err == nil {
+		return task, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	// Nothing was updated: tell "doesn't exist" apart from "exists but isn't completed"
+	if _, err := r.GetByID(id); err != nil {
+		return nil, err
+	}
+	return nil, ErrTaskNotCompleted
+}
+
+// ReclaimExpiredLeases returns in-progress tasks whose lease has expired to pending
+// and reports the IDs it reclaimed
+func (r *taskRepository) ReclaimExpiredLeases() ([]int, error) {
+	stmt, err := r.stmt(reclaimExpiredLeasesQuery)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := stmt.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Reassign moves every live task from one assignee to another
+func (r *taskRepository) Reassign(from, to string) ([]*models.Task, error) {
+	stmt, err := r.stmt(reassignTasksQuery)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := stmt.Query(from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []*models.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+// EscalatePriorities raises overdue tasks one priority level in a single statement
+func (r *taskRepository) EscalatePriorities(statuses []string, dueBefore models.Date) ([]*models.Task, error) {
+	stmt, err := r.stmt(escalatePrioritiesQuery)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := stmt.Query(pq.Array(statuses), dueBefore.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []*models.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildListWhere_Empty(t *testing.T) {
+	where, args := buildListWhere(models.ListFilter{})
+
+	// Soft-deleted tasks are always excluded
+	assert.Equal(t, " WHERE deleted_at IS NULL", where)
+	assert.Empty(t, args)
+}
+
+func TestBuildListWhere_IncludeDeleted(t *testing.T) {
+	where, args := buildListWhere(models.ListFilter{IncludeDeleted: true})
+	assert.Equal(t, "", where)
+	assert.Empty(t, args)
+
+	where, _ = buildListWhere(models.ListFilter{IncludeDeleted: true, Assignee: "alice"})
+	assert.Equal(t, " WHERE assignee = $1", where)
+}
+
+func TestBuildListWhere_CreatedRange(t *testing.T) {
+	after := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	before := after.Add(24 * time.Hour)
+	filter := models.ListFilter{Assignee: "alice", CreatedAfter: &after, CreatedBefore: &before}
+
+	where, args := buildListWhere(filter)
+
+	assert.Equal(t, " WHERE deleted_at IS NULL AND assignee = $1 AND created_at > $2 AND created_at < $3", where)
+	assert.Equal(t, []interface{}{"alice", after, before}, args)
+}
+
+func TestBuildListWhere_UpdatedByActorInRange(t *testing.T) {
+	after := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := after.Add(24 * time.Hour)
+	filter := models.ListFilter{Statuses: []string{"completed"}, UpdatedBy: "alice", UpdatedAfter: &after, UpdatedBefore: &before}
+
+	where, args := buildListWhere(filter)
+
+	// The dates bound alice's audited changes, not the task's own updated_at
+	assert.Equal(t, " WHERE deleted_at IS NULL AND status = ANY($1) AND id IN (SELECT task_id FROM {audit} WHERE actor = $2 AND created_at > $3 AND created_at < $4)", where)
+	assert.Equal(t, []interface{}{pq.Array([]string{"completed"}), "alice", after, before}, args)
+	assert.Contains(t, expandTable(where, "todo_tasks"), "FROM todo_tasks_audit WHERE", "each deployment reads its own audit log")
+}
+
+func TestBuildListWhere_UpdatedRange(t *testing.T) {
+	after := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	where, args := buildListWhere(models.ListFilter{UpdatedAfter: &after})
+
+	assert.Equal(t, " WHERE deleted_at IS NULL AND updated_at > $1", where)
+	assert.Equal(t, []interface{}{after}, args)
+}
+
+func TestBuildListQuery_Limit(t *testing.T) {
+	query, args := buildListQuery("SELECT id FROM tasks", models.ListFilter{Assignee: "alice", Limit: 11})
+
+	assert.Equal(t, "SELECT id FROM tasks WHERE deleted_at IS NULL AND assignee = $1"+listOrderNewestFirst+" LIMIT $2", query)
+	assert.Equal(t, []interface{}{"alice", 11}, args)
+
+	query, args = buildListQuery("SELECT id FROM tasks", models.ListFilter{})
+	assert.Equal(t, "SELECT id FROM tasks WHERE deleted_at IS NULL"+listOrderNewestFirst, query, "no limit by default")
+	assert.Empty(t, args)
+}
+
+func TestBuildListQuery_Order(t *testing.T) {
+	query, _ := buildListQuery("SELECT id FROM tasks", models.ListFilter{Order: models.SortOldestFirst})
+	assert.Equal(t, "SELECT id FROM tasks WHERE deleted_at IS NULL ORDER BY created_at ASC, id ASC", query)
+
+	query, _ = buildListQuery("SELECT id FROM tasks", models.ListFilter{Order: models.SortNewestFirst})
+	assert.Equal(t, "SELECT id FROM tasks WHERE deleted_at IS NULL ORDER BY created_at DESC, id DESC", query)
+}
+
+func TestListOrder_NullableFields(t *testing.T) {
+	cases := map[string]struct {
+		filter models.ListFilter
+		want   string
+	}{
+		"due date ascending":       {models.ListFilter{SortBy: models.SortByDueDate, Order: models.SortOldestFirst}, " ORDER BY due_date ASC NULLS LAST, id ASC"},
+		"due date descending":      {models.ListFilter{SortBy: models.SortByDueDate, Order: models.SortNewestFirst}, " ORDER BY due_date DESC NULLS LAST, id DESC"},
+		"completed_at nulls first": {models.ListFilter{SortBy: models.SortByCompletedAt, Order: models.SortNewestFirst, NullsFirst: true}, " ORDER BY completed_at DESC NULLS FIRST, id DESC"},
+		"created_at has no nulls":  {models.ListFilter{SortBy: models.SortByCreatedAt, Order: models.SortOldestFirst, NullsFirst: true}, listOrderOldestFirst},
+		"unknown field is ignored": {models.ListFilter{SortBy: "title; DROP TABLE tasks", Order: models.SortOldestFirst}, listOrderOldestFirst},
+	}
+	for name, tc := range cases {
+		assert.Equal(t, tc.want, listOrder(tc.filter), name)
+	}
+}
+
+func TestBuildListWhere_MetadataIsParameterised(t *testing.T) {
+	filter := models.ListFilter{Metadata: map[string]string{"team": "backend", "area": "api"}}
+
+	where, args := buildListWhere(filter)
+
+	// Keys are sorted so the query text is stable
+	assert.Equal(t, " WHERE deleted_at IS NULL AND metadata ? $1 AND metadata->>$1 = $2 AND metadata ? $3 AND metadata->>$3 = $4", where)
+	assert.Equal(t, []interface{}{"area", "api", "team", "backend"}, args)
+}
+
+func TestBuildListWhere_MetadataKeyExists(t *testing.T) {
+	filter := models.ListFilter{
+		Metadata:     map[string]string{"team": "backend"},
+		MetadataKeys: []string{"sprint"},
+	}
+
+	where, args := buildListWhere(filter)
+
+	assert.Equal(t, " WHERE deleted_at IS NULL AND metadata ? $1 AND metadata->>$1 = $2 AND metadata ? $3", where)
+	assert.Equal(t, []interface{}{"team", "backend", "sprint"}, args)
+}
+
+func TestBuildListWhere_Statuses(t *testing.T) {
+	filter := models.ListFilter{
+		Statuses:     []string{"pending", "in_progress"},
+		MetadataKeys: []string{"sprint"},
+	}
+
+	where, args := buildListWhere(filter)
+
+	assert.Equal(t, " WHERE deleted_at IS NULL AND status = ANY($1) AND metadata ? $2", where)
+	assert.Equal(t, []interface{}{pq.Array([]string{"pending", "in_progress"}), "sprint"}, args)
+}
+
+func TestBuildListWhere_Assignee(t *testing.T) {
+	filter := models.ListFilter{Statuses: []string{"pending"}, Assignee: "alice"}
+
+	where, args := buildListWhere(filter)
+
+	assert.Equal(t, " WHERE deleted_at IS NULL AND status = ANY($1) AND assignee = $2", where)
+	assert.Equal(t, []interface{}{pq.Array([]string{"pending"}), "alice"}, args)
+}
+
+func TestBuildListWhere_Unassigned(t *testing.T) {
+	// Unassigned wins over a specific assignee and needs no parameter
+	filter := models.ListFilter{Unassigned: true, Assignee: "alice", MetadataKeys: []string{"sprint"}}
+
+	where, args := buildListWhere(filter)
+
+	assert.Equal(t, " WHERE deleted_at IS NULL AND (assignee IS NULL OR assignee = '') AND metadata ? $1", where)
+	assert.Equal(t, []interface{}{"sprint"}, args)
+}
+
+func TestBuildListWhere_HasDueDate(t *testing.T) {
+	where, args := buildListWhere(models.ListFilter{HasDueDate: true})
+
+	assert.Equal(t, " WHERE deleted_at IS NULL AND due_date IS NOT NULL", where)
+	assert.Empty(t, args)
+}
+
+func TestBuildListWhere_Open(t *testing.T) {
+	where, args := buildListWhere(models.ListFilter{Statuses: []string{"pending"}, HasDueDate: true, Open: true})
+
+	assert.Equal(t, " WHERE deleted_at IS NULL AND status = ANY($1) AND due_date IS NOT NULL AND status <> 'completed'", where)
+	assert.Equal(t, []interface{}{pq.Array([]string{"pending"})}, args)
+}
+
+func TestNewUUID(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		uuid, err := newUUID()
+		assert.NoError(t, err)
+		assert.Regexp(t, pattern, uuid)
+		assert.False(t, seen[uuid])
+		seen[uuid] = true
+	}
+}
+
+func TestTranslateWriteError(t *testing.T) {
+	dup := &pq.Error{Code: "23505", Constraint: "idx_tasks_title_unique"}
+	assert.Equal(t, ErrDuplicateTask, translateWriteError(dup, DefaultTableName))
+	assert.Equal(t, ErrDuplicateTask, translateWriteError(fmt.Errorf("wrapped: %w", dup), DefaultTableName))
+	long := &pq.Error{Code: "23514", Constraint: "tasks_description_length"}
+	assert.Equal(t, ErrDescriptionTooLong, translateWriteError(long, DefaultTableName))
+
+	// Other unique violations and other errors pass through untouched
+	otherIndex := &pq.Error{Code: "23505", Constraint: "tasks_pkey"}
+	assert.Equal(t, error(otherIndex), translateWriteError(otherIndex, DefaultTableName))
+	otherCheck := &pq.Error{Code: "23514", Constraint: "tasks_status_check"}
+	assert.Equal(t, error(otherCheck), translateWriteError(otherCheck, DefaultTableName))
+	plain := errors.New("boom")
+	assert.Equal(t, plain, translateWriteError(plain, DefaultTableName))
+}
+
+// fakeRow scans fixed values into a row selected with taskColumns
+type fakeRow struct {
+	createdAt, updatedAt time.Time
+}
+
+func (r fakeRow) Scan(dest ...interface{}) error {
+	*dest[0].(*int) = 7
+	*dest[1].(*string) = "Crafted"
+	*dest[3].(*string) = "pending"
+	*dest[5].(*time.Time) = r.createdAt
+	*dest[6].(*time.Time) = r.updatedAt
+	return nil
+}
+
+func TestScanTask_ClampsUpdatedAtBeforeCreatedAt(t *testing.T) {
+	// Arrange: a row whose updated_at is an hour before its created_at
+	created := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	before := timestampViolations.Value()
+
+	// Act
+	task, err := scanTask(fakeRow{createdAt: created, updatedAt: created.Add(-time.Hour)})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, created, task.UpdatedAt)
+	assert.Equal(t, created, task.CreatedAt)
+	assert.Equal(t, before+1, timestampViolations.Value())
+}
+
+func TestScanTask_KeepsOrderedTimestamps(t *testing.T) {
+	// Arrange
+	created := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	before := timestampViolations.Value()
+
+	// Act
+	task, err := scanTask(fakeRow{createdAt: created, updatedAt: created.Add(time.Minute)})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, created.Add(time.Minute), task.UpdatedAt)
+	assert.Equal(t, before, timestampViolations.Value())
+}
+
+func TestUpdateQueries_NeverStampBeforeCreatedAt(t *testing.T) {
+	queries := []string{updateTaskQuery, deleteTaskQuery, reviveTaskQuery, claimTaskQuery, reclaimExpiredLeasesQuery,
+		reassignTasksQuery, reopenTaskQuery, releaseTaskQuery}
+	for _, query := range queries {
+		assert.Contains(t, query, "updated_at = GREATEST(NOW(), created_at)")
+		assert.NotContains(t, query, "updated_at = NOW()")
+	}
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+)
+
+// ErrTemplateNotFound is returned when no task template has the requested ID
+var ErrTemplateNotFound = errors.New("template not found")
+
+// templateColumns is the column list read by every template query; it must match scanTemplate
+const templateColumns = `id, name, title, description, metadata, assignee, due_in_days, priority, created_at`
+
+const (
+	createTemplateQuery = `
+        INSERT INTO {templates} (name, title, description, metadata, assignee, due_in_days, priority)
+        VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, NULLIF($7, ''))
+        RETURNING id, created_at
+    `
+	getTemplateQuery    = `SELECT ` + templateColumns + ` FROM {templates} WHERE id = $1`
+	listTemplatesQuery  = `SELECT ` + templateColumns + ` FROM {templates} ORDER BY name, id`
+	deleteTemplateQuery = `DELETE FROM {templates} WHERE id = $1`
+)
+
+// CreateTemplate inserts a task template, filling in its ID and CreatedAt
+func (r *taskRepository) CreateTemplate(tmpl *models.TaskTemplate) error {
+	metadata, err := encodeMetadata(tmpl.Metadata)
+	if err != nil {
+		return err
+	}
+	stmt, err := r.stmt(createTemplateQuery)
+	if err != nil {
+		return err
+	}
+	var dueInDays sql.NullInt64
+	if tmpl.DueInDays != nil {
+		dueInDays = sql.NullInt64{Int64: int64(*tmpl.DueInDays), Valid: true}
+	}
+	if err := stmt.QueryRow(tmpl.Name, tmpl.Title, tmpl.Description, metadata, tmpl.Assignee, dueInDays, tmpl.Priority).
+		Scan(&tmpl.ID, &tmpl.CreatedAt); err != nil {
+		return err
+	}
+	tmpl.CreatedAt = tmpl.CreatedAt.UTC()
+	return nil
+}
+
+// GetTemplate returns the task template with the given ID, or ErrTemplateNotFound
+func (r *taskRepository) GetTemplate(id int) (*models.TaskTemplate, error) {
+	stmt, err := r.stmt(getTemplateQuery)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := scanTemplate(stmt.QueryRow(id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTemplateNotFound
+	}
+	return tmpl, err
+}
+
+// ListTemplates returns every task template, sorted by name
+func (r *taskRepository) ListTemplates() ([]*models.TaskTemplate, error) {
+	stmt, err := r.stmt(listTemplatesQuery)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := stmt.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []*models.TaskTemplate{}
+	for rows.Next() {
+		tmpl, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, tmpl)
+	}
+	return templates, rows.Err()
+}
+
+// DeleteTemplate removes a task template. Tasks created from it are unaffected.
+func (r *taskRepository) DeleteTemplate(id int) error {
+	stmt, err := r.stmt(deleteTemplateQuery)
+	if err != nil {
+		return err
+	}
+	result, err := stmt.Exec(id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+// scanTemplate reads one row of templateColumns
+func scanTemplate(row rowScanner) (*models.TaskTemplate, error) {
+	tmpl := &models.TaskTemplate{}
+	var description, assignee, priority sql.NullString
+	var dueInDays sql.NullInt64
+	var metadata []byte
+	if err := row.Scan(&tmpl.ID, &tmpl.Name, &tmpl.Title, &description, &metadata, &assignee, &dueInDays, &priority, &tmpl.CreatedAt); err != nil {
+		return nil, err
+	}
+	tmpl.Description = description.String
+	tmpl.Assignee = assignee.String
+	tmpl.Priority = priority.String
+	if dueInDays.Valid {
+		days := int(dueInDays.Int64)
+		tmpl.DueInDays = &days
+	}
+	tmpl.CreatedAt = tmpl.CreatedAt.UTC()
+	tmpl.Metadata = map[string]interface{}{}
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &tmpl.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to decode metadata for template %d: %w", tmpl.ID, err)
+		}
+	}
+	return tmpl, nil
+}
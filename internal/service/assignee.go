@@ -0,0 +1,64 @@
+package service
+
+import (
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+)
+
+// AssigneeFormat selects what an assignee must look like
+type AssigneeFormat string
+
+const (
+	// AssigneeFormatUsername accepts any name (the default)
+	AssigneeFormatUsername AssigneeFormat = "username"
+	// AssigneeFormatEmail requires a bare email address and stores it lowercased
+	AssigneeFormatEmail AssigneeFormat = "email"
+	// AssigneeFormatNumeric requires a positive integer user ID
+	AssigneeFormatNumeric AssigneeFormat = "numeric"
+)
+
+// ParseAssigneeFormat validates an AssigneeFormat name (case-insensitive)
+func ParseAssigneeFormat(s string) (AssigneeFormat, error) {
+	switch f := AssigneeFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case AssigneeFormatUsername, AssigneeFormatEmail, AssigneeFormatNumeric:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown assignee format %q (want username, email or numeric)", s)
+}
+
+// WithAssigneeFormat sets the format assignees are validated against on create, update, patch
+// and assign. Tasks already stored are not re-checked.
+func WithAssigneeFormat(f AssigneeFormat) Option {
+	return func(s *taskService) {
+		s.assigneeFormat = f
+	}
+}
+
+// normalizeAssignee trims raw, checks it against the configured format and returns the value to
+// store. An empty result means unassigned and is valid in every format.
+func (s *taskService) normalizeAssignee(raw string) (string, error) {
+	assignee := strings.TrimSpace(raw)
+	if assignee == "" {
+		return "", nil
+	}
+	if err := validateAssignee(assignee); err != nil {
+		return "", err
+	}
+
+	switch s.assigneeFormat {
+	case AssigneeFormatEmail:
+		// ParseAddress also accepts "Bob <bob@example.com>"; only the bare address is allowed
+		addr, err := mail.ParseAddress(assignee)
+		if err != nil || addr.Name != "" || addr.Address != assignee {
+			return "", fmt.Errorf("%w: %q is not an email address", ErrInvalidAssignee, assignee)
+		}
+		return strings.ToLower(assignee), nil
+	case AssigneeFormatNumeric:
+		if id, err := strconv.ParseUint(assignee, 10, 63); err != nil || id == 0 || strconv.FormatUint(id, 10) != assignee {
+			return "", fmt.Errorf("%w: %q is not a numeric user ID", ErrInvalidAssignee, assignee)
+		}
+	}
+	return assignee, nil
+}
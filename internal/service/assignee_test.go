@@ -0,0 +1,80 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestParseAssigneeFormat(t *testing.T) {
+	f, err := ParseAssigneeFormat(" Email ")
+	assert.NoError(t, err)
+	assert.Equal(t, AssigneeFormatEmail, f)
+
+	_, err = ParseAssigneeFormat("uuid")
+	assert.Error(t, err)
+}
+
+func TestNormalizeAssignee(t *testing.T) {
+	cases := map[AssigneeFormat]struct {
+		valid   map[string]string // input -> stored value
+		invalid []string
+	}{
+		AssigneeFormatUsername: {
+			valid:   map[string]string{"bob": "bob", " Bob Smith ": "Bob Smith", "bob@example.com": "bob@example.com", "": "", "  ": ""},
+			invalid: []string{"none", "NONE"},
+		},
+		AssigneeFormatEmail: {
+			valid:   map[string]string{"Bob@Example.COM": "bob@example.com", " a.b+tag@sub.example.org ": "a.b+tag@sub.example.org", "": ""},
+			invalid: []string{"bob", "bob@", "@example.com", "Bob <bob@example.com>", "bob@example.com, amy@example.com", "none"},
+		},
+		AssigneeFormatNumeric: {
+			valid:   map[string]string{"42": "42", " 7 ": "7", "": ""},
+			invalid: []string{"0", "007", "-3", "+3", "4.2", "bob", "99999999999999999999"},
+		},
+	}
+	for format, tc := range cases {
+		t.Run(string(format), func(t *testing.T) {
+			s := NewTaskService(new(MockTaskRepository), WithAssigneeFormat(format)).(*taskService)
+			for input, want := range tc.valid {
+				got, err := s.normalizeAssignee(input)
+				assert.NoError(t, err, input)
+				assert.Equal(t, want, got, input)
+			}
+			for _, input := range tc.invalid {
+				_, err := s.normalizeAssignee(input)
+				assert.ErrorIs(t, err, ErrInvalidAssignee, input)
+			}
+		})
+	}
+}
+
+func TestCreateTask_EmailAssigneeLowercased(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, WithAssigneeFormat(AssigneeFormatEmail))
+	mockRepo.On("Create", mock.MatchedBy(func(task *models.Task) bool { return task.Assignee == "amy@example.com" })).Return(nil)
+
+	// Act
+	_, err := service.CreateTask(&models.CreateTaskRequest{Title: "T", Assignee: "Amy@Example.com"})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetAllTasks_EmailAssigneeFilterLowercased(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, WithAssigneeFormat(AssigneeFormatEmail))
+	mockRepo.On("GetAll", models.ListFilter{Assignee: "amy@example.com"}).Return([]*models.Task{}, nil)
+
+	// Act
+	_, err := service.GetAllTasks(models.ListFilter{Assignee: "Amy@Example.com"})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
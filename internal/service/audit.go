@@ -0,0 +1,76 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+)
+
+// ErrInvalidActor is returned when the actor a change is recorded against (the X-Actor header)
+// is longer than MaxActorLength
+var ErrInvalidActor = errors.New("invalid actor")
+
+// checkActor trims the actor recorded with a change and checks its length
+func checkActor(actor string) (string, error) {
+	actor = strings.TrimSpace(actor)
+	if len(actor) > MaxActorLength {
+		return "", fmt.Errorf("%w: actor must not exceed %d characters", ErrInvalidActor, MaxActorLength)
+	}
+	return actor, nil
+}
+
+// createEntry is the audit entry for a task actor created
+func createEntry(actor string, task *models.Task) *models.AuditEntry {
+	return &models.AuditEntry{TaskID: task.ID, Actor: actor, Action: models.AuditActionCreate, ToStatus: task.Status}
+}
+
+// deleteEntry is the audit entry for a task actor deleted
+func deleteEntry(actor string, id int) *models.AuditEntry {
+	return &models.AuditEntry{TaskID: id, Actor: actor, Action: models.AuditActionDelete}
+}
+
+// changeEntry is the audit entry for a change actor made to a task. A status change is recorded
+// in FromStatus and ToStatus; the other changed fields are listed in the note.
+func changeEntry(action, actor string, before, after *models.Task) *models.AuditEntry {
+	entry := &models.AuditEntry{TaskID: after.ID, Actor: actor, Action: action, Note: changeNote(before, after)}
+	if before.Status != after.Status {
+		entry.FromStatus, entry.ToStatus = before.Status, after.Status
+	}
+	return entry
+}
+
+// changeNote lists the fields other than status that differ between before and after, sorted.
+// Assignee, due date and priority are short, so their old and new values are shown as snooze does;
+// title, description and metadata are only named, since they can be long.
+func changeNote(before, after *models.Task) string {
+	changes := diffTasks(before, after)
+	fields := make([]string, 0, len(changes))
+	for field := range changes {
+		if field != "status" {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		switch field {
+		case "assignee", "due_date", "priority":
+			parts[i] = fmt.Sprintf("%s %s -> %s", field, auditValue(changes[field].Old), auditValue(changes[field].New))
+		default:
+			parts[i] = field
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// auditValue renders an assignee or due date for a note, with "none" for an unset one
+func auditValue(v interface{}) string {
+	if v == nil || v == "" {
+		return "none"
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+)
+
+// EventPublisher receives an event after each task create, update and delete has been saved.
+// Publish is called on the request path, so implementations must not block.
+type EventPublisher interface {
+	Publish(event models.TaskEvent)
+}
+
+// EventMode selects how a bulk operation's events are published
+type EventMode string
+
+const (
+	// EventsPerItem publishes one event per task, as single-task operations do
+	EventsPerItem EventMode = "item"
+	// EventsBatched publishes one models.EventTasksBatch event per bulk operation, holding
+	// the per-task events in order
+	EventsBatched EventMode = "batch"
+)
+
+// ParseEventMode validates an EventMode name (case-insensitive)
+func ParseEventMode(s string) (EventMode, error) {
+	switch m := EventMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case EventsPerItem, EventsBatched:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown event mode %q (want item or batch)", s)
+}
+
+// WithEventPublisher sends task events to p
+func WithEventPublisher(p EventPublisher) Option {
+	return func(s *taskService) {
+		s.events = p
+	}
+}
+
+// WithEventMode sets how bulk operations publish their events; the default is EventsPerItem
+func WithEventMode(m EventMode) Option {
+	return func(s *taskService) {
+		s.eventMode = m
+	}
+}
+
+// publish sends an event stamped with the current time, if a publisher is configured
+func (s *taskService) publish(event models.TaskEvent) {
+	if s.events == nil {
+		return
+	}
+	event.OccurredAt = s.now().UTC()
+	s.events.Publish(event)
+}
+
+// publishUpdate sends an updated event listing what changed between before and after
+func (s *taskService) publishUpdate(before, after *models.Task) {
+	if s.events == nil {
+		return
+	}
+	s.publish(models.TaskEvent{Type: models.EventTaskUpdated, TaskID: after.ID, Task: after, Changes: diffTasks(before, after)})
+}
+
+// publishUpdates sends the updated events of a bulk operation, one per task or, in batch mode,
+// all together in one event. befores and afters are paired by index.
+func (s *taskService) publishUpdates(befores, afters []*models.Task) {
+	if s.events == nil || len(afters) == 0 {
+		return
+	}
+	if s.eventMode != EventsBatched {
+		for i := range afters {
+			s.publishUpdate(befores[i], afters[i])
+		}
+		return
+	}
+	occurredAt := s.now().UTC()
+	batch := make([]models.TaskEvent, len(afters))
+	for i, after := range afters {
+		batch[i] = models.TaskEvent{Type: models.EventTaskUpdated, TaskID: after.ID, Task: after,
+			Changes: diffTasks(befores[i], after), OccurredAt: occurredAt}
+	}
+	// Stamped here rather than by publish so the batch and its items share one time
+	s.events.Publish(models.TaskEvent{Type: models.EventTasksBatch, Events: batch, OccurredAt: occurredAt})
+}
+
+// snapshot copies a task before an update is applied to it. Updates replace field values
+// (including the metadata map and due date pointer) rather than mutating them, so a shallow
+// copy is enough.
+func snapshot(task *models.Task) *models.Task {
+	copied := *task
+	return &copied
+}
+
+// diffTasks lists the client-editable fields that differ between before and after, keyed by
+// their JSON names. Server-managed fields such as updated_at are not reported.
+func diffTasks(before, after *models.Task) map[string]models.FieldChange {
+	changes := map[string]models.FieldChange{}
+	add := func(field string, old, new interface{}) {
+		if !reflect.DeepEqual(old, new) {
+			changes[field] = models.FieldChange{Old: old, New: new}
+		}
+	}
+
+	add("title", before.Title, after.Title)
+	add("description", before.Description, after.Description)
+	add("status", before.Status, after.Status)
+	add("priority", before.Priority, after.Priority)
+	add("metadata", metadataValue(before.Metadata), metadataValue(after.Metadata))
+	add("assignee", before.Assignee, after.Assignee)
+	add("due_date", dueDateValue(before.DueDate), dueDateValue(after.DueDate))
+	return changes
+}
+
+// metadataValue treats nil metadata as empty, matching how it is stored
+func metadataValue(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return map[string]interface{}{}
+	}
+	return m
+}
+
+// dueDateValue is a due date as it appears in JSON, so an unset date diffs as null
+func dueDateValue(d *models.Date) interface{} {
+	if d == nil {
+		return nil
+	}
+	return d.Format(models.DateLayout)
+}
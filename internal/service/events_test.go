@@ -0,0 +1,171 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPublisher keeps every event it is given
+type recordingPublisher struct {
+	events []models.TaskEvent
+}
+
+func (p *recordingPublisher) Publish(event models.TaskEvent) {
+	p.events = append(p.events, event)
+}
+
+func TestDiffTasks(t *testing.T) {
+	due := models.NewDate(time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC))
+	before := &models.Task{ID: 1, Title: "Write docs", Description: "draft", Status: "pending",
+		Metadata: map[string]interface{}{"team": "core"}, DueDate: &due}
+	after := &models.Task{ID: 1, Title: "Write docs", Description: "", Status: "in_progress",
+		Metadata: map[string]interface{}{"team": "core"}, Assignee: "alice", UpdatedAt: time.Now()}
+
+	changes := diffTasks(before, after)
+
+	assert.Equal(t, map[string]models.FieldChange{
+		"description": {Old: "draft", New: ""},
+		"status":      {Old: "pending", New: "in_progress"},
+		"assignee":    {Old: "", New: "alice"},
+		"due_date":    {Old: "2024-06-01", New: nil},
+	}, changes, "unchanged and server-managed fields are left out")
+}
+
+func TestDiffTasks_NilMetadataEqualsEmpty(t *testing.T) {
+	changes := diffTasks(&models.Task{Metadata: nil}, &models.Task{Metadata: map[string]interface{}{}})
+
+	assert.Empty(t, changes)
+}
+
+// --- Test Cases for task events ---
+func TestCreateTask_PublishesFullTask(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	events := &recordingPublisher{}
+	service := NewTaskService(mockRepo, WithEventPublisher(events))
+	mockRepo.On("Create", mock.AnythingOfType("*models.Task")).Return(nil)
+
+	// Act
+	task, err := service.CreateTask(&models.CreateTaskRequest{Title: "New"})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, events.events, 1)
+	assert.Equal(t, models.EventTaskCreated, events.events[0].Type)
+	assert.Same(t, task, events.events[0].Task)
+	assert.Nil(t, events.events[0].Changes)
+	assert.False(t, events.events[0].OccurredAt.IsZero())
+}
+
+func TestPatchTask_PublishesChanges(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	events := &recordingPublisher{}
+	service := NewTaskService(mockRepo, WithEventPublisher(events))
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Status: "pending",
+		Metadata: map[string]interface{}{"team": "core", "sprint": float64(12)}}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task")).Return(nil)
+
+	status := "completed"
+	patch := &models.PatchTaskRequest{Status: &status, Metadata: map[string]interface{}{"team": nil}}
+
+	// Act
+	_, err := service.PatchTask(1, patch)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, events.events, 1)
+	event := events.events[0]
+	assert.Equal(t, models.EventTaskUpdated, event.Type)
+	assert.Equal(t, 1, event.TaskID)
+	assert.Equal(t, map[string]models.FieldChange{
+		"status": {Old: "pending", New: "completed"},
+		"metadata": {
+			Old: map[string]interface{}{"team": "core", "sprint": float64(12)},
+			New: map[string]interface{}{"sprint": float64(12)},
+		},
+	}, event.Changes)
+}
+
+func TestUpdateTask_PublishesChanges(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	events := &recordingPublisher{}
+	service := NewTaskService(mockRepo, WithEventPublisher(events))
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "Old", Status: "pending"}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task")).Return(nil)
+
+	// Act
+	_, err := service.UpdateTask(1, &models.UpdateTaskRequest{Title: "New", Status: "pending"})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, events.events, 1)
+	assert.Equal(t, map[string]models.FieldChange{"title": {Old: "Old", New: "New"}}, events.events[0].Changes)
+}
+
+func TestUpdateTask_FailureDoesNotPublish(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	events := &recordingPublisher{}
+	service := NewTaskService(mockRepo, WithEventPublisher(events))
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "Old", Status: "pending"}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task")).Return(errors.New("db down"))
+
+	// Act
+	_, err := service.UpdateTask(1, &models.UpdateTaskRequest{Title: "New"})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Empty(t, events.events)
+}
+
+func TestDeleteTask_PublishesID(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	events := &recordingPublisher{}
+	service := NewTaskService(mockRepo, WithEventPublisher(events))
+	mockRepo.On("Delete", 3).Return(nil)
+
+	// Act
+	err := service.DeleteTask(3, &models.DeleteTaskRequest{})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, events.events, 1)
+	assert.Equal(t, models.TaskEvent{Type: models.EventTaskDeleted, TaskID: 3, OccurredAt: events.events[0].OccurredAt}, events.events[0])
+}
+
+func TestAssignTask_PublishesAssigneeChange(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	events := &recordingPublisher{}
+	service := NewTaskService(mockRepo, WithEventPublisher(events))
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Assignee: "alice"}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task")).Return(nil)
+
+	// Act
+	_, err := service.AssignTask(1, &models.AssignTaskRequest{})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, events.events, 1)
+	assert.Equal(t, map[string]models.FieldChange{"assignee": {Old: "alice", New: ""}}, events.events[0].Changes)
+}
+
+func TestParseEventMode(t *testing.T) {
+	for in, want := range map[string]EventMode{"item": EventsPerItem, " Batch ": EventsBatched} {
+		got, err := ParseEventMode(in)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	_, err := ParseEventMode("window")
+	assert.Error(t, err)
+}
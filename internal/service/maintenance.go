@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/cliffdoyle/task-api/internal/repository"
+)
+
+// ErrMaintenanceDisabled is returned when a maintenance operation that isn't enabled is asked for
+var ErrMaintenanceDisabled = errors.New("maintenance operation is disabled")
+
+// ErrInvalidMaintenance is returned for a maintenance request naming an unknown or repeated operation
+var ErrInvalidMaintenance = errors.New("invalid maintenance request")
+
+// DefaultMaintenanceTimeout bounds a maintenance run when Maintenance.Timeout is unset
+const DefaultMaintenanceTimeout = 10 * time.Minute
+
+// Maintenance sets which database maintenance operations RunMaintenance may run, and how long a
+// run may take. Every operation is off by default.
+type Maintenance struct {
+	Analyze bool
+	Reindex bool
+	// Timeout bounds a whole run, separately from any limit on normal requests, since
+	// REINDEX on a large table can take minutes
+	Timeout time.Duration
+}
+
+// enabled returns the operations turned on, in the order they run by default
+func (m Maintenance) enabled() []repository.MaintenanceOp {
+	var ops []repository.MaintenanceOp
+	if m.Analyze {
+		ops = append(ops, repository.MaintenanceAnalyze)
+	}
+	if m.Reindex {
+		ops = append(ops, repository.MaintenanceReindex)
+	}
+	return ops
+}
+
+// WithMaintenance sets which maintenance operations may run (see Maintenance)
+func WithMaintenance(m Maintenance) Option {
+	return func(s *taskService) {
+		s.maintenance = m
+	}
+}
+
+// RunMaintenance runs the requested maintenance operations on the tasks table in order, or every
+// enabled one when none are named, and reports how long each took. The whole run shares one
+// timeout; an operation still going when it, or ctx, ends is cancelled and later ones don't run.
+func (s *taskService) RunMaintenance(ctx context.Context, req *models.MaintenanceRequest) (*models.MaintenanceReport, error) {
+	enabled := s.maintenance.enabled()
+	if len(enabled) == 0 {
+		return nil, fmt.Errorf("%w: no maintenance operations are enabled", ErrMaintenanceDisabled)
+	}
+	ops := enabled
+	if len(req.Operations) > 0 {
+		ops = nil
+		seen := map[repository.MaintenanceOp]bool{}
+		for _, name := range req.Operations {
+			op := repository.MaintenanceOp(name)
+			if op != repository.MaintenanceAnalyze && op != repository.MaintenanceReindex {
+				return nil, fmt.Errorf("%w: unknown operation %q (want %s or %s)", ErrInvalidMaintenance, name,
+					repository.MaintenanceAnalyze, repository.MaintenanceReindex)
+			}
+			if seen[op] {
+				return nil, fmt.Errorf("%w: operation %q is listed twice", ErrInvalidMaintenance, name)
+			}
+			seen[op] = true
+			if !containsOp(enabled, op) {
+				return nil, fmt.Errorf("%w: %s", ErrMaintenanceDisabled, op)
+			}
+			ops = append(ops, op)
+		}
+	}
+
+	timeout := s.maintenance.Timeout
+	if timeout <= 0 {
+		timeout = DefaultMaintenanceTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	report := &models.MaintenanceReport{Results: make([]models.MaintenanceResult, 0, len(ops))}
+	runStart := s.now()
+	for _, op := range ops {
+		start := s.now()
+		if err := s.repo.RunMaintenance(ctx, op); err != nil {
+			return nil, fmt.Errorf("failed to run %s: %w", op, err)
+		}
+		report.Results = append(report.Results, models.MaintenanceResult{
+			Operation:  string(op),
+			DurationMS: s.now().Sub(start).Milliseconds(),
+		})
+	}
+	report.DurationMS = s.now().Sub(runStart).Milliseconds()
+	return report, nil
+}
+
+// containsOp reports whether ops includes op
+func containsOp(ops []repository.MaintenanceOp, op repository.MaintenanceOp) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/cliffdoyle/task-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// steppingClock returns a clock that moves forward by step on every reading
+func steppingClock(step time.Duration) func() time.Time {
+	t := time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		t = t.Add(step)
+		return t
+	}
+}
+
+func TestRunMaintenance(t *testing.T) {
+	tests := map[string]struct {
+		enabled Maintenance
+		req     models.MaintenanceRequest
+		wantOps []string
+	}{
+		"every enabled op by default": {Maintenance{Analyze: true, Reindex: true}, models.MaintenanceRequest{}, []string{"analyze", "reindex"}},
+		"only analyze enabled":        {Maintenance{Analyze: true}, models.MaintenanceRequest{}, []string{"analyze"}},
+		"in the order asked":          {Maintenance{Analyze: true, Reindex: true}, models.MaintenanceRequest{Operations: []string{"reindex", "analyze"}}, []string{"reindex", "analyze"}},
+		"a subset":                    {Maintenance{Analyze: true, Reindex: true}, models.MaintenanceRequest{Operations: []string{"analyze"}}, []string{"analyze"}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			repo := new(MockTaskRepository)
+			svc := NewTaskService(repo, WithMaintenance(tc.enabled)).(*taskService)
+			svc.now = steppingClock(time.Second)
+			for _, op := range tc.wantOps {
+				repo.On("RunMaintenance", mock.Anything, repository.MaintenanceOp(op)).Return(nil).Once()
+			}
+
+			// Act
+			report, err := svc.RunMaintenance(context.Background(), &tc.req)
+
+			// Assert
+			require.NoError(t, err)
+			var ops []string
+			for _, r := range report.Results {
+				ops = append(ops, r.Operation)
+				assert.Equal(t, int64(1000), r.DurationMS)
+			}
+			assert.Equal(t, tc.wantOps, ops)
+			assert.Equal(t, int64(1000*(2*len(tc.wantOps)+1)), report.DurationMS, "the total spans every reading in between")
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRunMaintenance_Rejected(t *testing.T) {
+	tests := map[string]struct {
+		enabled Maintenance
+		ops     []string
+		wantErr error
+	}{
+		"nothing enabled":     {Maintenance{}, nil, ErrMaintenanceDisabled},
+		"disabled operation":  {Maintenance{Analyze: true}, []string{"reindex"}, ErrMaintenanceDisabled},
+		"unknown operation":   {Maintenance{Analyze: true}, []string{"vacuum"}, ErrInvalidMaintenance},
+		"repeated operation":  {Maintenance{Analyze: true}, []string{"analyze", "analyze"}, ErrInvalidMaintenance},
+		"case must match":     {Maintenance{Analyze: true}, []string{"ANALYZE"}, ErrInvalidMaintenance},
+		"disabled after good": {Maintenance{Analyze: true}, []string{"analyze", "reindex"}, ErrMaintenanceDisabled},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo := new(MockTaskRepository)
+			svc := NewTaskService(repo, WithMaintenance(tc.enabled))
+
+			_, err := svc.RunMaintenance(context.Background(), &models.MaintenanceRequest{Operations: tc.ops})
+
+			assert.ErrorIs(t, err, tc.wantErr)
+			repo.AssertNotCalled(t, "RunMaintenance", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestRunMaintenance_Timeout(t *testing.T) {
+	// Arrange: the first operation outlasts the timeout, so the second must not start
+	repo := new(MockTaskRepository)
+	svc := NewTaskService(repo, WithMaintenance(Maintenance{Analyze: true, Reindex: true, Timeout: 10 * time.Millisecond}))
+	var deadline time.Time
+	repo.On("RunMaintenance", mock.Anything, repository.MaintenanceAnalyze).Run(func(args mock.Arguments) {
+		ctx := args.Get(0).(context.Context)
+		deadline, _ = ctx.Deadline()
+		<-ctx.Done()
+	}).Return(context.DeadlineExceeded)
+
+	// Act
+	start := time.Now()
+	_, err := svc.RunMaintenance(context.Background(), &models.MaintenanceRequest{})
+
+	// Assert
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.WithinDuration(t, start.Add(10*time.Millisecond), deadline, 5*time.Millisecond)
+	repo.AssertNotCalled(t, "RunMaintenance", mock.Anything, repository.MaintenanceReindex)
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+)
+
+// MaxMetricsDays caps how many days DailyMetrics covers, counting both ends
+const MaxMetricsDays = 366
+
+// ErrInvalidDateRange is returned by DailyMetrics when to is before from or the range is too long
+var ErrInvalidDateRange = errors.New("invalid date range")
+
+// DailyMetrics counts the tasks created and completed on each day from from to to, inclusive.
+// Every day in the range is returned, oldest first, with zeros where nothing happened.
+func (s *taskService) DailyMetrics(from, to models.Date) ([]*models.DailyMetrics, error) {
+	if to.Before(from.Time) {
+		return nil, fmt.Errorf("%w: to must not be before from", ErrInvalidDateRange)
+	}
+	end := to.AddDate(0, 0, 1) // exclusive
+	if end.After(from.AddDate(0, 0, MaxMetricsDays)) {
+		return nil, fmt.Errorf("%w: must not span more than %d days", ErrInvalidDateRange, MaxMetricsDays)
+	}
+
+	counted, err := s.repo.CountByDay(from.Time, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tasks by day: %w", err)
+	}
+	byDay := make(map[string]*models.DailyMetrics, len(counted))
+	for _, m := range counted {
+		byDay[m.Date.String()] = m
+	}
+
+	var metrics []*models.DailyMetrics
+	for day := from; day.Before(end); day = models.NewDate(day.AddDate(0, 0, 1)) {
+		m, ok := byDay[day.String()]
+		if !ok {
+			m = &models.DailyMetrics{Date: day}
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
+
+// Workload counts each assignee's live tasks by status, optionally only those with one of
+// statuses. Unassigned tasks are counted together in a final row with a nil Assignee.
+func (s *taskService) Workload(statuses []string) ([]*models.AssigneeWorkload, error) {
+	workload, err := s.repo.CountByAssignee(statuses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tasks by assignee: %w", err)
+	}
+	return workload, nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func mustDate(s string) models.Date {
+	d, err := models.ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// --- Test Cases for DailyMetrics ---
+func TestDailyMetrics_FillsEmptyDays(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	end := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("CountByDay", mustDate("2024-02-27").Time, end).Return([]*models.DailyMetrics{
+		{Date: mustDate("2024-02-28"), Created: 3},
+		{Date: mustDate("2024-02-29"), Created: 1, Completed: 2},
+	}, nil)
+
+	// Act: the range crosses a leap day and ends inclusive
+	metrics, err := service.DailyMetrics(mustDate("2024-02-27"), mustDate("2024-02-29"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.DailyMetrics{
+		{Date: mustDate("2024-02-27")},
+		{Date: mustDate("2024-02-28"), Created: 3},
+		{Date: mustDate("2024-02-29"), Created: 1, Completed: 2},
+	}, metrics)
+}
+
+func TestDailyMetrics_InvalidRange(t *testing.T) {
+	cases := map[string]struct {
+		from, to string
+	}{
+		"to before from": {"2024-05-02", "2024-05-01"},
+		"367 days":       {"2024-01-01", "2025-01-01"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			service := NewTaskService(mockRepo)
+
+			// Act
+			metrics, err := service.DailyMetrics(mustDate(tc.from), mustDate(tc.to))
+
+			// Assert
+			assert.Nil(t, metrics)
+			assert.ErrorIs(t, err, ErrInvalidDateRange)
+			mockRepo.AssertNotCalled(t, "CountByDay", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestDailyMetrics_MaxSpan(t *testing.T) {
+	// Arrange: 2024 is a leap year, so it is exactly MaxMetricsDays long
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	mockRepo.On("CountByDay", mock.Anything, mock.Anything).Return([]*models.DailyMetrics{}, nil)
+
+	// Act
+	metrics, err := service.DailyMetrics(mustDate("2024-01-01"), mustDate("2024-12-31"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, metrics, MaxMetricsDays)
+	assert.Equal(t, "2024-12-31", metrics[MaxMetricsDays-1].Date.String())
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/cliffdoyle/task-api/internal/repository"
+)
+
+// ErrInvalidPriority is returned when a request names a priority tasks can't have
+var ErrInvalidPriority = errors.New("invalid priority")
+
+// DefaultEscalationStatuses are the statuses whose overdue tasks EscalatePriorities raises
+// unless WithEscalation says otherwise: every status but completed
+var DefaultEscalationStatuses = []string{"pending", "in_progress"}
+
+// ParseEscalationStatuses validates the PRIORITY_ESCALATION_STATUSES setting, returning the
+// statuses in canonical form. An empty list returns DefaultEscalationStatuses. Completed tasks
+// are never escalated, so "completed" is refused.
+func ParseEscalationStatuses(statuses []string) ([]string, error) {
+	if len(statuses) == 0 {
+		return DefaultEscalationStatuses, nil
+	}
+	canonical := make([]string, len(statuses))
+	for i, status := range statuses {
+		c, ok := models.CanonicalStatus(status)
+		if !ok {
+			return nil, fmt.Errorf("unknown status %q (want %s)", status, strings.Join(DefaultEscalationStatuses, ", "))
+		}
+		if c == "completed" {
+			return nil, fmt.Errorf("completed tasks are never escalated (want %s)", strings.Join(DefaultEscalationStatuses, ", "))
+		}
+		canonical[i] = c
+	}
+	return canonical, nil
+}
+
+// WithEscalation sets which tasks EscalatePriorities raises: those in one of statuses whose due
+// date is more than afterDays days ago. Empty statuses keep the default and a negative afterDays
+// is ignored.
+func WithEscalation(statuses []string, afterDays int) Option {
+	return func(s *taskService) {
+		if len(statuses) > 0 {
+			s.escalationStatuses = statuses
+		}
+		if afterDays >= 0 {
+			s.escalationAfterDays = afterDays
+		}
+	}
+}
+
+// checkPriority returns priority in the canonical lowercase form, or ErrInvalidPriority
+func checkPriority(priority string) (string, error) {
+	canonical := strings.ToLower(strings.TrimSpace(priority))
+	if !models.IsValidPriority(canonical) {
+		return "", fmt.Errorf("%w: %q (want %s)", ErrInvalidPriority, priority, strings.Join(models.Priorities, ", "))
+	}
+	return canonical, nil
+}
+
+// EscalatePriorities raises the priority of overdue tasks by one level and returns how many it
+// raised. A task qualifies when its status is one of the escalation statuses, its due date is
+// more than the configured number of days ago, and its priority isn't already the highest.
+// Each change is written to the audit log in the same transaction and publishes an update event.
+// A task that stays overdue goes up another level on the next pass.
+func (s *taskService) EscalatePriorities() (int, error) {
+	dueBefore := models.NewDate(s.now().UTC().AddDate(0, 0, -s.escalationAfterDays))
+
+	var tasks []*models.Task
+	err := s.withTx(func(repo repository.TaskRepository) (err error) {
+		tasks, err = repo.EscalatePriorities(s.escalationStatuses, dueBefore)
+		if err != nil {
+			return fmt.Errorf("failed to escalate priorities in repository: %w", err)
+		}
+		for _, task := range tasks {
+			entry := &models.AuditEntry{
+				TaskID: task.ID,
+				Action: models.AuditActionEscalate,
+				Note:   fmt.Sprintf("priority %s -> %s", models.PreviousPriority(task.Priority), task.Priority),
+			}
+			if err := repo.AddAuditEntry(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	befores := make([]*models.Task, len(tasks))
+	for i, task := range tasks {
+		befores[i] = snapshot(task)
+		befores[i].Priority = models.PreviousPriority(task.Priority)
+	}
+	s.publishUpdates(befores, tasks)
+	return len(tasks), nil
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateTask_Priority(t *testing.T) {
+	tests := map[string]struct {
+		priority string
+		want     string
+	}{
+		"default":          {"", models.DefaultPriority},
+		"given":            {"low", "low"},
+		"case-insensitive": {" HIGH ", "high"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			svc := NewTaskService(mockRepo)
+			mockRepo.On("Create", mock.AnythingOfType("*models.Task")).Return(nil)
+
+			// Act
+			task, err := svc.CreateTask(&models.CreateTaskRequest{Title: "T", Priority: tc.priority})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, task.Priority)
+		})
+	}
+}
+
+func TestCreateTask_InvalidPriority(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	svc := NewTaskService(mockRepo)
+
+	// Act
+	_, err := svc.CreateTask(&models.CreateTaskRequest{Title: "T", Priority: "urgent"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidPriority)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestPatchTask_Priority(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	publisher := &recordingPublisher{}
+	svc := NewTaskService(mockRepo, WithEventPublisher(publisher))
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Status: "pending", Priority: "medium"}, nil)
+	mockRepo.On("Update", mock.MatchedBy(func(task *models.Task) bool { return task.Priority == "high" })).Return(nil)
+	mockRepo.On("AddAuditEntry", &models.AuditEntry{TaskID: 1, Action: models.AuditActionPatch, Note: "priority medium -> high"}).Return(nil)
+	high := "High"
+
+	// Act
+	task, err := svc.PatchTask(1, &models.PatchTaskRequest{Priority: &high})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "high", task.Priority)
+	mockRepo.AssertExpectations(t)
+	if assert.Len(t, publisher.events, 1) {
+		assert.Equal(t, models.FieldChange{Old: "medium", New: "high"}, publisher.events[0].Changes["priority"])
+	}
+}
+
+func TestUpdateTask_InvalidPriority(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	svc := NewTaskService(mockRepo)
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Status: "pending", Priority: "medium"}, nil)
+
+	// Act
+	_, err := svc.UpdateTask(1, &models.UpdateTaskRequest{Priority: "critical"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidPriority)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestEscalatePriorities(t *testing.T) {
+	// Arrange: it is 10 May, and tasks due before then are overdue
+	mockRepo := new(MockTaskRepository)
+	publisher := &recordingPublisher{}
+	svc := NewTaskService(mockRepo, WithEventPublisher(publisher)).(*taskService)
+	svc.now = func() time.Time { return time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC) }
+	escalated := []*models.Task{
+		{ID: 3, Title: "Overdue", Status: "pending", Priority: "medium", DueDate: dueDate("2024-05-01")},
+		{ID: 7, Title: "Very overdue", Status: "in_progress", Priority: "high", DueDate: dueDate("2024-04-01")},
+	}
+	mockRepo.On("EscalatePriorities", DefaultEscalationStatuses, mustDate("2024-05-10")).Return(escalated, nil)
+	mockRepo.On("AddAuditEntry", &models.AuditEntry{TaskID: 3, Action: models.AuditActionEscalate, Note: "priority low -> medium"}).Return(nil).Once()
+	mockRepo.On("AddAuditEntry", &models.AuditEntry{TaskID: 7, Action: models.AuditActionEscalate, Note: "priority medium -> high"}).Return(nil).Once()
+
+	// Act
+	count, err := svc.EscalatePriorities()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	mockRepo.AssertExpectations(t)
+	if assert.Len(t, publisher.events, 2) {
+		assert.Equal(t, 3, publisher.events[0].TaskID)
+		assert.Equal(t, map[string]models.FieldChange{"priority": {Old: "low", New: "medium"}}, publisher.events[0].Changes)
+		assert.Equal(t, map[string]models.FieldChange{"priority": {Old: "medium", New: "high"}}, publisher.events[1].Changes)
+	}
+}
+
+func TestEscalatePriorities_Configured(t *testing.T) {
+	// Arrange: only pending tasks more than 3 days past due
+	mockRepo := new(MockTaskRepository)
+	svc := NewTaskService(mockRepo, WithEscalation([]string{"pending"}, 3)).(*taskService)
+	svc.now = func() time.Time { return time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC) }
+	mockRepo.On("EscalatePriorities", []string{"pending"}, mustDate("2024-05-07")).Return([]*models.Task{}, nil)
+
+	// Act
+	count, err := svc.EscalatePriorities()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEscalatePriorities_AuditFailurePublishesNothing(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	publisher := &recordingPublisher{}
+	svc := NewTaskService(mockRepo, WithEventPublisher(publisher))
+	mockRepo.On("EscalatePriorities", mock.Anything, mock.Anything).Return([]*models.Task{{ID: 3, Priority: "medium"}}, nil)
+	mockRepo.On("AddAuditEntry", mock.Anything).Return(errors.New("audit write failed"))
+
+	// Act
+	count, err := svc.EscalatePriorities()
+
+	// Assert
+	assert.Error(t, err)
+	assert.Zero(t, count)
+	assert.Empty(t, publisher.events)
+}
+
+func TestParseEscalationStatuses(t *testing.T) {
+	tests := map[string]struct {
+		in      []string
+		want    []string
+		wantErr bool
+	}{
+		"empty":     {nil, DefaultEscalationStatuses, false},
+		"canonical": {[]string{"Pending", "IN_PROGRESS"}, []string{"pending", "in_progress"}, false},
+		"unknown":   {[]string{"pending", "done"}, nil, true},
+		"completed": {[]string{"completed"}, nil, true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseEscalationStatuses(tc.in)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/cliffdoyle/task-api/internal/repository"
+)
+
+// ErrInvalidSnooze is returned for a snooze request without exactly one of duration and until,
+// with a value that doesn't parse, or that wouldn't move the due date into the future
+var ErrInvalidSnooze = errors.New("invalid snooze")
+
+// ErrTaskCompleted is returned when an operation meant for open tasks is tried on a completed one
+var ErrTaskCompleted = errors.New("task is completed")
+
+// SnoozeTask pushes a task's due date later, by a duration or to a given date, and records the
+// change in the audit log in the same transaction. Due dates are calendar days, so a duration
+// moves the date by the days it spans: "36h" from the 10th lands on the 11th. A task without a
+// due date, or one already overdue, is snoozed from today. The new date must be after today and
+// after the current due date. Completed tasks can't be snoozed.
+func (s *taskService) SnoozeTask(id int, req *models.SnoozeTaskRequest) (*models.Task, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid task ID")
+	}
+	if (req.Duration == "") == (req.Until == "") {
+		return nil, fmt.Errorf("%w: give exactly one of duration and until", ErrInvalidSnooze)
+	}
+	actor := strings.TrimSpace(req.Actor)
+	if len(actor) > MaxActorLength {
+		return nil, fmt.Errorf("%w: actor must not exceed %d characters", ErrInvalidSnooze, MaxActorLength)
+	}
+	var duration time.Duration
+	var until models.Date
+	var err error
+	if req.Duration != "" {
+		if duration, err = parseSnoozeDuration(req.Duration); err != nil {
+			return nil, err
+		}
+	} else if until, err = parseSnoozeUntil(req.Until); err != nil {
+		return nil, err
+	}
+
+	var task, before *models.Task
+	err = s.withTx(func(repo repository.TaskRepository) (err error) {
+		task, err = repo.GetByID(id)
+		if err != nil {
+			return fmt.Errorf("task with ID %d not found: %w", id, err)
+		}
+		if task.Status == "completed" {
+			return fmt.Errorf("%w: only open tasks can be snoozed", ErrTaskCompleted)
+		}
+
+		today := models.NewDate(s.now().UTC())
+		due := until
+		if duration > 0 {
+			from := today
+			if task.DueDate != nil && task.DueDate.After(today.Time) {
+				from = *task.DueDate
+			}
+			due = models.NewDate(from.Add(duration))
+		}
+		if !due.After(today.Time) {
+			return fmt.Errorf("%w: new due date %s is not in the future", ErrInvalidSnooze, due)
+		}
+		if task.DueDate != nil && !due.After(task.DueDate.Time) {
+			return fmt.Errorf("%w: new due date %s is not after the current one, %s", ErrInvalidSnooze, due, task.DueDate)
+		}
+
+		before = snapshot(task)
+		task.DueDate = &due
+		if err := repo.Update(task); err != nil {
+			return fmt.Errorf("failed to update task in repository: %w", err)
+		}
+		previous := "none"
+		if before.DueDate != nil {
+			previous = before.DueDate.String()
+		}
+		return repo.AddAuditEntry(&models.AuditEntry{
+			TaskID: id,
+			Actor:  actor,
+			Action: models.AuditActionSnooze,
+			Note:   fmt.Sprintf("due_date %s -> %s", previous, due),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publishUpdate(before, task)
+	return task, nil
+}
+
+// parseSnoozeDuration accepts a Go duration ("90m", "48h") or a whole number of days ("3d")
+func parseSnoozeDuration(raw string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("%w: duration %q is not a duration such as \"48h\" or \"3d\"", ErrInvalidSnooze, raw)
+		}
+		// Checked before multiplying so a huge count can't overflow
+		if n > MaxDueInDays {
+			return 0, fmt.Errorf("%w: duration must not exceed %d days", ErrInvalidSnooze, MaxDueInDays)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(raw); err != nil {
+			return 0, fmt.Errorf("%w: duration %q is not a duration such as \"48h\" or \"3d\"", ErrInvalidSnooze, raw)
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%w: duration must be positive", ErrInvalidSnooze)
+	}
+	if d > MaxDueInDays*24*time.Hour {
+		return 0, fmt.Errorf("%w: duration must not exceed %d days", ErrInvalidSnooze, MaxDueInDays)
+	}
+	return d, nil
+}
+
+// parseSnoozeUntil accepts a calendar day or an RFC 3339 timestamp, which is taken as the UTC
+// day it falls on
+func parseSnoozeUntil(raw string) (models.Date, error) {
+	if d, err := models.ParseDate(raw); err == nil {
+		return d, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return models.Date{}, fmt.Errorf("%w: until %q is not a date (YYYY-MM-DD) or RFC 3339 timestamp", ErrInvalidSnooze, raw)
+	}
+	return models.NewDate(t.UTC()), nil
+}
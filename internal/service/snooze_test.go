@@ -0,0 +1,97 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// snoozeNow is the fixed clock for snooze tests: the morning of 10 May 2024, UTC
+var snoozeNow = time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+
+func TestSnoozeTask(t *testing.T) {
+	tests := map[string]struct {
+		due      *models.Date
+		req      models.SnoozeTaskRequest
+		wantDue  string
+		wantNote string
+	}{
+		"by days from the due date":  {dueDate("2024-05-12"), models.SnoozeTaskRequest{Duration: "3d"}, "2024-05-15", "due_date 2024-05-12 -> 2024-05-15"},
+		"by hours spanning a day":    {dueDate("2024-05-12"), models.SnoozeTaskRequest{Duration: "36h"}, "2024-05-13", "due_date 2024-05-12 -> 2024-05-13"},
+		"overdue snoozes from today": {dueDate("2024-05-01"), models.SnoozeTaskRequest{Duration: "48h"}, "2024-05-12", "due_date 2024-05-01 -> 2024-05-12"},
+		"no due date":                {nil, models.SnoozeTaskRequest{Duration: "1d"}, "2024-05-11", "due_date none -> 2024-05-11"},
+		"until a date":               {dueDate("2024-05-12"), models.SnoozeTaskRequest{Until: "2024-06-01"}, "2024-06-01", "due_date 2024-05-12 -> 2024-06-01"},
+		"until a timestamp, in UTC":  {nil, models.SnoozeTaskRequest{Until: "2024-05-20T23:30:00-02:00"}, "2024-05-21", "due_date none -> 2024-05-21"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			publisher := &recordingPublisher{}
+			svc := NewTaskService(mockRepo, WithEventPublisher(publisher)).(*taskService)
+			svc.now = func() time.Time { return snoozeNow }
+			mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Status: "pending", DueDate: tc.due}, nil)
+			mockRepo.On("Update", mock.MatchedBy(func(task *models.Task) bool { return task.DueDate.String() == tc.wantDue })).Return(nil)
+			mockRepo.On("AddAuditEntry", &models.AuditEntry{TaskID: 1, Actor: "alice", Action: models.AuditActionSnooze, Note: tc.wantNote}).Return(nil)
+			req := tc.req
+			req.Actor = "alice"
+
+			// Act
+			task, err := svc.SnoozeTask(1, &req)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantDue, task.DueDate.String())
+			mockRepo.AssertExpectations(t)
+			assert.Len(t, publisher.events, 1)
+			assert.Contains(t, publisher.events[0].Changes, "due_date")
+		})
+	}
+}
+
+func TestSnoozeTask_Rejected(t *testing.T) {
+	tests := map[string]struct {
+		status  string
+		due     *models.Date
+		req     models.SnoozeTaskRequest
+		want    error
+		wantMsg string
+	}{
+		"completed":             {"completed", nil, models.SnoozeTaskRequest{Duration: "1d"}, ErrTaskCompleted, ""},
+		"neither given":         {"pending", nil, models.SnoozeTaskRequest{}, ErrInvalidSnooze, "give exactly one of duration and until"},
+		"both given":            {"pending", nil, models.SnoozeTaskRequest{Duration: "1d", Until: "2024-06-01"}, ErrInvalidSnooze, ""},
+		"bad duration":          {"pending", nil, models.SnoozeTaskRequest{Duration: "a while"}, ErrInvalidSnooze, ""},
+		"negative duration":     {"pending", nil, models.SnoozeTaskRequest{Duration: "-2h"}, ErrInvalidSnooze, "duration must be positive"},
+		"too many days":         {"pending", nil, models.SnoozeTaskRequest{Duration: "99999999999999d"}, ErrInvalidSnooze, ""},
+		"bad until":             {"pending", nil, models.SnoozeTaskRequest{Until: "next week"}, ErrInvalidSnooze, ""},
+		"until in the past":     {"pending", nil, models.SnoozeTaskRequest{Until: "2024-05-01"}, ErrInvalidSnooze, "new due date 2024-05-01 is not in the future"},
+		"until today":           {"pending", nil, models.SnoozeTaskRequest{Until: "2024-05-10"}, ErrInvalidSnooze, "is not in the future"},
+		"hours within today":    {"pending", nil, models.SnoozeTaskRequest{Duration: "2h"}, ErrInvalidSnooze, "new due date 2024-05-10 is not in the future"},
+		"until before due date": {"pending", dueDate("2024-05-20"), models.SnoozeTaskRequest{Until: "2024-05-15"}, ErrInvalidSnooze, "not after the current one, 2024-05-20"},
+		"actor too long":        {"pending", nil, models.SnoozeTaskRequest{Duration: "1d", Actor: strings.Repeat("a", MaxActorLength+1)}, ErrInvalidSnooze, ""},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			svc := NewTaskService(mockRepo).(*taskService)
+			svc.now = func() time.Time { return snoozeNow }
+			mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Status: tc.status, DueDate: tc.due}, nil).Maybe()
+
+			// Act
+			_, err := svc.SnoozeTask(1, &tc.req)
+
+			// Assert
+			assert.ErrorIs(t, err, tc.want)
+			if tc.wantMsg != "" {
+				assert.ErrorContains(t, err, tc.wantMsg)
+			}
+			mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+			mockRepo.AssertNotCalled(t, "AddAuditEntry", mock.Anything)
+		})
+	}
+}
@@ -1,8 +1,18 @@
 package service
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"expvar"
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/cliffdoyle/task-api/internal/models"
 	"github.com/cliffdoyle/task-api/internal/repository"
@@ -11,39 +21,501 @@ import (
 // TaskService defines the interface for task-related business logic
 type TaskService interface {
 	CreateTask(req *models.CreateTaskRequest) (*models.Task, error)
+	CreateTaskIfNotExists(req *models.CreateTaskRequest) (task *models.Task, created bool, err error)
 	GetTask(id int) (*models.Task, error)
-	GetAllTasks() ([]*models.Task, error)
+	ResolveUUID(uuid string) (int, error)
+	GetAllTasks(filter models.ListFilter) ([]*models.Task, error)
+	ListTaskSummaries(filter models.ListFilter) ([]*models.TaskSummary, error)
+	BatchGetTasks(req *models.BatchGetRequest) (*models.BatchGetResponse, error)
+	GetChanges(since time.Time, cursor string, limit int) (*models.ChangesResponse, error)
+	GetRecentTasks(limit int) ([]*models.Task, error)
+	OldestPendingTask() (*models.OldestPendingResponse, error)
+	RandomTask(filter models.ListFilter) (*models.Task, error)
+	DailyMetrics(from, to models.Date) ([]*models.DailyMetrics, error)
+	Workload(statuses []string) ([]*models.AssigneeWorkload, error)
+	CreateTemplate(req *models.CreateTemplateRequest) (*models.TaskTemplate, error)
+	GetTemplate(id int) (*models.TaskTemplate, error)
+	ListTemplates() ([]*models.TaskTemplate, error)
+	DeleteTemplate(id int) error
+	CreateTaskFromTemplate(templateID int, overrides *models.CreateTaskRequest) (*models.Task, error)
 	UpdateTask(id int, req *models.UpdateTaskRequest) (*models.Task, error)
-	DeleteTask(id int) error
+	PutTask(id int, req *models.UpdateTaskRequest) (*models.Task, bool, error)
+	PatchTask(id int, patch *models.PatchTaskRequest) (*models.Task, error)
+	DeleteTask(id int, req *models.DeleteTaskRequest) error
+	DeleteTaskReturning(id int, req *models.DeleteTaskRequest) (*models.Task, error)
+	ClaimTask(req *models.ClaimTaskRequest) (*models.Task, error)
+	ReleaseTask(id int, req *models.ReleaseTaskRequest) (*models.Task, error)
+	ReopenTask(id int, req *models.ReopenTaskRequest) (*models.Task, error)
+	AssignTask(id int, req *models.AssignTaskRequest) (*models.Task, error)
+	ReassignTasks(req *models.ReassignTasksRequest) (*models.ReassignTasksResponse, error)
+	NextTransitions(id int) (*models.NextTransitionsResponse, error)
+	TransitionTask(id int, req *models.TransitionTaskRequest) (*models.Task, error)
+	SnoozeTask(id int, req *models.SnoozeTaskRequest) (*models.Task, error)
+	ReclaimExpiredLeases() (int, error)
+	EscalatePriorities() (int, error)
+	RunMaintenance(ctx context.Context, req *models.MaintenanceRequest) (*models.MaintenanceReport, error)
 }
 
+// MaxMetadataBytes caps the serialised size of a task's metadata
+const MaxMetadataBytes = 4096
+
+// MaxWorkerIDLength matches the claimed_by column size
+const MaxWorkerIDLength = 255
+
+// MaxDescriptionLength matches the tasks_description_length constraint, in characters
+const MaxDescriptionLength = 10000
+
+// MaxAssigneeLength matches the assignee column size
+const MaxAssigneeLength = 255
+
+// MaxBatchGetIDs is the default cap on how many IDs a single batch request may carry
+const MaxBatchGetIDs = 100
+
+// MaxReopenReasonLength caps the reason recorded when a task is reopened
+const MaxReopenReasonLength = 1000
+
+// DefaultReopenStatus is the status a reopened task returns to
+const DefaultReopenStatus = "in_progress"
+
+// MaxChangesLimit caps the page size of the changes feed
+const MaxChangesLimit = 500
+
+// DefaultRecentLimit and MaxRecentLimit are the default and largest page sizes of the
+// recently-updated list
+const (
+	DefaultRecentLimit = 10
+	MaxRecentLimit     = 100
+)
+
+// DefaultLeaseDuration is how long a claimed task is held before its lease expires
+const DefaultLeaseDuration = 5 * time.Minute
+
+// ErrInvalidStatus is returned when a request names a status tasks can't have
+var ErrInvalidStatus = errors.New("invalid status value")
+
+// ErrInvalidMetadata is returned (wrapped with details) when metadata fails validation
+var ErrInvalidMetadata = errors.New("invalid metadata")
+
+// ErrInvalidBatch is returned when a batch get's ID list is empty, too long or has invalid IDs
+var ErrInvalidBatch = errors.New("invalid batch")
+
+// ErrInvalidPatch is returned when a merge patch would leave the task invalid
+var ErrInvalidPatch = errors.New("invalid patch")
+
+// ErrInvalidReopenReason is returned when a reopen request has a missing or over-long reason
+var ErrInvalidReopenReason = errors.New("invalid reason")
+
+// ErrInvalidCursor is returned when a changes feed cursor can't be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrInvalidAssignee is returned when an assignee is too long or uses the reserved name "none"
+var ErrInvalidAssignee = errors.New("invalid assignee")
+
+// ErrInvalidDescription is returned when a description exceeds MaxDescriptionLength, or is
+// missing while descriptions are required
+var ErrInvalidDescription = errors.New("invalid description")
+
+// ErrInvalidDueDate is returned when a create request's due_in_days is out of range
+var ErrInvalidDueDate = errors.New("invalid due date")
+
+// MaxDueInDays is the largest due-date offset, from configuration or a request
+const MaxDueInDays = 3650
+
+// ErrTaskLocked is returned when an update would edit a completed task while completed tasks
+// are locked (see WithLockCompleted)
+var ErrTaskLocked = errors.New("task is locked")
+
+// ErrTitleRequired is returned when a task would be created without a title
+var ErrTitleRequired = errors.New("title is required")
+
+// ErrInvalidWorkerID is returned when a claim has a missing or over-long worker ID
+var ErrInvalidWorkerID = errors.New("invalid worker_id")
+
 // taskService is an implementation of TaskService
 type taskService struct {
-	repo repository.TaskRepository
+	repo          repository.TaskRepository
+	leaseDuration time.Duration
+	reopenStatus  string
+	transitions   Transitions
+	now           func() time.Time
+
+	assigneeFormat AssigneeFormat
+
+	// recentLimit is used when GetRecentTasks is called without a limit; larger limits are
+	// capped at maxRecentLimit
+	recentLimit    int
+	maxRecentLimit int
+
+	// listMaxAge limits lists without date filters to tasks created within it; zero lists all
+	listMaxAge time.Duration
+
+	// randomSample picks random tasks by sampling from a random ID rather than shuffling every
+	// match (see repository.TaskRepository.GetRandom)
+	randomSample bool
+
+	// listOrder is the creation-time order of lists that don't ask for one; empty leaves it to
+	// the repository, which lists newest first
+	listOrder models.SortOrder
+
+	// sortDirections is the direction a list sorted by a field goes in when it doesn't give one
+	sortDirections map[models.SortField]models.SortOrder
+
+	// escalationStatuses and escalationAfterDays pick the overdue tasks EscalatePriorities raises
+	escalationStatuses  []string
+	escalationAfterDays int
+
+	// requireDescription rejects tasks created, or edited, without a description
+	requireDescription bool
+
+	// foldStatus accepts statuses in any case and stores them lowercased
+	foldStatus bool
+
+	// lockCompleted refuses PUT and PATCH edits to completed tasks other than a status change
+	lockCompleted bool
+
+	// reviveOnUpdate lets PUT and PATCH bring a soft-deleted task back instead of returning not found
+	reviveOnUpdate bool
+
+	// skipNoopUpdates makes a PUT or PATCH that changes nothing skip the write, leaving
+	// updated_at as it was and publishing no event
+	skipNoopUpdates bool
+
+	// maxBatchIDs caps the IDs in one batch request; rejectDuplicateIDs makes a repeated ID an
+	// error instead of being silently collapsed
+	maxBatchIDs        int
+	rejectDuplicateIDs bool
+
+	events    EventPublisher // nil when nothing subscribes to task events
+	eventMode EventMode      // how bulk operations publish; empty means EventsPerItem
+
+	// defaultDueDays is how many days after creation a task is due when the request gives no
+	// due date; zero leaves it without one
+	defaultDueDays int
+
+	// titlePolicy holds the minimum length and blocklist titles are checked against
+	titlePolicy TitlePolicy
+
+	// txLimit caps how many transactional operations run at once; nil means no limit
+	txLimit *txLimiter
+
+	// maintenance sets which operations RunMaintenance may run; none by default
+	maintenance Maintenance
+}
+
+// Option configures optional taskService behaviour
+type Option func(*taskService)
+
+// WithLeaseDuration sets how long a claimed task is held by its worker
+func WithLeaseDuration(d time.Duration) Option {
+	return func(s *taskService) {
+		if d > 0 {
+			s.leaseDuration = d
+		}
+	}
+}
+
+// WithReopenStatus sets the status a reopened task returns to: "pending" or "in_progress"
+func WithReopenStatus(status string) Option {
+	return func(s *taskService) {
+		if status == "pending" || status == "in_progress" {
+			s.reopenStatus = status
+		}
+	}
+}
+
+// WithBatchLimits sets how many IDs a batch request may carry and whether duplicate IDs are
+// rejected (rather than deduplicated). A max of zero or less keeps MaxBatchGetIDs.
+func WithBatchLimits(max int, rejectDuplicates bool) Option {
+	return func(s *taskService) {
+		if max > 0 {
+			s.maxBatchIDs = max
+		}
+		s.rejectDuplicateIDs = rejectDuplicates
+	}
+}
+
+// WithRecentLimits sets the default and maximum number of tasks GetRecentTasks returns.
+// Values of zero or less keep DefaultRecentLimit and MaxRecentLimit.
+func WithRecentLimits(def, max int) Option {
+	return func(s *taskService) {
+		if def > 0 {
+			s.recentLimit = def
+		}
+		if max > 0 {
+			s.maxRecentLimit = max
+		}
+	}
+}
+
+// WithListMaxAge limits task lists that have no date filter (created_after, created_before,
+// updated_after or updated_before), and don't set All, to tasks created within maxAge. Zero, the default, lists tasks of any age.
+func WithListMaxAge(maxAge time.Duration) Option {
+	return func(s *taskService) {
+		if maxAge >= 0 {
+			s.listMaxAge = maxAge
+		}
+	}
+}
+
+// WithDefaultListOrder sets the creation-time order of task lists whose filter has no Order.
+// The default is models.SortNewestFirst; unknown orders are ignored.
+func WithDefaultListOrder(order models.SortOrder) Option {
+	return func(s *taskService) {
+		if order == models.SortNewestFirst || order == models.SortOldestFirst {
+			s.listOrder = order
+		}
+	}
+}
+
+// DefaultSortDirections returns the direction a list sorted by each field goes in when the request
+// doesn't give one: oldest first by creation time, soonest due first, and most recently
+// completed first
+func DefaultSortDirections() map[models.SortField]models.SortOrder {
+	return map[models.SortField]models.SortOrder{
+		models.SortByCreatedAt:   models.SortOldestFirst,
+		models.SortByDueDate:     models.SortOldestFirst,
+		models.SortByCompletedAt: models.SortNewestFirst,
+	}
+}
+
+// WithSortDirections overrides the default direction of the fields in dirs, leaving the others
+// as DefaultSortDirections has them. Unknown orders are ignored.
+func WithSortDirections(dirs map[models.SortField]models.SortOrder) Option {
+	return func(s *taskService) {
+		for field, order := range dirs {
+			if order == models.SortNewestFirst || order == models.SortOldestFirst {
+				s.sortDirections[field] = order
+			}
+		}
+	}
+}
+
+// WithRandomSampling makes RandomTask sample from a random ID, which stays fast on large tables
+// but favours tasks that follow gaps in the IDs, instead of shuffling every match
+func WithRandomSampling(enabled bool) Option {
+	return func(s *taskService) {
+		s.randomSample = enabled
+	}
+}
+
+// WithRequireDescription makes a description mandatory: creating a task without one, or
+// clearing it on update, fails with ErrInvalidDescription. An update that leaves the
+// description out doesn't touch it and is still allowed.
+func WithRequireDescription(required bool) Option {
+	return func(s *taskService) {
+		s.requireDescription = required
+	}
+}
+
+// checkDescription validates a description the request is setting, including that it isn't
+// blank when descriptions are required
+func (s *taskService) checkDescription(description string) error {
+	if s.requireDescription && strings.TrimSpace(description) == "" {
+		return fmt.Errorf("%w: description is required", ErrInvalidDescription)
+	}
+	return validateDescription(description)
+}
+
+// WithCaseInsensitiveStatus makes UpdateTask, PatchTask and TransitionTask accept a status in any
+// case ("In_Progress", "PENDING") and store its canonical lowercase form
+func WithCaseInsensitiveStatus(enabled bool) Option {
+	return func(s *taskService) {
+		s.foldStatus = enabled
+	}
+}
+
+// canonicalStatus lowercases a known status when statuses are case-insensitive. Anything else
+// is returned unchanged for the caller's validation to reject.
+func (s *taskService) canonicalStatus(status string) string {
+	if !s.foldStatus {
+		return status
+	}
+	canonical, _ := models.CanonicalStatus(status)
+	return canonical
+}
+
+// WithLockCompleted makes completed tasks read-only to UpdateTask and PatchTask: the only change
+// they accept is moving the task to another status, after which it can be edited again
+func WithLockCompleted(lock bool) Option {
+	return func(s *taskService) {
+		s.lockCompleted = lock
+	}
+}
+
+// WithReviveOnUpdate makes updating a soft-deleted task undelete it and apply the update,
+// instead of failing with repository.ErrTaskNotFound
+func WithReviveOnUpdate(revive bool) Option {
+	return func(s *taskService) {
+		s.reviveOnUpdate = revive
+	}
+}
+
+// noopUpdates counts PUT and PATCH requests skipped because they changed nothing
+var noopUpdates = expvar.NewInt("tasks_noop_updates_total")
+
+// WithSkipNoopUpdates makes an update whose submitted fields all equal the task's current values
+// return the task without writing it, so updated_at keeps its value and no event is published
+func WithSkipNoopUpdates(skip bool) Option {
+	return func(s *taskService) {
+		s.skipNoopUpdates = skip
+	}
+}
+
+// WithDefaultDueDays gives tasks created without a due_date one that many days after creation.
+// Zero, the default, disables it; values outside 0..MaxDueInDays are ignored.
+func WithDefaultDueDays(days int) Option {
+	return func(s *taskService) {
+		if days >= 0 && days <= MaxDueInDays {
+			s.defaultDueDays = days
+		}
+	}
 }
 
 // NewTaskService creates a new instance of TaskService
-func NewTaskService(repo repository.TaskRepository) TaskService {
-	return &taskService{repo: repo}
+func NewTaskService(repo repository.TaskRepository, opts ...Option) TaskService {
+	s := &taskService{
+		repo:          repo,
+		leaseDuration: DefaultLeaseDuration,
+		reopenStatus:  DefaultReopenStatus,
+		transitions:   DefaultTransitions(),
+		now:           time.Now,
+		maxBatchIDs:   MaxBatchGetIDs,
+
+		assigneeFormat: AssigneeFormatUsername,
+		sortDirections: DefaultSortDirections(),
+		recentLimit:    DefaultRecentLimit,
+		maxRecentLimit: MaxRecentLimit,
+
+		escalationStatuses: DefaultEscalationStatuses,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // CreateTask handles the creation of a new task, including validation
 func (s *taskService) CreateTask(req *models.CreateTaskRequest) (*models.Task, error) {
+	task, err := s.newTask(req)
+	if err != nil {
+		return nil, err
+	}
+	actor, err := checkActor(req.Actor)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.withTx(func(repo repository.TaskRepository) error {
+		if err := repo.Create(task); err != nil {
+			return fmt.Errorf("failed to create task in repository: %w", err)
+		}
+		return repo.AddAuditEntry(createEntry(actor, task))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(models.TaskEvent{Type: models.EventTaskCreated, TaskID: task.ID, Task: task})
+	return task, nil
+}
+
+// CreateTaskIfNotExists returns the live task whose title matches req.Title, trimmed and
+// case-insensitively, or creates one from req if there is none; created reports which. The check
+// and the create run in one transaction under a lock on the title, so concurrent calls for the
+// same title create it once. Plain CreateTask calls don't take the lock.
+func (s *taskService) CreateTaskIfNotExists(req *models.CreateTaskRequest) (*models.Task, bool, error) {
+	task, err := s.newTask(req)
+	if err != nil {
+		return nil, false, err
+	}
+	actor, err := checkActor(req.Actor)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var existing *models.Task
+	err = s.withTx(func(repo repository.TaskRepository) (err error) {
+		if err := repo.LockTitle(task.Title); err != nil {
+			return fmt.Errorf("failed to lock title: %w", err)
+		}
+		existing, err = repo.GetByTitle(task.Title)
+		if !errors.Is(err, repository.ErrTaskNotFound) {
+			return err
+		}
+		if err := repo.Create(task); err != nil {
+			return fmt.Errorf("failed to create task in repository: %w", err)
+		}
+		return repo.AddAuditEntry(createEntry(actor, task))
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		return existing, false, nil
+	}
+
+	s.publish(models.TaskEvent{Type: models.EventTaskCreated, TaskID: task.ID, Task: task})
+	return task, true, nil
+}
+
+// newTask validates a create request and builds the task it describes
+func (s *taskService) newTask(req *models.CreateTaskRequest) (*models.Task, error) {
 	if req.Title == "" {
-		return nil, errors.New("title is required")
+		return nil, ErrTitleRequired
+	}
+	if err := s.checkTitle(req.Title); err != nil {
+		return nil, err
+	}
+	if err := s.checkDescription(req.Description); err != nil {
+		return nil, err
+	}
+	if err := validateMetadata(req.Metadata); err != nil {
+		return nil, err
+	}
+	assignee, err := s.normalizeAssignee(req.Assignee)
+	if err != nil {
+		return nil, err
+	}
+	dueDate, err := s.defaultDueDate(req)
+	if err != nil {
+		return nil, err
+	}
+	priority := models.DefaultPriority
+	if req.Priority != "" {
+		if priority, err = checkPriority(req.Priority); err != nil {
+			return nil, err
+		}
 	}
 
-	task := &models.Task{
+	return &models.Task{
 		Title:       req.Title,
 		Description: req.Description,
 		Status:      "pending", // Default status for new tasks
-	}
+		Priority:    priority,
+		Metadata:    req.Metadata,
+		Assignee:    assignee,
+		DueDate:     dueDate,
+	}, nil
+}
 
-	if err := s.repo.Create(task); err != nil {
-		return nil, fmt.Errorf("failed to create task in repository: %w", err)
+// defaultDueDate returns the due date of a new task: the request's due_date if it has one,
+// otherwise today (UTC) plus due_in_days or, failing that, the configured default offset
+func (s *taskService) defaultDueDate(req *models.CreateTaskRequest) (*models.Date, error) {
+	days := s.defaultDueDays
+	if req.DueInDays != nil {
+		days = *req.DueInDays
+		if days < 0 || days > MaxDueInDays {
+			return nil, fmt.Errorf("%w: due_in_days must be between 0 and %d", ErrInvalidDueDate, MaxDueInDays)
+		}
 	}
-
-	return task, nil
+	if req.DueDate != nil {
+		return req.DueDate, nil
+	}
+	if days == 0 {
+		return nil, nil
+	}
+	due := models.NewDate(s.now().UTC().AddDate(0, 0, days))
+	return &due, nil
 }
 
 // GetTask retrieves a single task by its ID
@@ -58,56 +530,810 @@ func (s *taskService) GetTask(id int) (*models.Task, error) {
 	return task, nil
 }
 
-// GetAllTasks retrieves all tasks
-func (s *taskService) GetAllTasks() ([]*models.Task, error) {
-	tasks, err := s.repo.GetAll()
+// ResolveUUID returns the internal ID of the task with the given UUID
+func (s *taskService) ResolveUUID(uuid string) (int, error) {
+	id, err := s.repo.GetIDByUUID(uuid)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve task UUID: %w", err)
+	}
+	return id, nil
+}
+
+// GetAllTasks retrieves all tasks matching the filter
+func (s *taskService) GetAllTasks(filter models.ListFilter) ([]*models.Task, error) {
+	tasks, err := s.repo.GetAll(s.listFilter(filter))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all tasks from repository: %w", err)
 	}
+	// Never hand back a nil slice: it would be encoded as null instead of []
+	if tasks == nil {
+		tasks = []*models.Task{}
+	}
 	return tasks, nil
 }
 
+// ListTaskSummaries retrieves the summaries of all tasks matching the filter, in the same order
+// as GetAllTasks
+func (s *taskService) ListTaskSummaries(filter models.ListFilter) ([]*models.TaskSummary, error) {
+	summaries, err := s.repo.GetSummaries(s.listFilter(filter))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task summaries from repository: %w", err)
+	}
+	if summaries == nil {
+		summaries = []*models.TaskSummary{}
+	}
+	return summaries, nil
+}
+
+// listFilter normalises a list filter to match how values are stored and applies the default
+// max-age window when the filter has no dates of its own
+func (s *taskService) listFilter(filter models.ListFilter) models.ListFilter {
+	// Email assignees are stored lowercased, so match them that way
+	if s.assigneeFormat == AssigneeFormatEmail {
+		filter.Assignee = strings.ToLower(filter.Assignee)
+	}
+	if s.listMaxAge > 0 && !filter.All && !hasDateFilter(filter) {
+		after := s.now().UTC().Add(-s.listMaxAge)
+		filter.CreatedAfter = &after
+	}
+	if filter.Order == "" && filter.SortBy != "" {
+		filter.Order = s.sortDirections[filter.SortBy]
+	} else if filter.Order == "" {
+		filter.Order = s.listOrder
+	}
+	return filter
+}
+
+// hasDateFilter reports whether filter bounds creation or change time, which replaces the
+// default max-age window
+func hasDateFilter(filter models.ListFilter) bool {
+	return filter.CreatedAfter != nil || filter.CreatedBefore != nil ||
+		filter.UpdatedAfter != nil || filter.UpdatedBefore != nil
+}
+
+// BatchGetTasks fetches several tasks at once and reports which of the requested IDs don't exist.
+// Duplicate IDs are collapsed; missing IDs are returned in the order they were requested.
+func (s *taskService) BatchGetTasks(req *models.BatchGetRequest) (*models.BatchGetResponse, error) {
+	ids, err := s.batchIDs(req.IDs)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.repo.GetByIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks from repository: %w", err)
+	}
+
+	found := make(map[int]bool, len(tasks))
+	for _, task := range tasks {
+		found[task.ID] = true
+	}
+	missing := []int{}
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	if tasks == nil {
+		tasks = []*models.Task{}
+	}
+	return &models.BatchGetResponse{Found: tasks, Missing: missing}, nil
+}
+
+// batchIDs validates the IDs of a batch request and returns them deduplicated, in first-seen
+// order. Every batch endpoint should go through it so they share the same limits.
+func (s *taskService) batchIDs(raw []int) ([]int, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("%w: ids must not be empty", ErrInvalidBatch)
+	}
+	if len(raw) > s.maxBatchIDs {
+		return nil, fmt.Errorf("%w: at most %d ids may be requested", ErrInvalidBatch, s.maxBatchIDs)
+	}
+
+	seen := make(map[int]bool, len(raw))
+	ids := make([]int, 0, len(raw))
+	var duplicates []int
+	for _, id := range raw {
+		if id <= 0 {
+			return nil, fmt.Errorf("%w: invalid task ID %d", ErrInvalidBatch, id)
+		}
+		if seen[id] {
+			if s.rejectDuplicateIDs && !containsID(duplicates, id) {
+				duplicates = append(duplicates, id)
+			}
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(duplicates) > 0 {
+		return nil, fmt.Errorf("%w: duplicate ids %v", ErrInvalidBatch, duplicates)
+	}
+	return ids, nil
+}
+
+// containsID reports whether ids contains id
+func containsID(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// GetChanges returns a page of tasks changed after since (or after cursor, when continuing a
+// previous page), oldest change first. Deleted tasks are included and flagged.
+func (s *taskService) GetChanges(since time.Time, cursor string, limit int) (*models.ChangesResponse, error) {
+	if limit <= 0 || limit > MaxChangesLimit {
+		limit = MaxChangesLimit
+	}
+
+	// Task IDs are SERIAL (int4), so MaxInt32 skips every row updated exactly at since
+	after := models.ChangesCursor{UpdatedAt: since, ID: math.MaxInt32}
+	if cursor != "" {
+		var err error
+		if after, err = decodeChangesCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	// Read from the database before the query, so every change dated before it is already
+	// committed and in this page or an earlier one. It trails open write transactions, so the
+	// next sync may repeat a few changes but never misses one.
+	serverTime, err := s.repo.ChangesWatermark()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read changes watermark from repository: %w", err)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	tasks, err := s.repo.GetChanges(after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changes from repository: %w", err)
+	}
+
+	resp := &models.ChangesResponse{Changes: []models.TaskChange{}, ServerTime: serverTime.UTC()}
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+		last := tasks[len(tasks)-1]
+		resp.NextCursor = encodeChangesCursor(models.ChangesCursor{UpdatedAt: last.UpdatedAt, ID: last.ID})
+	}
+	for _, task := range tasks {
+		resp.Changes = append(resp.Changes, models.TaskChange{Task: task, Deleted: task.DeletedAt != nil})
+	}
+	return resp, nil
+}
+
+// GetRecentTasks returns the most recently updated tasks, newest first. A limit of zero uses
+// the configured default and a limit above the maximum is capped.
+func (s *taskService) GetRecentTasks(limit int) ([]*models.Task, error) {
+	if limit <= 0 {
+		limit = s.recentLimit
+	}
+	if limit > s.maxRecentLimit {
+		limit = s.maxRecentLimit
+	}
+	tasks, err := s.repo.GetRecent(limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent tasks from repository: %w", err)
+	}
+	if tasks == nil {
+		tasks = []*models.Task{}
+	}
+	return tasks, nil
+}
+
+// OldestPendingTask returns the pending task that has waited longest and how long it has waited.
+// The error wraps repository.ErrNoTaskAvailable when nothing is pending.
+func (s *taskService) OldestPendingTask() (*models.OldestPendingResponse, error) {
+	task, err := s.repo.GetOldestPending()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get oldest pending task from repository: %w", err)
+	}
+	age := s.now().Sub(task.CreatedAt)
+	if age < 0 {
+		age = 0 // clock skew between the server and the database
+	}
+	return &models.OldestPendingResponse{Task: task, AgeSeconds: int64(age / time.Second)}, nil
+}
+
+// RandomTask returns one task matching filter, chosen at random. Only the filter's conditions
+// apply: it is never limited to the list age window. The error wraps
+// repository.ErrNoTaskAvailable when no task matches.
+func (s *taskService) RandomTask(filter models.ListFilter) (*models.Task, error) {
+	filter.All = true
+	task, err := s.repo.GetRandom(s.listFilter(filter), s.randomSample)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get random task from repository: %w", err)
+	}
+	return task, nil
+}
+
+// encodeChangesCursor makes an opaque cursor from a keyset position
+func encodeChangesCursor(c models.ChangesCursor) string {
+	raw := c.UpdatedAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.Itoa(c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeChangesCursor reverses encodeChangesCursor
+func decodeChangesCursor(cursor string) (models.ChangesCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return models.ChangesCursor{}, ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return models.ChangesCursor{}, ErrInvalidCursor
+	}
+	updatedAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return models.ChangesCursor{}, ErrInvalidCursor
+	}
+	n, err := strconv.Atoi(id)
+	if err != nil || n <= 0 {
+		return models.ChangesCursor{}, ErrInvalidCursor
+	}
+	return models.ChangesCursor{UpdatedAt: updatedAt, ID: n}, nil
+}
+
+// withTx runs fn in a repository transaction, holding a slot under WithMaxConcurrentTransactions
+// while it does. Methods that read a task and then write it back use it so the read and the
+// write either both happen or neither does.
+func (s *taskService) withTx(fn func(repo repository.TaskRepository) error) error {
+	release, err := s.txLimit.acquire()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.repo.WithTransaction(context.Background(), fn)
+}
+
+// getForUpdate loads the task an update applies to. In revive mode a soft-deleted task is
+// undeleted first, and revived reports it; the caller's transaction rolls that back if the
+// update then fails.
+func (s *taskService) getForUpdate(repo repository.TaskRepository, id int) (task *models.Task, revived bool, err error) {
+	task, err = repo.GetByID(id)
+	if !errors.Is(err, repository.ErrTaskNotFound) || !s.reviveOnUpdate {
+		return task, false, err
+	}
+	if rerr := repo.Revive(id); rerr != nil {
+		if errors.Is(rerr, repository.ErrTaskNotFound) {
+			return nil, false, err // never existed
+		}
+		return nil, false, fmt.Errorf("failed to revive deleted task: %w", rerr)
+	}
+	task, err = repo.GetByID(id)
+	return task, true, err
+}
+
+// isNoop reports whether an update can be skipped: skipping is on, the task wasn't just revived
+// and no client-editable field changed. Skipped updates leave updated_at alone and publish nothing.
+func (s *taskService) isNoop(before, after *models.Task, revived bool) bool {
+	if !s.skipNoopUpdates || revived || len(diffTasks(before, after)) > 0 {
+		return false
+	}
+	noopUpdates.Add(1)
+	return true
+}
+
+// checkLocked refuses an update that edits a completed task when completed tasks are locked.
+// A status change on its own is allowed, so the task can be reopened.
+func (s *taskService) checkLocked(before, after *models.Task) error {
+	if !s.lockCompleted || before.Status != "completed" {
+		return nil
+	}
+	var fields []string
+	for field := range diffTasks(before, after) {
+		if field != "status" {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) > 0 {
+		sort.Strings(fields)
+		return fmt.Errorf("%w: completed tasks can't be edited (%s); reopen the task first", ErrTaskLocked, strings.Join(fields, ", "))
+	}
+	return nil
+}
+
 // UpdateTask updates an existing task with the provided request data
 func (s *taskService) UpdateTask(id int, req *models.UpdateTaskRequest) (*models.Task, error) {
 	if id <= 0 {
 		return nil, errors.New("invalid task ID")
 	}
+	actor, err := checkActor(req.Actor)
+	if err != nil {
+		return nil, err
+	}
 
-	existingTask, err := s.repo.GetByID(id)
+	var existingTask, before *models.Task
+	noop := false
+	err = s.withTx(func(repo repository.TaskRepository) (err error) {
+		var revived bool
+		existingTask, revived, err = s.getForUpdate(repo, id)
+		if err != nil {
+			return fmt.Errorf("task with ID %d not found: %w", id, err)
+		}
+		before = snapshot(existingTask)
+
+		// Apply updates if fields are provided
+		if req.Title != "" {
+			if err := s.checkTitle(req.Title); err != nil {
+				return err
+			}
+			existingTask.Title = req.Title
+		}
+		if req.Description != "" {
+			if err := s.checkDescription(req.Description); err != nil {
+				return err
+			}
+			existingTask.Description = req.Description
+		}
+		if req.Status != "" {
+			status := s.canonicalStatus(req.Status)
+			// Basic validation for status
+			if !models.IsValidStatus(status) {
+				return ErrInvalidStatus
+			}
+			if err := s.checkTransition(existingTask.Status, status); err != nil {
+				return err
+			}
+			existingTask.Status = status
+		}
+		if req.Metadata != nil {
+			if err := validateMetadata(req.Metadata); err != nil {
+				return err
+			}
+			existingTask.Metadata = req.Metadata
+		}
+		if req.Assignee != "" {
+			assignee, err := s.normalizeAssignee(req.Assignee)
+			if err != nil {
+				return err
+			}
+			existingTask.Assignee = assignee
+		}
+		if req.DueDate != nil {
+			existingTask.DueDate = req.DueDate
+		}
+		if req.Priority != "" {
+			if existingTask.Priority, err = checkPriority(req.Priority); err != nil {
+				return err
+			}
+		}
+		if err := s.checkLocked(before, existingTask); err != nil {
+			return err
+		}
+		if noop = s.isNoop(before, existingTask, revived); noop {
+			return nil
+		}
+
+		if err := repo.Update(existingTask); err != nil {
+			return fmt.Errorf("failed to update task in repository: %w", err)
+		}
+		return repo.AddAuditEntry(changeEntry(models.AuditActionUpdate, actor, before, existingTask))
+	})
 	if err != nil {
-		return nil, fmt.Errorf("task with ID %d not found: %w", id, err)
+		return nil, err
+	}
+	if !noop {
+		s.publishUpdate(before, existingTask)
+	}
+	return existingTask, nil
+}
+
+// PutTask is UpdateTask, except that when no live task has the ID it creates one with that ID
+// from req, as a REST PUT would; created reports which happened. The new task is built and
+// validated like one from CreateTask, so it needs a title; its status defaults to pending and
+// must be reachable from pending. A deleted task keeps its ID: unless WithReviveOnUpdate is set,
+// putting to it fails with repository.ErrTaskIDTaken.
+func (s *taskService) PutTask(id int, req *models.UpdateTaskRequest) (*models.Task, bool, error) {
+	task, err := s.UpdateTask(id, req)
+	if !errors.Is(err, repository.ErrTaskNotFound) {
+		return task, false, err
 	}
 
-	// Apply updates if fields are provided
-	if req.Title != "" {
-		existingTask.Title = req.Title
+	task, err = s.newTask(&models.CreateTaskRequest{
+		Title:       req.Title,
+		Description: req.Description,
+		Metadata:    req.Metadata,
+		Assignee:    req.Assignee,
+		DueDate:     req.DueDate,
+		Priority:    req.Priority,
+	})
+	if err != nil {
+		return nil, false, err
 	}
-	if req.Description != "" {
-		existingTask.Description = req.Description
+	actor, err := checkActor(req.Actor)
+	if err != nil {
+		return nil, false, err
 	}
+	task.ID = id
 	if req.Status != "" {
-		// Basic validation for status
-		if req.Status != "pending" && req.Status != "in_progress" && req.Status != "completed" {
-			return nil, errors.New("invalid status value")
+		status := s.canonicalStatus(req.Status)
+		if !models.IsValidStatus(status) {
+			return nil, false, ErrInvalidStatus
 		}
-		existingTask.Status = req.Status
+		if err := s.checkTransition(task.Status, status); err != nil {
+			return nil, false, err
+		}
+		task.Status = status
 	}
 
-	if err := s.repo.Update(existingTask); err != nil {
-		return nil, fmt.Errorf("failed to update task in repository: %w", err)
+	err = s.withTx(func(repo repository.TaskRepository) error {
+		if err := repo.CreateWithID(task); err != nil {
+			return fmt.Errorf("failed to create task in repository: %w", err)
+		}
+		return repo.AddAuditEntry(createEntry(actor, task))
+	})
+	if err != nil {
+		return nil, false, err
 	}
+	s.publish(models.TaskEvent{Type: models.EventTaskCreated, TaskID: task.ID, Task: task})
+	return task, true, nil
+}
 
-	return existingTask, nil
+// PatchTask applies a JSON Merge Patch to a task and validates the result before saving it
+func (s *taskService) PatchTask(id int, patch *models.PatchTaskRequest) (*models.Task, error) {
+	actor, err := checkActor(patch.Actor)
+	if err != nil {
+		return nil, err
+	}
+	return s.patchTask(id, patch, models.AuditActionPatch, actor)
 }
 
-// DeleteTask deletes a task by its ID
-func (s *taskService) DeleteTask(id int) error {
+// patchTask is PatchTask that records the change in the audit log as action, made by actor, in
+// the same transaction
+func (s *taskService) patchTask(id int, patch *models.PatchTaskRequest, action, actor string) (*models.Task, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid task ID")
+	}
+
+	var task, before *models.Task
+	noop := false
+	err := s.withTx(func(repo repository.TaskRepository) (err error) {
+		var revived bool
+		task, revived, err = s.getForUpdate(repo, id)
+		if err != nil {
+			return fmt.Errorf("failed to get task from repository: %w", err)
+		}
+		before = snapshot(task)
+
+		if patch.Title != nil {
+			if strings.TrimSpace(*patch.Title) == "" {
+				return fmt.Errorf("%w: title must not be empty", ErrInvalidPatch)
+			}
+			if err := s.checkTitle(*patch.Title); err != nil {
+				return err
+			}
+			task.Title = *patch.Title
+		}
+		if patch.Description != nil {
+			if err := s.checkDescription(*patch.Description); err != nil {
+				return err
+			}
+			task.Description = *patch.Description
+		}
+		if patch.Status != nil {
+			status := s.canonicalStatus(*patch.Status)
+			if !models.IsValidStatus(status) {
+				return fmt.Errorf("%w: invalid status value %q", ErrInvalidPatch, status)
+			}
+			if err := s.checkTransition(task.Status, status); err != nil {
+				return err
+			}
+			task.Status = status
+		}
+		if patch.ClearMetadata || patch.Metadata != nil {
+			merged := map[string]interface{}{}
+			if !patch.ClearMetadata {
+				for k, v := range task.Metadata {
+					merged[k] = v
+				}
+			}
+			for k, v := range patch.Metadata {
+				if v == nil {
+					delete(merged, k)
+					continue
+				}
+				merged[k] = v
+			}
+			if err := validateMetadata(merged); err != nil {
+				return err
+			}
+			task.Metadata = merged
+		}
+		if patch.Assignee != nil {
+			assignee, err := s.normalizeAssignee(*patch.Assignee)
+			if err != nil {
+				return err
+			}
+			task.Assignee = assignee
+		}
+		if patch.ClearDueDate {
+			task.DueDate = nil
+		} else if patch.DueDate != nil {
+			task.DueDate = patch.DueDate
+		}
+		if patch.Priority != nil {
+			if task.Priority, err = checkPriority(*patch.Priority); err != nil {
+				return err
+			}
+		}
+		if err := s.checkLocked(before, task); err != nil {
+			return err
+		}
+		if noop = s.isNoop(before, task, revived); noop {
+			return nil
+		}
+
+		if err := repo.Update(task); err != nil {
+			return fmt.Errorf("failed to update task in repository: %w", err)
+		}
+		return repo.AddAuditEntry(changeEntry(action, actor, before, task))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !noop {
+		s.publishUpdate(before, task)
+	}
+	return task, nil
+}
+
+// DeleteTask soft-deletes a task by its ID, recording the delete and req.Actor in the audit log
+// in the same transaction
+func (s *taskService) DeleteTask(id int, req *models.DeleteTaskRequest) error {
 	if id <= 0 {
 		return errors.New("invalid task ID")
 	}
-	err := s.repo.Delete(id)
+	actor, err := checkActor(req.Actor)
+	if err != nil {
+		return err
+	}
+	err = s.withTx(func(repo repository.TaskRepository) error {
+		if err := repo.Delete(id); err != nil {
+			return fmt.Errorf("failed to delete task from repository: %w", err)
+		}
+		return repo.AddAuditEntry(deleteEntry(actor, id))
+	})
+	if err != nil {
+		return err
+	}
+	s.publish(models.TaskEvent{Type: models.EventTaskDeleted, TaskID: id})
+	return nil
+}
+
+// DeleteTaskReturning soft-deletes a task like DeleteTask and returns it as it was just before
+// the delete, with DeletedAt set. The read and the delete share a transaction, so the task
+// returned is the one deleted.
+func (s *taskService) DeleteTaskReturning(id int, req *models.DeleteTaskRequest) (*models.Task, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid task ID")
+	}
+	actor, err := checkActor(req.Actor)
+	if err != nil {
+		return nil, err
+	}
+
+	var task *models.Task
+	err = s.withTx(func(repo repository.TaskRepository) (err error) {
+		task, err = repo.GetByID(id)
+		if err != nil {
+			return fmt.Errorf("task with ID %d not found: %w", id, err)
+		}
+		if err := repo.Delete(id); err != nil {
+			return fmt.Errorf("failed to delete task from repository: %w", err)
+		}
+		return repo.AddAuditEntry(deleteEntry(actor, id))
+	})
+	if err != nil {
+		return nil, err
+	}
+	deletedAt := s.now().UTC()
+	task.DeletedAt = &deletedAt
+	s.publish(models.TaskEvent{Type: models.EventTaskDeleted, TaskID: id})
+	return task, nil
+}
+
+// ClaimTask hands the oldest pending task to the requesting worker.
+// repository.ErrNoTaskAvailable is returned (wrapped) when the queue is empty.
+func (s *taskService) ClaimTask(req *models.ClaimTaskRequest) (*models.Task, error) {
+	if err := validateWorkerID(req.WorkerID, true); err != nil {
+		return nil, err
+	}
+	task, err := s.repo.Claim(req.WorkerID, s.leaseDuration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim task: %w", err)
+	}
+	return task, nil
+}
+
+// ReleaseTask puts a claimed task back in the queue
+func (s *taskService) ReleaseTask(id int, req *models.ReleaseTaskRequest) (*models.Task, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid task ID")
+	}
+	if err := validateWorkerID(req.WorkerID, false); err != nil {
+		return nil, err
+	}
+	task, err := s.repo.Release(id, req.WorkerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release task: %w", err)
+	}
+	return task, nil
+}
+
+// ReopenTask moves a completed task back to the configured open status, recording why, and by
+// whom, in the audit log in the same transaction. repository.ErrTaskNotCompleted is returned
+// (wrapped) if the task isn't completed.
+func (s *taskService) ReopenTask(id int, req *models.ReopenTaskRequest) (*models.Task, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid task ID")
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidReopenReason)
+	}
+	if len(reason) > MaxReopenReasonLength {
+		return nil, fmt.Errorf("%w: must not exceed %d characters", ErrInvalidReopenReason, MaxReopenReasonLength)
+	}
+	actor, err := checkActor(req.Actor)
+	if err != nil {
+		return nil, err
+	}
+
+	var task *models.Task
+	err = s.withTx(func(repo repository.TaskRepository) (err error) {
+		task, err = repo.Reopen(id, s.reopenStatus, reason)
+		if err != nil {
+			return fmt.Errorf("failed to reopen task: %w", err)
+		}
+		return repo.AddAuditEntry(&models.AuditEntry{
+			TaskID:     id,
+			Actor:      actor,
+			Action:     models.AuditActionReopen,
+			FromStatus: "completed",
+			ToStatus:   task.Status,
+			Note:       reason,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Only completed tasks are reopened, so the status is the one change clients can see
+	before := snapshot(task)
+	before.Status = "completed"
+	s.publishUpdate(before, task)
+	return task, nil
+}
+
+// AssignTask sets who a task is assigned to, or unassigns it when req.Assignee is nil. Only the
+// assignee changes; it goes through PatchTask so it is validated and published the same way,
+// and is recorded in the audit log with req.Actor.
+func (s *taskService) AssignTask(id int, req *models.AssignTaskRequest) (*models.Task, error) {
+	actor, err := checkActor(req.Actor)
+	if err != nil {
+		return nil, err
+	}
+	assignee := ""
+	if req.Assignee != nil {
+		if strings.TrimSpace(*req.Assignee) == "" {
+			return nil, fmt.Errorf("%w: must not be blank; use null to unassign", ErrInvalidAssignee)
+		}
+		assignee = *req.Assignee
+	}
+	return s.patchTask(id, &models.PatchTaskRequest{Assignee: &assignee}, models.AuditActionAssign, actor)
+}
+
+// ReassignTasks moves every live task assigned to req.From over to req.To, publishing an update
+// event for each, or one batch of them (see WithEventMode). Both must be valid assignees, and
+// different from each other.
+func (s *taskService) ReassignTasks(req *models.ReassignTasksRequest) (*models.ReassignTasksResponse, error) {
+	from, err := s.normalizeAssignee(req.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.normalizeAssignee(req.To)
+	if err != nil {
+		return nil, err
+	}
+	if from == "" || to == "" {
+		return nil, fmt.Errorf("%w: from and to are both required", ErrInvalidAssignee)
+	}
+	if from == to {
+		return nil, fmt.Errorf("%w: from and to must be different", ErrInvalidAssignee)
+	}
+	actor, err := checkActor(req.Actor)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []*models.Task
+	err = s.withTx(func(repo repository.TaskRepository) (err error) {
+		tasks, err = repo.Reassign(from, to)
+		if err != nil {
+			return fmt.Errorf("failed to reassign tasks in repository: %w", err)
+		}
+		note := fmt.Sprintf("assignee %s -> %s", from, to)
+		for _, task := range tasks {
+			entry := &models.AuditEntry{TaskID: task.ID, Actor: actor, Action: models.AuditActionReassign, Note: note}
+			if err := repo.AddAuditEntry(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	befores := make([]*models.Task, len(tasks))
+	for i, task := range tasks {
+		befores[i] = snapshot(task)
+		befores[i].Assignee = from
+	}
+	s.publishUpdates(befores, tasks)
+	return &models.ReassignTasksResponse{From: from, To: to, Moved: len(tasks)}, nil
+}
+
+// ReclaimExpiredLeases requeues claimed tasks whose lease ran out and returns how many there were
+func (s *taskService) ReclaimExpiredLeases() (int, error) {
+	ids, err := s.repo.ReclaimExpiredLeases()
 	if err != nil {
-		return fmt.Errorf("failed to delete task from repository: %w", err)
+		return 0, fmt.Errorf("failed to reclaim expired leases: %w", err)
+	}
+	return len(ids), nil
+}
+
+// validateWorkerID checks a worker ID's length, and its presence when required
+func validateWorkerID(workerID string, required bool) error {
+	if required && workerID == "" {
+		return fmt.Errorf("%w: worker_id is required", ErrInvalidWorkerID)
+	}
+	if len(workerID) > MaxWorkerIDLength {
+		return fmt.Errorf("%w: must not exceed %d characters", ErrInvalidWorkerID, MaxWorkerIDLength)
+	}
+	return nil
+}
+
+// validateDescription enforces MaxDescriptionLength, counted in characters like the database does
+func validateDescription(description string) error {
+	if utf8.RuneCountInString(description) > MaxDescriptionLength {
+		return fmt.Errorf("%w: must be at most %d characters", ErrInvalidDescription, MaxDescriptionLength)
+	}
+	return nil
+}
+
+// validateAssignee checks an already trimmed assignee in any format; empty means unassigned
+func validateAssignee(assignee string) error {
+	if len(assignee) > MaxAssigneeLength {
+		return fmt.Errorf("%w: must be at most %d bytes", ErrInvalidAssignee, MaxAssigneeLength)
+	}
+	// ?assignee=none lists unassigned tasks, so nobody can be called that
+	if strings.EqualFold(assignee, models.UnassignedFilterValue) {
+		return fmt.Errorf("%w: %q is reserved", ErrInvalidAssignee, assignee)
+	}
+	return nil
+}
+
+// validateMetadata checks that metadata is a flat object of scalar values within MaxMetadataBytes
+func validateMetadata(metadata map[string]interface{}) error {
+	for key, value := range metadata {
+		if key == "" {
+			return fmt.Errorf("%w: keys must not be empty", ErrInvalidMetadata)
+		}
+		switch value.(type) {
+		case string, float64, bool, nil:
+		default:
+			return fmt.Errorf("%w: value for %q must be a string, number, boolean or null", ErrInvalidMetadata, key)
+		}
+	}
+
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMetadata, err)
+	}
+	if len(encoded) > MaxMetadataBytes {
+		return fmt.Errorf("%w: must not exceed %d bytes", ErrInvalidMetadata, MaxMetadataBytes)
 	}
 	return nil
 }
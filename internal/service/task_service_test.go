@@ -1,14 +1,20 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/cliffdoyle/task-api/internal/repository"
 )
 
 // MockTaskRepository is a mock implementation of the TaskRepository interface.
@@ -17,6 +23,17 @@ type MockTaskRepository struct {
 	mock.Mock
 }
 
+// WithTransaction runs fn against the mock itself, so expectations set on the mock apply inside it
+func (m *MockTaskRepository) WithTransaction(ctx context.Context, fn func(repo repository.TaskRepository) error) error {
+	return fn(m)
+}
+
+// GetIDByUUID mocks the GetIDByUUID method of the repository
+func (m *MockTaskRepository) GetIDByUUID(uuid string) (int, error) {
+	args := m.Called(uuid)
+	return args.Int(0), args.Error(1)
+}
+
 // Create mocks the Create method of the repository
 func (m *MockTaskRepository) Create(task *models.Task) error {
 	args := m.Called(task)
@@ -29,6 +46,16 @@ func (m *MockTaskRepository) Create(task *models.Task) error {
 	return args.Error(0)
 }
 
+// CreateWithID mocks the CreateWithID method of the repository
+func (m *MockTaskRepository) CreateWithID(task *models.Task) error {
+	args := m.Called(task)
+	if args.Error(0) == nil {
+		task.CreatedAt = time.Now()
+		task.UpdatedAt = time.Now()
+	}
+	return args.Error(0)
+}
+
 // GetByID mocks the GetByID method of the repository
 func (m *MockTaskRepository) GetByID(id int) (*models.Task, error) {
 	args := m.Called(id)
@@ -39,8 +66,8 @@ func (m *MockTaskRepository) GetByID(id int) (*models.Task, error) {
 }
 
 // GetAll mocks the GetAll method of the repository
-func (m *MockTaskRepository) GetAll() ([]*models.Task, error) {
-	args := m.Called()
+func (m *MockTaskRepository) GetAll(filter models.ListFilter) ([]*models.Task, error) {
+	args := m.Called(filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -62,6 +89,215 @@ func (m *MockTaskRepository) Delete(id int) error {
 	return args.Error(0)
 }
 
+// Revive mocks the Revive method of the repository
+func (m *MockTaskRepository) Revive(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// GetSummaries mocks the GetSummaries method of the repository
+func (m *MockTaskRepository) GetSummaries(filter models.ListFilter) ([]*models.TaskSummary, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TaskSummary), args.Error(1)
+}
+
+// CountByDay mocks the CountByDay method of the repository
+func (m *MockTaskRepository) CountByDay(from, to time.Time) ([]*models.DailyMetrics, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DailyMetrics), args.Error(1)
+}
+
+// GetByTitle mocks the GetByTitle method of the repository
+func (m *MockTaskRepository) GetByTitle(title string) (*models.Task, error) {
+	args := m.Called(title)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// LockTitle mocks the LockTitle method of the repository
+func (m *MockTaskRepository) LockTitle(title string) error {
+	args := m.Called(title)
+	return args.Error(0)
+}
+
+// Reassign mocks the Reassign method of the repository
+func (m *MockTaskRepository) Reassign(from, to string) ([]*models.Task, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) EscalatePriorities(statuses []string, dueBefore models.Date) ([]*models.Task, error) {
+	args := m.Called(statuses, dueBefore)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Task), args.Error(1)
+}
+
+// AddAuditEntry mocks the AddAuditEntry method of the repository. Audit rows are written
+// alongside many changes, so a test only sees them when it sets an expectation for AddAuditEntry.
+func (m *MockTaskRepository) AddAuditEntry(entry *models.AuditEntry) error {
+	for _, call := range m.ExpectedCalls {
+		if call.Method == "AddAuditEntry" {
+			args := m.Called(entry)
+			return args.Error(0)
+		}
+	}
+	return nil
+}
+
+// RunMaintenance mocks the RunMaintenance method of the repository
+func (m *MockTaskRepository) RunMaintenance(ctx context.Context, op repository.MaintenanceOp) error {
+	args := m.Called(ctx, op)
+	return args.Error(0)
+}
+
+// CreateTemplate mocks the CreateTemplate method of the repository
+func (m *MockTaskRepository) CreateTemplate(tmpl *models.TaskTemplate) error {
+	args := m.Called(tmpl)
+	if args.Error(0) == nil {
+		tmpl.ID = 1 // Simulate DB assigning an ID
+	}
+	return args.Error(0)
+}
+
+// GetTemplate mocks the GetTemplate method of the repository
+func (m *MockTaskRepository) GetTemplate(id int) (*models.TaskTemplate, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaskTemplate), args.Error(1)
+}
+
+// ListTemplates mocks the ListTemplates method of the repository
+func (m *MockTaskRepository) ListTemplates() ([]*models.TaskTemplate, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TaskTemplate), args.Error(1)
+}
+
+// DeleteTemplate mocks the DeleteTemplate method of the repository
+func (m *MockTaskRepository) DeleteTemplate(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// GetByIDs mocks the GetByIDs method of the repository
+func (m *MockTaskRepository) GetByIDs(ids []int) ([]*models.Task, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Task), args.Error(1)
+}
+
+// GetChanges mocks the GetChanges method of the repository
+func (m *MockTaskRepository) GetChanges(after models.ChangesCursor, limit int) ([]*models.Task, error) {
+	args := m.Called(after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Task), args.Error(1)
+}
+
+// ChangesWatermark mocks the ChangesWatermark method of the repository
+func (m *MockTaskRepository) ChangesWatermark() (time.Time, error) {
+	args := m.Called()
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+// GetRecent mocks the GetRecent method of the repository
+func (m *MockTaskRepository) GetRecent(limit int) ([]*models.Task, error) {
+	args := m.Called(limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Task), args.Error(1)
+}
+
+// CountByAssignee mocks the CountByAssignee method of the repository
+func (m *MockTaskRepository) CountByAssignee(statuses []string) ([]*models.AssigneeWorkload, error) {
+	args := m.Called(statuses)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AssigneeWorkload), args.Error(1)
+}
+
+// GetOldestPending mocks the GetOldestPending method of the repository
+func (m *MockTaskRepository) GetOldestPending() (*models.Task, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// GetRandom mocks the GetRandom method of the repository
+func (m *MockTaskRepository) GetRandom(filter models.ListFilter, sample bool) (*models.Task, error) {
+	args := m.Called(filter, sample)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// Claim mocks the Claim method of the repository
+func (m *MockTaskRepository) Claim(workerID string, lease time.Duration) (*models.Task, error) {
+	args := m.Called(workerID, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// Release mocks the Release method of the repository
+func (m *MockTaskRepository) Release(id int, workerID string) (*models.Task, error) {
+	args := m.Called(id, workerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// Reopen mocks the Reopen method of the repository
+func (m *MockTaskRepository) Reopen(id int, status, reason string) (*models.Task, error) {
+	args := m.Called(id, status, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+// ReclaimExpiredLeases mocks the ReclaimExpiredLeases method of the repository
+func (m *MockTaskRepository) ReclaimExpiredLeases() ([]int, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
+}
+
+// Close mocks the Close method of the repository
+func (m *MockTaskRepository) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
 // --- Test Cases for CreateTask ---
 func TestCreateTask_Success(t *testing.T) {
 	// Arrange
@@ -111,6 +347,59 @@ func TestCreateTask_EmptyTitle(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestCreateTaskIfNotExists_ReturnsExisting(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	publisher := &recordingPublisher{}
+	service := NewTaskService(mockRepo, WithEventPublisher(publisher))
+	existing := &models.Task{ID: 4, Title: "deploy v2", Status: "in_progress"}
+	mockRepo.On("LockTitle", " Deploy V2 ").Return(nil)
+	mockRepo.On("GetByTitle", " Deploy V2 ").Return(existing, nil)
+
+	// Act
+	task, created, err := service.CreateTaskIfNotExists(&models.CreateTaskRequest{Title: " Deploy V2 ", Description: "ignored"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, existing, task)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	assert.Empty(t, publisher.events)
+}
+
+func TestCreateTaskIfNotExists_Creates(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	publisher := &recordingPublisher{}
+	service := NewTaskService(mockRepo, WithEventPublisher(publisher))
+	mockRepo.On("LockTitle", "Deploy").Return(nil)
+	mockRepo.On("GetByTitle", "Deploy").Return(nil, repository.ErrTaskNotFound)
+	mockRepo.On("Create", mock.MatchedBy(func(task *models.Task) bool { return task.Title == "Deploy" })).Return(nil)
+
+	// Act
+	task, created, err := service.CreateTaskIfNotExists(&models.CreateTaskRequest{Title: "Deploy"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, 1, task.ID)
+	assert.Equal(t, "pending", task.Status)
+	assert.Len(t, publisher.events, 1)
+}
+
+func TestCreateTaskIfNotExists_Invalid(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	// Act
+	_, _, err := service.CreateTaskIfNotExists(&models.CreateTaskRequest{Title: ""})
+
+	// Assert: validation runs before any lock is taken
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "LockTitle", mock.Anything)
+}
+
 func TestCreateTask_RepoError(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockTaskRepository)
@@ -181,6 +470,35 @@ func TestGetTask_NotFound(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+// A repository call abandoned because the request's context ended must still be recognisable
+// after the service wraps it, so handlers can answer 499 or 504 rather than 500
+func TestContextErrorsSurviveWrapping(t *testing.T) {
+	tests := map[string]error{
+		"cancelled": context.Canceled,
+		"deadline":  context.DeadlineExceeded,
+	}
+	for name, ctxErr := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			service := NewTaskService(mockRepo)
+			repoErr := fmt.Errorf("pq: %w", ctxErr)
+			mockRepo.On("GetByID", 1).Return(nil, repoErr)
+			mockRepo.On("GetAll", mock.Anything).Return(nil, repoErr)
+
+			// Act
+			_, getErr := service.GetTask(1)
+			_, listErr := service.GetAllTasks(models.ListFilter{})
+			_, updateErr := service.UpdateTask(1, &models.UpdateTaskRequest{Title: "Renamed", Status: "pending"})
+
+			// Assert
+			assert.ErrorIs(t, getErr, ctxErr)
+			assert.ErrorIs(t, listErr, ctxErr)
+			assert.ErrorIs(t, updateErr, ctxErr)
+		})
+	}
+}
+
 func TestGetTask_InvalidID(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockTaskRepository)
@@ -211,10 +529,10 @@ func TestGetAllTasks_Success(t *testing.T) {
 	expectedTasks := []*models.Task{task1, task2}
 
 	// Expect GetAll to be called and return the list of tasks
-	mockRepo.On("GetAll").Return(expectedTasks, nil)
+	mockRepo.On("GetAll", models.ListFilter{}).Return(expectedTasks, nil)
 
 	// Act
-	tasks, err := service.GetAllTasks()
+	tasks, err := service.GetAllTasks(models.ListFilter{})
 
 	// Assert
 	assert.NoError(t, err)
@@ -224,16 +542,36 @@ func TestGetAllTasks_Success(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestGetAllTasks_NilFromRepoEncodesAsEmptyArray(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	// A repository returning a nil slice must not leak through as JSON null
+	mockRepo.On("GetAll", models.ListFilter{}).Return([]*models.Task(nil), nil)
+
+	// Act
+	tasks, err := service.GetAllTasks(models.ListFilter{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, tasks)
+	body, err := json.Marshal(tasks)
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", string(body))
+	mockRepo.AssertExpectations(t)
+}
+
 func TestGetAllTasks_RepoError(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockTaskRepository)
 	service := NewTaskService(mockRepo)
 
 	repoError := errors.New("failed to fetch from database")
-	mockRepo.On("GetAll").Return(nil, repoError)
+	mockRepo.On("GetAll", models.ListFilter{}).Return(nil, repoError)
 
 	// Act
-	tasks, err := service.GetAllTasks()
+	tasks, err := service.GetAllTasks(models.ListFilter{})
 
 	// Assert
 	assert.Error(t, err)
@@ -347,6 +685,248 @@ func TestUpdateTask_NotFound(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestUpdateTask_DeletedTask(t *testing.T) {
+	// Arrange: the default leaves deleted tasks deleted
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	mockRepo.On("GetByID", 5).Return(nil, repository.ErrTaskNotFound)
+
+	// Act
+	_, err := service.UpdateTask(5, &models.UpdateTaskRequest{Title: "Back"})
+
+	// Assert
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+	mockRepo.AssertNotCalled(t, "Revive", mock.Anything)
+}
+
+func TestUpdateTask_ReviveOnUpdate(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, WithReviveOnUpdate(true))
+	mockRepo.On("GetByID", 5).Return(nil, repository.ErrTaskNotFound).Once()
+	mockRepo.On("Revive", 5).Return(nil)
+	mockRepo.On("GetByID", 5).Return(&models.Task{ID: 5, Title: "Gone", Status: "pending"}, nil).Once()
+	mockRepo.On("Update", mock.MatchedBy(func(task *models.Task) bool { return task.Title == "Back" })).Return(nil)
+
+	// Act
+	task, err := service.UpdateTask(5, &models.UpdateTaskRequest{Title: "Back"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Back", task.Title)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPutTask(t *testing.T) {
+	tests := map[string]struct {
+		req         models.UpdateTaskRequest
+		existing    *models.Task
+		createErr   error
+		wantCreated bool
+		wantStatus  string
+		wantErr     error
+	}{
+		"updates an existing task": {
+			req:        models.UpdateTaskRequest{Title: "Renamed"},
+			existing:   &models.Task{ID: 42, Title: "Old", Status: "in_progress"},
+			wantStatus: "in_progress",
+		},
+		"creates a missing task": {
+			req:         models.UpdateTaskRequest{Title: "New", Status: "in_progress"},
+			wantCreated: true,
+			wantStatus:  "in_progress",
+		},
+		"defaults to pending": {
+			req:         models.UpdateTaskRequest{Title: "New"},
+			wantCreated: true,
+			wantStatus:  "pending",
+		},
+		"create needs a title": {
+			req:     models.UpdateTaskRequest{Status: "pending"},
+			wantErr: ErrTitleRequired,
+		},
+		"deleted ID": {
+			req:       models.UpdateTaskRequest{Title: "New"},
+			createErr: repository.ErrTaskIDTaken,
+			wantErr:   repository.ErrTaskIDTaken,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			service := NewTaskService(mockRepo)
+			if tc.existing != nil {
+				mockRepo.On("GetByID", 42).Return(tc.existing, nil)
+				mockRepo.On("Update", mock.AnythingOfType("*models.Task")).Return(nil)
+			} else {
+				mockRepo.On("GetByID", 42).Return(nil, repository.ErrTaskNotFound)
+				mockRepo.On("CreateWithID", mock.MatchedBy(func(task *models.Task) bool { return task.ID == 42 })).Return(tc.createErr)
+			}
+
+			// Act
+			task, created, err := service.PutTask(42, &tc.req)
+
+			// Assert
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Nil(t, task)
+				assert.False(t, created)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantCreated, created)
+			assert.Equal(t, 42, task.ID)
+			assert.Equal(t, tc.req.Title, task.Title)
+			assert.Equal(t, tc.wantStatus, task.Status)
+		})
+	}
+}
+
+func TestPutTask_CreateFollowsWorkflow(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, WithTransitions(Transitions{"pending": {"in_progress"}, "in_progress": {"completed"}}))
+	mockRepo.On("GetByID", 42).Return(nil, repository.ErrTaskNotFound)
+
+	// Act
+	_, created, err := service.PutTask(42, &models.UpdateTaskRequest{Title: "New", Status: "completed"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+	assert.False(t, created)
+	mockRepo.AssertNotCalled(t, "CreateWithID", mock.Anything)
+}
+
+func TestPatchTask_ReviveOnUpdateMissingTask(t *testing.T) {
+	// Arrange: nothing, deleted or not, has the ID
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, WithReviveOnUpdate(true))
+	mockRepo.On("GetByID", 5).Return(nil, repository.ErrTaskNotFound)
+	mockRepo.On("Revive", 5).Return(repository.ErrTaskNotFound)
+	title := "Back"
+
+	// Act
+	_, err := service.PatchTask(5, &models.PatchTaskRequest{Title: &title})
+
+	// Assert
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestUpdateTask_LockCompleted(t *testing.T) {
+	completed := func() *models.Task {
+		return &models.Task{ID: 1, Title: "Done", Description: "Shipped", Status: "completed"}
+	}
+	title := "Renamed"
+	pending := "pending"
+
+	tests := map[string]struct {
+		lock    bool
+		update  func(s TaskService) (*models.Task, error)
+		wantErr bool
+	}{
+		"put edits a locked task": {
+			lock:    true,
+			update:  func(s TaskService) (*models.Task, error) { return s.UpdateTask(1, &models.UpdateTaskRequest{Title: title}) },
+			wantErr: true,
+		},
+		"patch edits a locked task": {
+			lock:    true,
+			update:  func(s TaskService) (*models.Task, error) { return s.PatchTask(1, &models.PatchTaskRequest{Title: &title}) },
+			wantErr: true,
+		},
+		"reopen alongside an edit": {
+			lock: true,
+			update: func(s TaskService) (*models.Task, error) {
+				return s.PatchTask(1, &models.PatchTaskRequest{Title: &title, Status: &pending})
+			},
+			wantErr: true,
+		},
+		"status-only reopen": {
+			lock:   true,
+			update: func(s TaskService) (*models.Task, error) { return s.PatchTask(1, &models.PatchTaskRequest{Status: &pending}) },
+		},
+		"lock off": {
+			update: func(s TaskService) (*models.Task, error) { return s.UpdateTask(1, &models.UpdateTaskRequest{Title: title}) },
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			service := NewTaskService(mockRepo, WithLockCompleted(tc.lock))
+			mockRepo.On("GetByID", 1).Return(completed(), nil)
+			mockRepo.On("Update", mock.AnythingOfType("*models.Task")).Return(nil)
+
+			// Act
+			_, err := tc.update(service)
+
+			// Assert
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrTaskLocked)
+				assert.Contains(t, err.Error(), "title")
+				mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			mockRepo.AssertCalled(t, "Update", mock.AnythingOfType("*models.Task"))
+		})
+	}
+}
+
+func TestUpdateTask_CaseInsensitiveStatus(t *testing.T) {
+	tests := map[string]struct {
+		fold   bool
+		update func(s TaskService) (*models.Task, error)
+		want   string // stored status; empty when the update is rejected
+	}{
+		"put folds": {
+			fold:   true,
+			update: func(s TaskService) (*models.Task, error) { return s.UpdateTask(1, &models.UpdateTaskRequest{Status: "In_Progress"}) },
+			want:   "in_progress",
+		},
+		"patch folds": {
+			fold: true,
+			update: func(s TaskService) (*models.Task, error) {
+				status := "COMPLETED"
+				return s.PatchTask(1, &models.PatchTaskRequest{Status: &status})
+			},
+			want: "completed",
+		},
+		"unknown status still rejected": {
+			fold:   true,
+			update: func(s TaskService) (*models.Task, error) { return s.UpdateTask(1, &models.UpdateTaskRequest{Status: "Done"}) },
+		},
+		"exact case required when off": {
+			update: func(s TaskService) (*models.Task, error) { return s.UpdateTask(1, &models.UpdateTaskRequest{Status: "Pending"}) },
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			service := NewTaskService(mockRepo, WithCaseInsensitiveStatus(tc.fold))
+			mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Status: "pending"}, nil)
+			mockRepo.On("Update", mock.AnythingOfType("*models.Task")).Return(nil)
+
+			// Act
+			task, err := tc.update(service)
+
+			// Assert
+			if tc.want == "" {
+				assert.Error(t, err)
+				mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, task.Status)
+		})
+	}
+}
+
 // --- Test Cases for DeleteTask ---
 func TestDeleteTask_Success(t *testing.T) {
 	// Arrange
@@ -354,9 +934,10 @@ func TestDeleteTask_Success(t *testing.T) {
 	service := NewTaskService(mockRepo)
 
 	mockRepo.On("Delete", 1).Return(nil) // Expect Delete with ID 1 to succeed
+	mockRepo.On("AddAuditEntry", &models.AuditEntry{TaskID: 1, Actor: "alice", Action: models.AuditActionDelete}).Return(nil)
 
 	// Act
-	err := service.DeleteTask(1)
+	err := service.DeleteTask(1, &models.DeleteTaskRequest{Actor: " alice "})
 
 	// Assert
 	assert.NoError(t, err)
@@ -368,19 +949,48 @@ func TestDeleteTask_NotFound(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
 	service := NewTaskService(mockRepo)
 
-	repoError := fmt.Errorf("task with ID %d not found for deletion", 99)
+	repoError := fmt.Errorf("task with ID %d not found for deletion: %w", 99, repository.ErrTaskNotFound)
 	mockRepo.On("Delete", 99).Return(repoError)
 
 	// Act
-	err := service.DeleteTask(99)
+	err := service.DeleteTask(99, &models.DeleteTaskRequest{})
 
 	// Assert
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to delete task from repository")
-	assert.True(t, errors.Is(err, repoError))
+	assert.True(t, errors.Is(err, repository.ErrTaskNotFound), "handlers map it to 404")
 	mockRepo.AssertExpectations(t)
 }
 
+func TestDeleteTask_AuditFailureKeepsTask(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	events := &recordingPublisher{}
+	service := NewTaskService(mockRepo, WithEventPublisher(events))
+	mockRepo.On("Delete", 1).Return(nil)
+	mockRepo.On("AddAuditEntry", mock.Anything).Return(errors.New("audit write failed"))
+
+	// Act
+	err := service.DeleteTask(1, &models.DeleteTaskRequest{Actor: "alice"})
+
+	// Assert: the transaction rolls the delete back, so nothing is announced
+	assert.Error(t, err)
+	assert.Empty(t, events.events)
+}
+
+func TestDeleteTask_InvalidActor(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	// Act
+	err := service.DeleteTask(1, &models.DeleteTaskRequest{Actor: strings.Repeat("a", MaxActorLength+1)})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidActor)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
 func TestDeleteTask_InvalidID(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockTaskRepository)
@@ -389,10 +999,1522 @@ func TestDeleteTask_InvalidID(t *testing.T) {
 	mockRepo.AssertNotCalled(t, "Delete") // Repository method should not be called
 
 	// Act
-	err := service.DeleteTask(0) // Invalid ID
+	err := service.DeleteTask(0, &models.DeleteTaskRequest{}) // Invalid ID
 
 	// Assert
 	assert.Error(t, err)
 	assert.Equal(t, "invalid task ID", err.Error())
 	mockRepo.AssertExpectations(t)
 }
+
+func TestDeleteTaskReturning_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	svc := NewTaskService(mockRepo).(*taskService)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "Gone", Status: "pending"}, nil)
+	mockRepo.On("Delete", 1).Return(nil)
+	mockRepo.On("AddAuditEntry", &models.AuditEntry{TaskID: 1, Actor: "bob", Action: models.AuditActionDelete}).Return(nil)
+
+	// Act
+	task, err := svc.DeleteTaskReturning(1, &models.DeleteTaskRequest{Actor: "bob"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Gone", task.Title)
+	if assert.NotNil(t, task.DeletedAt) {
+		assert.Equal(t, now, *task.DeletedAt)
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteTaskReturning_NotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	mockRepo.On("GetByID", 99).Return(nil, repository.ErrTaskNotFound)
+
+	// Act
+	task, err := service.DeleteTaskReturning(99, &models.DeleteTaskRequest{})
+
+	// Assert
+	assert.Nil(t, task)
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+	mockRepo.AssertNotCalled(t, "Delete", 99)
+}
+
+// --- Test Cases for metadata validation ---
+func TestCreateTask_WithMetadata(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	req := &models.CreateTaskRequest{
+		Title:    "Task with metadata",
+		Metadata: map[string]interface{}{"team": "backend", "points": float64(3), "urgent": true},
+	}
+	mockRepo.On("Create", mock.AnythingOfType("*models.Task")).Return(nil)
+
+	// Act
+	task, err := service.CreateTask(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "backend", task.Metadata["team"])
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRequireDescription(t *testing.T) {
+	blank := "  "
+	tests := map[string]struct {
+		required bool
+		call     func(s TaskService) error
+		wantErr  bool
+	}{
+		"create without, optional": {
+			call: func(s TaskService) error { _, err := s.CreateTask(&models.CreateTaskRequest{Title: "T"}); return err },
+		},
+		"create without, required": {
+			required: true,
+			call:     func(s TaskService) error { _, err := s.CreateTask(&models.CreateTaskRequest{Title: "T"}); return err },
+			wantErr:  true,
+		},
+		"create blank, required": {
+			required: true,
+			call: func(s TaskService) error {
+				_, err := s.CreateTask(&models.CreateTaskRequest{Title: "T", Description: blank})
+				return err
+			},
+			wantErr: true,
+		},
+		"create with, required": {
+			required: true,
+			call: func(s TaskService) error {
+				_, err := s.CreateTask(&models.CreateTaskRequest{Title: "T", Description: "Why"})
+				return err
+			},
+		},
+		"put omitting description, required": {
+			required: true,
+			call:     func(s TaskService) error { _, err := s.UpdateTask(1, &models.UpdateTaskRequest{Title: "New"}); return err },
+		},
+		"put blank description, required": {
+			required: true,
+			call:     func(s TaskService) error { _, err := s.UpdateTask(1, &models.UpdateTaskRequest{Description: blank}); return err },
+			wantErr:  true,
+		},
+		"patch omitting description, required": {
+			required: true,
+			call: func(s TaskService) error {
+				title := "New"
+				_, err := s.PatchTask(1, &models.PatchTaskRequest{Title: &title})
+				return err
+			},
+		},
+		"patch clearing description, required": {
+			required: true,
+			call: func(s TaskService) error {
+				empty := ""
+				_, err := s.PatchTask(1, &models.PatchTaskRequest{Description: &empty})
+				return err
+			},
+			wantErr: true,
+		},
+		"patch clearing description, optional": {
+			call: func(s TaskService) error {
+				empty := ""
+				_, err := s.PatchTask(1, &models.PatchTaskRequest{Description: &empty})
+				return err
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			service := NewTaskService(mockRepo, WithRequireDescription(tc.required))
+			mockRepo.On("Create", mock.AnythingOfType("*models.Task")).Return(nil)
+			mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Description: "Old", Status: "pending"}, nil)
+			mockRepo.On("Update", mock.AnythingOfType("*models.Task")).Return(nil)
+
+			// Act
+			err := tc.call(service)
+
+			// Assert
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidDescription)
+				assert.Contains(t, err.Error(), "description is required")
+				mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+				mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestDescriptionLength(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Task")).Return(nil)
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Status: "pending"}, nil)
+	atLimit := strings.Repeat("é", MaxDescriptionLength) // counted in characters, not bytes
+	tooLong := atLimit + "x"
+
+	// Act & Assert
+	_, err := service.CreateTask(&models.CreateTaskRequest{Title: "T", Description: atLimit})
+	assert.NoError(t, err)
+	_, err = service.CreateTask(&models.CreateTaskRequest{Title: "T", Description: tooLong})
+	assert.ErrorIs(t, err, ErrInvalidDescription)
+	_, err = service.UpdateTask(1, &models.UpdateTaskRequest{Description: tooLong})
+	assert.ErrorIs(t, err, ErrInvalidDescription)
+	_, err = service.PatchTask(1, &models.PatchTaskRequest{Description: &tooLong})
+	assert.ErrorIs(t, err, ErrInvalidDescription)
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestCreateTask_NestedMetadata(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	req := &models.CreateTaskRequest{
+		Title:    "Nested metadata",
+		Metadata: map[string]interface{}{"owner": map[string]interface{}{"name": "alice"}},
+	}
+
+	// Act
+	task, err := service.CreateTask(req)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, task)
+	assert.True(t, errors.Is(err, ErrInvalidMetadata))
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestCreateTask_MetadataTooLarge(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	req := &models.CreateTaskRequest{
+		Title:    "Large metadata",
+		Metadata: map[string]interface{}{"blob": strings.Repeat("x", MaxMetadataBytes)},
+	}
+
+	// Act
+	task, err := service.CreateTask(req)
+
+	// Assert
+	assert.Nil(t, task)
+	assert.True(t, errors.Is(err, ErrInvalidMetadata))
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestUpdateTask_ReplacesMetadata(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	existingTask := &models.Task{
+		ID: 1, Title: "Title", Status: "pending",
+		Metadata: map[string]interface{}{"team": "backend", "sprint": "12"},
+	}
+	updateReq := &models.UpdateTaskRequest{Metadata: map[string]interface{}{"team": "frontend"}}
+
+	mockRepo.On("GetByID", 1).Return(existingTask, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task")).Return(nil)
+
+	// Act
+	updatedTask, err := service.UpdateTask(1, updateReq)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"team": "frontend"}, updatedTask.Metadata)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetAllTasks_PassesFilter(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	filter := models.ListFilter{Metadata: map[string]string{"team": "backend"}}
+	mockRepo.On("GetAll", filter).Return([]*models.Task{}, nil)
+
+	// Act
+	tasks, err := service.GetAllTasks(filter)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, tasks)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetAllTasks_ListMaxAge(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	windowStart := now.Add(-72 * time.Hour)
+	explicit := now.Add(-time.Hour)
+	cases := map[string]struct {
+		maxAge time.Duration
+		filter models.ListFilter
+		want   models.ListFilter
+	}{
+		"disabled":        {0, models.ListFilter{}, models.ListFilter{}},
+		"applied":         {72 * time.Hour, models.ListFilter{Assignee: "bob"}, models.ListFilter{Assignee: "bob", CreatedAfter: &windowStart}},
+		"all":             {72 * time.Hour, models.ListFilter{All: true}, models.ListFilter{All: true}},
+		"explicit after":  {72 * time.Hour, models.ListFilter{CreatedAfter: &explicit}, models.ListFilter{CreatedAfter: &explicit}},
+		"explicit before": {72 * time.Hour, models.ListFilter{CreatedBefore: &explicit}, models.ListFilter{CreatedBefore: &explicit}},
+		"updated after":   {72 * time.Hour, models.ListFilter{UpdatedBy: "alice", UpdatedAfter: &explicit}, models.ListFilter{UpdatedBy: "alice", UpdatedAfter: &explicit}},
+		"updated by only": {72 * time.Hour, models.ListFilter{UpdatedBy: "alice"}, models.ListFilter{UpdatedBy: "alice", CreatedAfter: &windowStart}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			svc := NewTaskService(mockRepo, WithListMaxAge(tc.maxAge)).(*taskService)
+			svc.now = func() time.Time { return now }
+			mockRepo.On("GetAll", tc.want).Return([]*models.Task{}, nil)
+			mockRepo.On("GetSummaries", tc.want).Return([]*models.TaskSummary{}, nil)
+
+			// Act
+			_, err := svc.GetAllTasks(tc.filter)
+			_, summaryErr := svc.ListTaskSummaries(tc.filter)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.NoError(t, summaryErr)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetAllTasks_SortDirections(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	svc := NewTaskService(mockRepo, WithSortDirections(map[models.SortField]models.SortOrder{
+		models.SortByDueDate:   models.SortNewestFirst,
+		models.SortByCreatedAt: "sideways",
+	}))
+	for field, want := range map[models.SortField]models.SortOrder{
+		models.SortByDueDate:     models.SortNewestFirst,
+		models.SortByCreatedAt:   models.SortOldestFirst,
+		models.SortByCompletedAt: models.SortNewestFirst,
+	} {
+		mockRepo.On("GetAll", models.ListFilter{SortBy: field, Order: want}).Return([]*models.Task{}, nil).Once()
+
+		// Act
+		_, err := svc.GetAllTasks(models.ListFilter{SortBy: field})
+
+		// Assert
+		assert.NoError(t, err, field)
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetAllTasks_DefaultListOrder(t *testing.T) {
+	cases := map[string]struct {
+		order  models.SortOrder
+		filter models.ListFilter
+		want   models.ListFilter
+	}{
+		"unset":                    {"", models.ListFilter{}, models.ListFilter{}},
+		"newest first":             {models.SortNewestFirst, models.ListFilter{}, models.ListFilter{Order: models.SortNewestFirst}},
+		"oldest first":             {models.SortOldestFirst, models.ListFilter{}, models.ListFilter{Order: models.SortOldestFirst}},
+		"request wins":             {models.SortOldestFirst, models.ListFilter{Order: models.SortNewestFirst}, models.ListFilter{Order: models.SortNewestFirst}},
+		"unknown order is ignored": {"sideways", models.ListFilter{}, models.ListFilter{}},
+		"field default direction":  {models.SortNewestFirst, models.ListFilter{SortBy: models.SortByDueDate}, models.ListFilter{SortBy: models.SortByDueDate, Order: models.SortOldestFirst}},
+		"field direction given":    {"", models.ListFilter{SortBy: models.SortByCreatedAt, Order: models.SortNewestFirst}, models.ListFilter{SortBy: models.SortByCreatedAt, Order: models.SortNewestFirst}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			svc := NewTaskService(mockRepo, WithDefaultListOrder(tc.order))
+			mockRepo.On("GetAll", tc.want).Return([]*models.Task{}, nil)
+			mockRepo.On("GetSummaries", tc.want).Return([]*models.TaskSummary{}, nil)
+
+			// Act
+			_, err := svc.GetAllTasks(tc.filter)
+			_, summaryErr := svc.ListTaskSummaries(tc.filter)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.NoError(t, summaryErr)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// --- Test Cases for ClaimTask / ReleaseTask ---
+func TestClaimTask_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, WithLeaseDuration(time.Minute))
+
+	worker := "worker-1"
+	claimed := &models.Task{ID: 1, Title: "Job", Status: "in_progress", ClaimedBy: &worker}
+	mockRepo.On("Claim", "worker-1", time.Minute).Return(claimed, nil)
+
+	// Act
+	task, err := service.ClaimTask(&models.ClaimTaskRequest{WorkerID: "worker-1"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "in_progress", task.Status)
+	assert.Equal(t, "worker-1", *task.ClaimedBy)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestClaimTask_DefaultLease(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	mockRepo.On("Claim", "worker-1", DefaultLeaseDuration).Return(&models.Task{ID: 1}, nil)
+
+	// Act
+	_, err := service.ClaimTask(&models.ClaimTaskRequest{WorkerID: "worker-1"})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestClaimTask_MissingWorkerID(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	// Act
+	task, err := service.ClaimTask(&models.ClaimTaskRequest{})
+
+	// Assert
+	assert.Nil(t, task)
+	assert.True(t, errors.Is(err, ErrInvalidWorkerID))
+	mockRepo.AssertNotCalled(t, "Claim", mock.Anything, mock.Anything)
+}
+
+func TestClaimTask_QueueEmpty(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	mockRepo.On("Claim", "worker-1", DefaultLeaseDuration).Return(nil, repository.ErrNoTaskAvailable)
+
+	// Act
+	task, err := service.ClaimTask(&models.ClaimTaskRequest{WorkerID: "worker-1"})
+
+	// Assert
+	assert.Nil(t, task)
+	assert.True(t, errors.Is(err, repository.ErrNoTaskAvailable))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReleaseTask_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	mockRepo.On("Release", 1, "worker-1").Return(&models.Task{ID: 1, Status: "pending"}, nil)
+
+	// Act
+	task, err := service.ReleaseTask(1, &models.ReleaseTaskRequest{WorkerID: "worker-1"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", task.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReleaseTask_NotClaimed(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	mockRepo.On("Release", 1, "").Return(nil, repository.ErrTaskNotClaimed)
+
+	// Act
+	task, err := service.ReleaseTask(1, &models.ReleaseTaskRequest{})
+
+	// Assert
+	assert.Nil(t, task)
+	assert.True(t, errors.Is(err, repository.ErrTaskNotClaimed))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReclaimExpiredLeases_ReturnsCount(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	mockRepo.On("ReclaimExpiredLeases").Return([]int{3, 5}, nil)
+
+	// Act
+	count, err := service.ReclaimExpiredLeases()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	mockRepo.AssertExpectations(t)
+}
+
+// --- Test Cases for BatchGetTasks ---
+func TestBatchGetTasks_ReportsMissing(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	mockRepo.On("GetByIDs", []int{3, 1, 99}).Return([]*models.Task{{ID: 1}, {ID: 3}}, nil)
+
+	// Act
+	result, err := service.BatchGetTasks(&models.BatchGetRequest{IDs: []int{3, 1, 99, 3}})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, result.Found, 2)
+	assert.Equal(t, []int{99}, result.Missing)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBatchGetTasks_NoneMissing(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	mockRepo.On("GetByIDs", []int{1}).Return([]*models.Task{{ID: 1}}, nil)
+
+	// Act
+	result, err := service.BatchGetTasks(&models.BatchGetRequest{IDs: []int{1}})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, result.Missing, "missing must encode as [] rather than null")
+	assert.Empty(t, result.Missing)
+}
+
+func TestBatchGetTasks_InvalidIDs(t *testing.T) {
+	tooMany := make([]int, MaxBatchGetIDs+1)
+	for i := range tooMany {
+		tooMany[i] = i + 1
+	}
+
+	cases := map[string][]int{
+		"empty":    {},
+		"zero":     {1, 0},
+		"negative": {-4},
+		"too many": tooMany,
+	}
+	for name, ids := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			service := NewTaskService(mockRepo)
+
+			// Act
+			result, err := service.BatchGetTasks(&models.BatchGetRequest{IDs: ids})
+
+			// Assert
+			assert.Nil(t, result)
+			assert.True(t, errors.Is(err, ErrInvalidBatch))
+			mockRepo.AssertNotCalled(t, "GetByIDs", mock.Anything)
+		})
+	}
+}
+
+func TestBatchGetTasks_DuplicateIDs(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	lenient := NewTaskService(mockRepo)
+	strict := NewTaskService(mockRepo, WithBatchLimits(0, true))
+	mockRepo.On("GetByIDs", []int{1, 2}).Return([]*models.Task{{ID: 1}, {ID: 2}}, nil)
+
+	// Act
+	result, err := lenient.BatchGetTasks(&models.BatchGetRequest{IDs: []int{1, 1, 2}})
+	_, strictErr := strict.BatchGetTasks(&models.BatchGetRequest{IDs: []int{1, 1, 2, 2, 1}})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, result.Found, 2)
+	assert.True(t, errors.Is(strictErr, ErrInvalidBatch))
+	assert.EqualError(t, strictErr, "invalid batch: duplicate ids [1 2]")
+	mockRepo.AssertNumberOfCalls(t, "GetByIDs", 1)
+}
+
+func TestBatchGetTasks_ConfiguredMax(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, WithBatchLimits(2, false))
+
+	// Act
+	result, err := service.BatchGetTasks(&models.BatchGetRequest{IDs: []int{1, 2, 3}})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "invalid batch: at most 2 ids may be requested")
+}
+
+// --- Test Cases for GetChanges ---
+func TestGetChanges_FirstPageWithMore(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	svc := NewTaskService(mockRepo)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mockRepo.On("ChangesWatermark").Return(now.In(time.FixedZone("EAT", 3*60*60)), nil)
+
+	since := now.Add(-time.Hour)
+	deletedAt := now.Add(-time.Minute)
+	t1 := &models.Task{ID: 1, UpdatedAt: since.Add(time.Second)}
+	t2 := &models.Task{ID: 2, UpdatedAt: since.Add(2 * time.Second), DeletedAt: &deletedAt}
+	t3 := &models.Task{ID: 3, UpdatedAt: since.Add(3 * time.Second)}
+	mockRepo.On("GetChanges", models.ChangesCursor{UpdatedAt: since, ID: math.MaxInt32}, 3).
+		Return([]*models.Task{t1, t2, t3}, nil)
+
+	// Act
+	resp, err := svc.GetChanges(since, "", 2)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, resp.Changes, 2)
+	assert.False(t, resp.Changes[0].Deleted)
+	assert.True(t, resp.Changes[1].Deleted)
+	assert.Equal(t, now, resp.ServerTime, "server_time is the database watermark, in UTC")
+	assert.NotEmpty(t, resp.NextCursor)
+
+	cursor, err := decodeChangesCursor(resp.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cursor.ID)
+	assert.True(t, t2.UpdatedAt.Equal(cursor.UpdatedAt))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetChanges_ContinuesFromCursor(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	position := models.ChangesCursor{UpdatedAt: time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC), ID: 42}
+	mockRepo.On("ChangesWatermark").Return(time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC), nil)
+	mockRepo.On("GetChanges", position, MaxChangesLimit+1).Return([]*models.Task{}, nil)
+
+	// Act
+	resp, err := service.GetChanges(time.Time{}, encodeChangesCursor(position), 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Changes)
+	assert.Empty(t, resp.NextCursor, "no cursor on the last page")
+	mockRepo.AssertExpectations(t)
+}
+
+// keysetRepo serves GetChanges from memory with the same (updated_at, id) keyset semantics
+// as the SQL query
+type keysetRepo struct {
+	repository.TaskRepository
+	tasks []*models.Task
+}
+
+func (r *keysetRepo) ChangesWatermark() (time.Time, error) {
+	return time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), nil
+}
+
+func (r *keysetRepo) GetChanges(after models.ChangesCursor, limit int) ([]*models.Task, error) {
+	sorted := append([]*models.Task(nil), r.tasks...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].UpdatedAt.Equal(sorted[j].UpdatedAt) {
+			return sorted[i].UpdatedAt.Before(sorted[j].UpdatedAt)
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	var page []*models.Task
+	for _, task := range sorted {
+		if task.UpdatedAt.After(after.UpdatedAt) || (task.UpdatedAt.Equal(after.UpdatedAt) && task.ID > after.ID) {
+			page = append(page, task)
+		}
+		if len(page) == limit {
+			break
+		}
+	}
+	return page, nil
+}
+
+func TestGetChanges_SharedTimestampsPageExactlyOnce(t *testing.T) {
+	// Arrange: 23 tasks in three timestamp groups, inserted out of ID order
+	same := time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC)
+	repo := &keysetRepo{}
+	for id := 23; id >= 1; id-- {
+		repo.tasks = append(repo.tasks, &models.Task{ID: id, UpdatedAt: same.Add(time.Duration(id%3) * time.Microsecond)})
+	}
+	service := NewTaskService(repo)
+
+	// Act: page through four at a time
+	seen := map[int]int{}
+	cursor := ""
+	for page := 0; page < 10; page++ {
+		resp, err := service.GetChanges(same.Add(-time.Second), cursor, 4)
+		assert.NoError(t, err)
+		for _, change := range resp.Changes {
+			seen[change.ID]++
+		}
+		if resp.NextCursor == "" {
+			break
+		}
+		cursor = resp.NextCursor
+	}
+
+	// Assert
+	assert.Len(t, seen, 23, "no task skipped")
+	for id, count := range seen {
+		assert.Equal(t, 1, count, "task %d repeated", id)
+	}
+}
+
+// --- Test Cases for OldestPendingTask ---
+func TestOldestPendingTask_Age(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	svc := NewTaskService(mockRepo).(*taskService)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	task := &models.Task{ID: 4, Status: "pending", CreatedAt: now.Add(-90*time.Minute - 500*time.Millisecond)}
+	mockRepo.On("GetOldestPending").Return(task, nil)
+
+	// Act
+	resp, err := svc.OldestPendingTask()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Same(t, task, resp.Task)
+	assert.Equal(t, int64(5400), resp.AgeSeconds)
+}
+
+func TestOldestPendingTask_NonePending(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	mockRepo.On("GetOldestPending").Return(nil, repository.ErrNoTaskAvailable)
+
+	// Act
+	resp, err := service.OldestPendingTask()
+
+	// Assert
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, repository.ErrNoTaskAvailable))
+}
+
+func TestRandomTask(t *testing.T) {
+	cases := map[string]struct {
+		opts   []Option
+		sample bool
+	}{
+		"shuffle by default": {nil, false},
+		"sampling":           {[]Option{WithRandomSampling(true)}, true},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange: the list age window never narrows the pick
+			mockRepo := new(MockTaskRepository)
+			svc := NewTaskService(mockRepo, append(tc.opts, WithListMaxAge(time.Hour))...)
+			task := &models.Task{ID: 4, Title: "Pick", Status: "pending"}
+			want := models.ListFilter{Statuses: []string{"pending"}, All: true}
+			mockRepo.On("GetRandom", want, tc.sample).Return(task, nil)
+
+			// Act
+			got, err := svc.RandomTask(models.ListFilter{Statuses: []string{"pending"}})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Same(t, task, got)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRandomTask_NoneMatch(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	svc := NewTaskService(mockRepo)
+	mockRepo.On("GetRandom", models.ListFilter{All: true}, false).Return(nil, repository.ErrNoTaskAvailable)
+
+	// Act
+	task, err := svc.RandomTask(models.ListFilter{})
+
+	// Assert
+	assert.Nil(t, task)
+	assert.ErrorIs(t, err, repository.ErrNoTaskAvailable)
+}
+
+// --- Test Cases for GetRecentTasks ---
+func TestGetRecentTasks_Limits(t *testing.T) {
+	cases := map[string]struct {
+		opts      []Option
+		requested int
+		want      int
+	}{
+		"default":             {nil, 0, DefaultRecentLimit},
+		"within max":          {nil, 40, 40},
+		"capped":              {nil, 500, MaxRecentLimit},
+		"configured default":  {[]Option{WithRecentLimits(25, 50)}, 0, 25},
+		"configured cap":      {[]Option{WithRecentLimits(25, 50)}, 60, 50},
+		"non-positive config": {[]Option{WithRecentLimits(0, -1)}, 0, DefaultRecentLimit},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			service := NewTaskService(mockRepo, tc.opts...)
+			mockRepo.On("GetRecent", tc.want).Return(nil, nil)
+
+			// Act
+			tasks, err := service.GetRecentTasks(tc.requested)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.NotNil(t, tasks, "never nil so it encodes as []")
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetChanges_InvalidCursor(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	for _, cursor := range []string{"not base64!", "bm8tc2VwYXJhdG9y", encodeChangesCursor(models.ChangesCursor{})} {
+		// Act
+		resp, err := service.GetChanges(time.Time{}, cursor, 10)
+
+		// Assert
+		assert.Nil(t, resp)
+		assert.True(t, errors.Is(err, ErrInvalidCursor), cursor)
+	}
+	mockRepo.AssertNotCalled(t, "GetChanges", mock.Anything, mock.Anything)
+}
+
+func TestGetChanges_WatermarkError(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	mockRepo.On("ChangesWatermark").Return(time.Time{}, errors.New("connection refused"))
+
+	// Act
+	resp, err := service.GetChanges(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), "", 10)
+
+	// Assert
+	assert.Nil(t, resp)
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "GetChanges", mock.Anything, mock.Anything)
+}
+
+// --- Test Cases for ReopenTask ---
+func TestReopenTask_DefaultStatus(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	mockRepo.On("Reopen", 1, "in_progress", "tests fail on CI").
+		Return(&models.Task{ID: 1, Status: "in_progress", ReopenReason: "tests fail on CI"}, nil)
+
+	// Act
+	task, err := service.ReopenTask(1, &models.ReopenTaskRequest{Reason: "  tests fail on CI "})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "in_progress", task.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReopenTask_ConfiguredStatus(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, WithReopenStatus("pending"))
+
+	mockRepo.On("Reopen", 1, "pending", "regression").Return(&models.Task{ID: 1, Status: "pending"}, nil)
+
+	// Act
+	_, err := service.ReopenTask(1, &models.ReopenTaskRequest{Reason: "regression"})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReopenTask_InvalidReason(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	for _, reason := range []string{"", "   ", strings.Repeat("x", MaxReopenReasonLength+1)} {
+		// Act
+		task, err := service.ReopenTask(1, &models.ReopenTaskRequest{Reason: reason})
+
+		// Assert
+		assert.Nil(t, task)
+		assert.True(t, errors.Is(err, ErrInvalidReopenReason))
+	}
+	mockRepo.AssertNotCalled(t, "Reopen", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReopenTask_NotCompleted(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	mockRepo.On("Reopen", 1, "in_progress", "why").Return(nil, repository.ErrTaskNotCompleted)
+
+	// Act
+	task, err := service.ReopenTask(1, &models.ReopenTaskRequest{Reason: "why"})
+
+	// Assert
+	assert.Nil(t, task)
+	assert.True(t, errors.Is(err, repository.ErrTaskNotCompleted))
+}
+
+func TestReopenTask_RecordsAuditAndPublishes(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	events := &recordingPublisher{}
+	service := NewTaskService(mockRepo, WithEventPublisher(events))
+	mockRepo.On("Reopen", 1, "in_progress", "regression").Return(&models.Task{ID: 1, Status: "in_progress"}, nil)
+	mockRepo.On("AddAuditEntry", &models.AuditEntry{
+		TaskID: 1, Actor: "alice", Action: models.AuditActionReopen, FromStatus: "completed", ToStatus: "in_progress", Note: "regression",
+	}).Return(nil)
+
+	// Act
+	_, err := service.ReopenTask(1, &models.ReopenTaskRequest{Reason: "regression", Actor: " alice "})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	if assert.Len(t, events.events, 1) {
+		assert.Equal(t, models.EventTaskUpdated, events.events[0].Type)
+		assert.Equal(t, map[string]models.FieldChange{"status": {Old: "completed", New: "in_progress"}}, events.events[0].Changes)
+	}
+}
+
+func TestReopenTask_AuditFailurePublishesNothing(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	events := &recordingPublisher{}
+	service := NewTaskService(mockRepo, WithEventPublisher(events))
+	mockRepo.On("Reopen", 1, "in_progress", "why").Return(&models.Task{ID: 1, Status: "in_progress"}, nil)
+	mockRepo.On("AddAuditEntry", mock.Anything).Return(errors.New("disk full"))
+
+	// Act
+	task, err := service.ReopenTask(1, &models.ReopenTaskRequest{Reason: "why"})
+
+	// Assert
+	assert.Nil(t, task)
+	assert.Error(t, err)
+	assert.Empty(t, events.events)
+}
+
+// --- Test Cases for PatchTask ---
+func TestPatchTask_MergesFields(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	existing := &models.Task{ID: 1, Title: "Old", Description: "Keep?", Status: "pending",
+		Metadata: map[string]interface{}{"team": "core", "sprint": float64(12)}}
+	mockRepo.On("GetByID", 1).Return(existing, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task")).Return(nil)
+
+	empty := ""
+	patch := &models.PatchTaskRequest{
+		Description: &empty,
+		Metadata:    map[string]interface{}{"team": nil, "area": "api"},
+	}
+
+	// Act
+	task, err := service.PatchTask(1, patch)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Old", task.Title, "absent fields are untouched")
+	assert.Equal(t, "pending", task.Status)
+	assert.Equal(t, "", task.Description)
+	assert.Equal(t, map[string]interface{}{"sprint": float64(12), "area": "api"}, task.Metadata)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPatchTask_ClearMetadata(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Metadata: map[string]interface{}{"a": "b"}}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task")).Return(nil)
+
+	// Act
+	task, err := service.PatchTask(1, &models.PatchTaskRequest{ClearMetadata: true})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, task.Metadata)
+}
+
+func TestPatchTask_InvalidResult(t *testing.T) {
+	blank := "  "
+	bogus := "done"
+	cases := map[string]*models.PatchTaskRequest{
+		"blank title":    {Title: &blank},
+		"invalid status": {Status: &bogus},
+	}
+	for name, patch := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			service := NewTaskService(mockRepo)
+			mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Status: "pending"}, nil)
+
+			// Act
+			task, err := service.PatchTask(1, patch)
+
+			// Assert
+			assert.Nil(t, task)
+			assert.True(t, errors.Is(err, ErrInvalidPatch))
+			mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+		})
+	}
+}
+
+// txRecordingRepository records the outcome of every transaction the service runs
+type txRecordingRepository struct {
+	*MockTaskRepository
+	committed, rolledBack int
+}
+
+func (r *txRecordingRepository) WithTransaction(ctx context.Context, fn func(repo repository.TaskRepository) error) error {
+	err := fn(r)
+	if err != nil {
+		r.rolledBack++
+	} else {
+		r.committed++
+	}
+	return err
+}
+
+// --- Test Cases for transactions ---
+func TestUpdateTask_RunsInTransaction(t *testing.T) {
+	// Arrange
+	repo := &txRecordingRepository{MockTaskRepository: new(MockTaskRepository)}
+	service := NewTaskService(repo)
+	repo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "Old", Status: "pending"}, nil)
+	repo.On("Update", mock.AnythingOfType("*models.Task")).Return(nil)
+
+	// Act
+	task, err := service.UpdateTask(1, &models.UpdateTaskRequest{Title: "New"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "New", task.Title)
+	assert.Equal(t, 1, repo.committed)
+	assert.Equal(t, 0, repo.rolledBack)
+}
+
+func TestPatchTask_RollsBackOnFailure(t *testing.T) {
+	// Arrange
+	repo := &txRecordingRepository{MockTaskRepository: new(MockTaskRepository)}
+	service := NewTaskService(repo)
+	repo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "Old", Status: "pending"}, nil)
+	repo.On("Update", mock.AnythingOfType("*models.Task")).Return(errors.New("db down"))
+	title := "New"
+
+	// Act
+	task, err := service.PatchTask(1, &models.PatchTaskRequest{Title: &title})
+
+	// Assert
+	assert.Nil(t, task)
+	assert.Error(t, err)
+	assert.Equal(t, 0, repo.committed)
+	assert.Equal(t, 1, repo.rolledBack)
+}
+
+// --- Test Cases for assignee ---
+func TestCreateTask_Assignee(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Task")).Return(nil)
+
+	// Act
+	task, err := service.CreateTask(&models.CreateTaskRequest{Title: "Triage", Assignee: "  alice "})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", task.Assignee)
+}
+
+func TestCreateTask_InvalidAssignee(t *testing.T) {
+	for _, assignee := range []string{"none", "NONE", strings.Repeat("a", MaxAssigneeLength+1)} {
+		// Arrange
+		mockRepo := new(MockTaskRepository)
+		service := NewTaskService(mockRepo)
+
+		// Act
+		task, err := service.CreateTask(&models.CreateTaskRequest{Title: "Triage", Assignee: assignee})
+
+		// Assert
+		assert.Nil(t, task)
+		assert.True(t, errors.Is(err, ErrInvalidAssignee), assignee)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	}
+}
+
+// --- Test Cases for the default due date ---
+func TestCreateTask_DefaultDueDate(t *testing.T) {
+	explicit := models.NewDate(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	days := func(n int) *int { return &n }
+	date := func(s string) *models.Date { d := mustDate(s); return &d }
+	cases := map[string]struct {
+		defaultDays int
+		req         models.CreateTaskRequest
+		want        *models.Date
+	}{
+		"disabled by default":      {0, models.CreateTaskRequest{Title: "T"}, nil},
+		"configured offset":        {3, models.CreateTaskRequest{Title: "T"}, date("2024-05-04")},
+		"explicit date wins":       {3, models.CreateTaskRequest{Title: "T", DueDate: &explicit}, &explicit},
+		"explicit date and offset": {3, models.CreateTaskRequest{Title: "T", DueDate: &explicit, DueInDays: days(7)}, &explicit},
+		"request offset":           {3, models.CreateTaskRequest{Title: "T", DueInDays: days(7)}, date("2024-05-08")},
+		"request offset when off":  {0, models.CreateTaskRequest{Title: "T", DueInDays: days(1)}, date("2024-05-02")},
+		"request opts out":         {3, models.CreateTaskRequest{Title: "T", DueInDays: days(0)}, nil},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			svc := NewTaskService(mockRepo, WithDefaultDueDays(tc.defaultDays)).(*taskService)
+			// Late in the day, so the offset must be counted in UTC rather than local time
+			svc.now = func() time.Time { return time.Date(2024, 5, 1, 23, 30, 0, 0, time.UTC) }
+			mockRepo.On("Create", mock.AnythingOfType("*models.Task")).Return(nil)
+
+			// Act
+			task, err := svc.CreateTask(&tc.req)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, task.DueDate)
+		})
+	}
+}
+
+func TestCreateTask_InvalidDueInDays(t *testing.T) {
+	for _, n := range []int{-1, MaxDueInDays + 1} {
+		// Arrange
+		mockRepo := new(MockTaskRepository)
+		service := NewTaskService(mockRepo)
+
+		// Act
+		task, err := service.CreateTask(&models.CreateTaskRequest{Title: "T", DueInDays: &n})
+
+		// Assert
+		assert.Nil(t, task)
+		assert.True(t, errors.Is(err, ErrInvalidDueDate), n)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	}
+}
+
+// --- Test Cases for ReassignTasks ---
+func TestReassignTasks_PublishesPerTask(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	events := &recordingPublisher{}
+	service := NewTaskService(mockRepo, WithEventPublisher(events))
+	moved := []*models.Task{{ID: 2, Title: "A", Assignee: "bob"}, {ID: 5, Title: "B", Assignee: "bob"}}
+	mockRepo.On("Reassign", "alice", "bob").Return(moved, nil)
+
+	// Act
+	resp, err := service.ReassignTasks(&models.ReassignTasksRequest{From: " alice ", To: "bob"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &models.ReassignTasksResponse{From: "alice", To: "bob", Moved: 2}, resp)
+	if assert.Len(t, events.events, 2) {
+		assert.Equal(t, 5, events.events[1].TaskID)
+		assert.Equal(t, map[string]models.FieldChange{"assignee": {Old: "alice", New: "bob"}}, events.events[1].Changes)
+	}
+}
+
+func TestReassignTasks_PublishesOneBatch(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	events := &recordingPublisher{}
+	service := NewTaskService(mockRepo, WithEventPublisher(events), WithEventMode(EventsBatched))
+	moved := make([]*models.Task, 100)
+	for i := range moved {
+		moved[i] = &models.Task{ID: i + 1, Title: fmt.Sprintf("Task %d", i+1), Assignee: "bob"}
+	}
+	mockRepo.On("Reassign", "alice", "bob").Return(moved, nil)
+
+	// Act
+	resp, err := service.ReassignTasks(&models.ReassignTasksRequest{From: "alice", To: "bob"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 100, resp.Moved)
+	if assert.Len(t, events.events, 1, "the whole reassignment is one delivery") {
+		batch := events.events[0]
+		assert.Equal(t, models.EventTasksBatch, batch.Type)
+		assert.Zero(t, batch.TaskID)
+		if assert.Len(t, batch.Events, 100) {
+			for i, event := range batch.Events {
+				assert.Equal(t, models.EventTaskUpdated, event.Type)
+				assert.Equal(t, i+1, event.TaskID, "items keep the repository's order")
+				assert.Equal(t, map[string]models.FieldChange{"assignee": {Old: "alice", New: "bob"}}, event.Changes)
+				assert.Equal(t, batch.OccurredAt, event.OccurredAt)
+			}
+		}
+	}
+}
+
+func TestReassignTasks_NothingMovedPublishesNothing(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	events := &recordingPublisher{}
+	service := NewTaskService(mockRepo, WithEventPublisher(events), WithEventMode(EventsBatched))
+	mockRepo.On("Reassign", "alice", "bob").Return([]*models.Task{}, nil)
+
+	_, err := service.ReassignTasks(&models.ReassignTasksRequest{From: "alice", To: "bob"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, events.events)
+}
+
+func TestReassignTasks_Invalid(t *testing.T) {
+	cases := map[string]models.ReassignTasksRequest{
+		"missing from": {To: "bob"},
+		"missing to":   {From: "alice"},
+		"same":         {From: "alice", To: " alice"},
+		"reserved":     {From: "alice", To: "none"},
+	}
+	for name, req := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			service := NewTaskService(mockRepo)
+
+			// Act
+			resp, err := service.ReassignTasks(&req)
+
+			// Assert
+			assert.Nil(t, resp)
+			assert.True(t, errors.Is(err, ErrInvalidAssignee), err)
+			mockRepo.AssertNotCalled(t, "Reassign", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestReassignTasks_EmailIsCaseInsensitive(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, WithAssigneeFormat(AssigneeFormatEmail))
+
+	// Act
+	_, err := service.ReassignTasks(&models.ReassignTasksRequest{From: "Alice@example.com", To: "alice@example.com"})
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrInvalidAssignee), "both normalise to the same address")
+}
+
+func TestPatchTask_Unassign(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Assignee: "alice"}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task")).Return(nil)
+	empty := ""
+
+	// Act
+	task, err := service.PatchTask(1, &models.PatchTaskRequest{Assignee: &empty})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "", task.Assignee)
+}
+
+func TestAssignTask_SetsOnlyAssignee(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Status: "pending", Assignee: "alice"}, nil)
+	mockRepo.On("Update", mock.MatchedBy(func(task *models.Task) bool {
+		return task.Assignee == "bob" && task.Title == "T" && task.Status == "pending"
+	})).Return(nil)
+	bob := " bob "
+
+	// Act
+	task, err := service.AssignTask(1, &models.AssignTaskRequest{Assignee: &bob})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", task.Assignee)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAssignTask_NilUnassigns(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Assignee: "alice"}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task")).Return(nil)
+
+	// Act
+	task, err := service.AssignTask(1, &models.AssignTaskRequest{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "", task.Assignee)
+}
+
+func TestAssignTask_Invalid(t *testing.T) {
+	for _, assignee := range []string{"   ", "None"} {
+		// Arrange
+		mockRepo := new(MockTaskRepository)
+		service := NewTaskService(mockRepo)
+		mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T"}, nil)
+		value := assignee
+
+		// Act
+		_, err := service.AssignTask(1, &models.AssignTaskRequest{Assignee: &value})
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidAssignee, assignee)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	}
+}
+
+func TestAssignTask_RecordsAudit(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Status: "pending", Assignee: "alice"}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task")).Return(nil)
+	mockRepo.On("AddAuditEntry", &models.AuditEntry{
+		TaskID: 1, Actor: "carol", Action: models.AuditActionAssign, Note: "assignee alice -> bob",
+	}).Return(nil)
+	bob := "bob"
+
+	// Act
+	_, err := service.AssignTask(1, &models.AssignTaskRequest{Assignee: &bob, Actor: "carol"})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAssignTask_ActorTooLong(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	bob := "bob"
+
+	// Act
+	_, err := service.AssignTask(1, &models.AssignTaskRequest{Assignee: &bob, Actor: strings.Repeat("a", MaxActorLength+1)})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidActor)
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
+func TestCreateTask_RecordsAudit(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Task")).Return(nil)
+	mockRepo.On("AddAuditEntry", &models.AuditEntry{
+		TaskID: 1, Actor: "carol", Action: models.AuditActionCreate, ToStatus: "pending",
+	}).Return(nil)
+
+	// Act
+	_, err := service.CreateTask(&models.CreateTaskRequest{Title: "T", Actor: " carol "})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateTask_AuditFailurePublishesNothing(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	events := &recordingPublisher{}
+	service := NewTaskService(mockRepo, WithEventPublisher(events))
+	mockRepo.On("Create", mock.AnythingOfType("*models.Task")).Return(nil)
+	mockRepo.On("AddAuditEntry", mock.Anything).Return(errors.New("audit write failed"))
+
+	// Act
+	task, err := service.CreateTask(&models.CreateTaskRequest{Title: "T", Actor: "carol"})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, task)
+	assert.Empty(t, events.events)
+}
+
+func TestUpdateTask_RecordsAudit(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "Old", Status: "pending"}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task")).Return(nil)
+	mockRepo.On("AddAuditEntry", &models.AuditEntry{
+		TaskID: 1, Actor: "carol", Action: models.AuditActionUpdate,
+		FromStatus: "pending", ToStatus: "in_progress", Note: "title",
+	}).Return(nil)
+
+	// Act
+	_, err := service.UpdateTask(1, &models.UpdateTaskRequest{Title: "New", Status: "in_progress", Actor: "carol"})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPatchTask_RecordsAudit(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Status: "pending"}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task")).Return(nil)
+	mockRepo.On("AddAuditEntry", &models.AuditEntry{
+		TaskID: 1, Actor: "carol", Action: models.AuditActionPatch, Note: "assignee none -> bob; description",
+	}).Return(nil)
+	desc, bob := "details", "bob"
+
+	// Act
+	_, err := service.PatchTask(1, &models.PatchTaskRequest{Description: &desc, Assignee: &bob, Actor: "carol"})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPatchTask_ActorTooLong(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	desc := "details"
+
+	// Act
+	_, err := service.PatchTask(1, &models.PatchTaskRequest{Description: &desc, Actor: strings.Repeat("a", MaxActorLength+1)})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidActor)
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+}
+
+func TestReassignTasks_RecordsAuditPerTask(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	moved := []*models.Task{{ID: 2, Title: "A", Assignee: "bob"}, {ID: 5, Title: "B", Assignee: "bob"}}
+	mockRepo.On("Reassign", "alice", "bob").Return(moved, nil)
+	for _, id := range []int{2, 5} {
+		mockRepo.On("AddAuditEntry", &models.AuditEntry{
+			TaskID: id, Actor: "carol", Action: models.AuditActionReassign, Note: "assignee alice -> bob",
+		}).Return(nil).Once()
+	}
+
+	// Act
+	_, err := service.ReassignTasks(&models.ReassignTasksRequest{From: "alice", To: "bob", Actor: "carol"})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+// --- Test Cases for ResolveUUID ---
+func TestResolveUUID(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	mockRepo.On("GetIDByUUID", "3f2b8c1e-9a4d-4e6f-8b2a-1c5d7e9f0a3b").Return(7, nil)
+	mockRepo.On("GetIDByUUID", "00000000-0000-4000-8000-000000000000").Return(0, repository.ErrTaskNotFound)
+
+	// Act
+	id, err := service.ResolveUUID("3f2b8c1e-9a4d-4e6f-8b2a-1c5d7e9f0a3b")
+	_, missingErr := service.ResolveUUID("00000000-0000-4000-8000-000000000000")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 7, id)
+	assert.True(t, errors.Is(missingErr, repository.ErrTaskNotFound))
+}
+
+func TestSkipNoopUpdates(t *testing.T) {
+	updatedAt := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	due := models.NewDate(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	title, status, assignee := "Same", "pending", ""
+	tests := map[string]func(s TaskService) (*models.Task, error){
+		"update with the current values": func(s TaskService) (*models.Task, error) {
+			return s.UpdateTask(1, &models.UpdateTaskRequest{Title: "Same", Status: "pending",
+				Metadata: map[string]interface{}{"team": "core"}, DueDate: &due})
+		},
+		"update with no fields": func(s TaskService) (*models.Task, error) {
+			return s.UpdateTask(1, &models.UpdateTaskRequest{})
+		},
+		"put with the current values": func(s TaskService) (*models.Task, error) {
+			task, _, err := s.PutTask(1, &models.UpdateTaskRequest{Title: "Same"})
+			return task, err
+		},
+		"patch with the current values": func(s TaskService) (*models.Task, error) {
+			return s.PatchTask(1, &models.PatchTaskRequest{Title: &title, Status: &status, Assignee: &assignee,
+				Metadata: map[string]interface{}{"team": "core"}})
+		},
+	}
+	for name, update := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			events := &recordingPublisher{}
+			svc := NewTaskService(mockRepo, WithSkipNoopUpdates(true), WithEventPublisher(events))
+			mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "Same", Status: "pending",
+				Metadata: map[string]interface{}{"team": "core"}, DueDate: &due, UpdatedAt: updatedAt}, nil)
+
+			// Act
+			task, err := update(svc)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, updatedAt, task.UpdatedAt)
+			assert.Empty(t, events.events)
+			mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+		})
+	}
+}
+
+func TestSkipNoopUpdates_RealChangesStillWrite(t *testing.T) {
+	tests := map[string]struct {
+		skip   bool
+		req    models.UpdateTaskRequest
+		revive bool
+	}{
+		"a changed field":     {skip: true, req: models.UpdateTaskRequest{Title: "Renamed"}},
+		"a revived task":      {skip: true, req: models.UpdateTaskRequest{Title: "Same"}, revive: true},
+		"skipping turned off": {skip: false, req: models.UpdateTaskRequest{Title: "Same"}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(MockTaskRepository)
+			events := &recordingPublisher{}
+			svc := NewTaskService(mockRepo, WithSkipNoopUpdates(tc.skip), WithReviveOnUpdate(true), WithEventPublisher(events))
+			if tc.revive {
+				mockRepo.On("GetByID", 1).Return(nil, repository.ErrTaskNotFound).Once()
+				mockRepo.On("Revive", 1).Return(nil)
+			}
+			mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "Same", Status: "pending"}, nil)
+			mockRepo.On("Update", mock.AnythingOfType("*models.Task")).Return(nil)
+
+			_, err := svc.UpdateTask(1, &tc.req)
+
+			assert.NoError(t, err)
+			mockRepo.AssertCalled(t, "Update", mock.Anything)
+			assert.Len(t, events.events, 1)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+)
+
+// ErrInvalidTemplate is returned when a template definition is incomplete or out of range
+var ErrInvalidTemplate = errors.New("invalid template")
+
+// MaxTemplateNameLength matches the task_templates.name column size
+const MaxTemplateNameLength = 255
+
+// templateDatePlaceholder in a template title is replaced with the UTC date the task is created on
+const templateDatePlaceholder = "{date}"
+
+// CreateTemplate validates and saves a task template. Its fields are checked the way a task's
+// are, so every template can produce a valid task.
+func (s *taskService) CreateTemplate(req *models.CreateTemplateRequest) (*models.TaskTemplate, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidTemplate)
+	}
+	if len(name) > MaxTemplateNameLength {
+		return nil, fmt.Errorf("%w: name must be at most %d bytes", ErrInvalidTemplate, MaxTemplateNameLength)
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		return nil, fmt.Errorf("%w: title is required", ErrInvalidTemplate)
+	}
+	if req.DueInDays != nil && (*req.DueInDays < 0 || *req.DueInDays > MaxDueInDays) {
+		return nil, fmt.Errorf("%w: due_in_days must be between 0 and %d", ErrInvalidTemplate, MaxDueInDays)
+	}
+	if err := validateDescription(req.Description); err != nil {
+		return nil, err
+	}
+	if err := validateMetadata(req.Metadata); err != nil {
+		return nil, err
+	}
+	assignee, err := s.normalizeAssignee(req.Assignee)
+	if err != nil {
+		return nil, err
+	}
+	var priority string
+	if req.Priority != "" {
+		if priority, err = checkPriority(req.Priority); err != nil {
+			return nil, err
+		}
+	}
+
+	tmpl := &models.TaskTemplate{
+		Name:        name,
+		Title:       req.Title,
+		Description: req.Description,
+		Metadata:    req.Metadata,
+		Assignee:    assignee,
+		DueInDays:   req.DueInDays,
+		Priority:    priority,
+	}
+	if err := s.repo.CreateTemplate(tmpl); err != nil {
+		return nil, fmt.Errorf("failed to create template in repository: %w", err)
+	}
+	return tmpl, nil
+}
+
+// GetTemplate returns one task template
+func (s *taskService) GetTemplate(id int) (*models.TaskTemplate, error) {
+	tmpl, err := s.repo.GetTemplate(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template from repository: %w", err)
+	}
+	return tmpl, nil
+}
+
+// ListTemplates returns every task template, sorted by name
+func (s *taskService) ListTemplates() ([]*models.TaskTemplate, error) {
+	templates, err := s.repo.ListTemplates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	return templates, nil
+}
+
+// DeleteTemplate removes a task template
+func (s *taskService) DeleteTemplate(id int) error {
+	if err := s.repo.DeleteTemplate(id); err != nil {
+		return fmt.Errorf("failed to delete template from repository: %w", err)
+	}
+	return nil
+}
+
+// CreateTaskFromTemplate creates a task from a template's defaults. Any field set in overrides
+// replaces the template's; metadata keys are merged, with overrides winning. The result goes
+// through CreateTask, so it is validated like any other new task.
+func (s *taskService) CreateTaskFromTemplate(templateID int, overrides *models.CreateTaskRequest) (*models.Task, error) {
+	tmpl, err := s.repo.GetTemplate(templateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template from repository: %w", err)
+	}
+	return s.CreateTask(s.templateRequest(tmpl, overrides))
+}
+
+// templateRequest merges a template and a request's overrides into a create request
+func (s *taskService) templateRequest(tmpl *models.TaskTemplate, overrides *models.CreateTaskRequest) *models.CreateTaskRequest {
+	req := &models.CreateTaskRequest{
+		Title:       strings.ReplaceAll(tmpl.Title, templateDatePlaceholder, models.NewDate(s.now().UTC()).String()),
+		Description: tmpl.Description,
+		Assignee:    tmpl.Assignee,
+		DueInDays:   tmpl.DueInDays,
+		Priority:    tmpl.Priority,
+		Actor:       overrides.Actor,
+	}
+	if overrides.Title != "" {
+		req.Title = overrides.Title
+	}
+	if overrides.Description != "" {
+		req.Description = overrides.Description
+	}
+	if overrides.Assignee != "" {
+		req.Assignee = overrides.Assignee
+	}
+	if overrides.Priority != "" {
+		req.Priority = overrides.Priority
+	}
+	if overrides.DueDate != nil || overrides.DueInDays != nil {
+		req.DueDate, req.DueInDays = overrides.DueDate, overrides.DueInDays
+	}
+	if len(tmpl.Metadata) > 0 || len(overrides.Metadata) > 0 {
+		req.Metadata = map[string]interface{}{}
+		for k, v := range tmpl.Metadata {
+			req.Metadata[k] = v
+		}
+		for k, v := range overrides.Metadata {
+			req.Metadata[k] = v
+		}
+	}
+	return req
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/cliffdoyle/task-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateTemplate_Validation(t *testing.T) {
+	negative, tooFar := -1, MaxDueInDays+1
+	tests := map[string]struct {
+		req     models.CreateTemplateRequest
+		wantErr error
+	}{
+		"missing name": {req: models.CreateTemplateRequest{Title: "T"}, wantErr: ErrInvalidTemplate},
+		"blank title":  {req: models.CreateTemplateRequest{Name: "n", Title: "  "}, wantErr: ErrInvalidTemplate},
+		"negative due": {req: models.CreateTemplateRequest{Name: "n", Title: "T", DueInDays: &negative}, wantErr: ErrInvalidTemplate},
+		"due too far":  {req: models.CreateTemplateRequest{Name: "n", Title: "T", DueInDays: &tooFar}, wantErr: ErrInvalidTemplate},
+		"bad metadata": {req: models.CreateTemplateRequest{Name: "n", Title: "T", Metadata: map[string]interface{}{"": 1}}, wantErr: ErrInvalidMetadata},
+		"bad assignee": {req: models.CreateTemplateRequest{Name: "n", Title: "T", Assignee: "none"}, wantErr: ErrInvalidAssignee},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			service := NewTaskService(mockRepo)
+
+			// Act
+			tmpl, err := service.CreateTemplate(&tc.req)
+
+			// Assert
+			assert.Nil(t, tmpl)
+			assert.True(t, errors.Is(err, tc.wantErr), "got %v", err)
+			mockRepo.AssertNotCalled(t, "CreateTemplate", mock.Anything)
+		})
+	}
+}
+
+func TestCreateTemplate_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	mockRepo.On("CreateTemplate", mock.AnythingOfType("*models.TaskTemplate")).Return(nil)
+
+	// Act
+	tmpl, err := service.CreateTemplate(&models.CreateTemplateRequest{Name: " weekly ", Title: "Report {date}", Assignee: " alice "})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, tmpl.ID)
+	assert.Equal(t, "weekly", tmpl.Name)
+	assert.Equal(t, "Report {date}", tmpl.Title)
+	assert.Equal(t, "alice", tmpl.Assignee)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateTaskFromTemplate(t *testing.T) {
+	two, five := 2, 5
+	override := models.NewDate(time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC))
+	tmpl := &models.TaskTemplate{
+		ID:          3,
+		Name:        "weekly",
+		Title:       "Report {date}",
+		Description: "Collect numbers",
+		Metadata:    map[string]interface{}{"team": "ops", "kind": "report"},
+		Assignee:    "alice",
+		Priority:    "high",
+		DueInDays:   &two,
+	}
+	tests := map[string]struct {
+		overrides models.CreateTaskRequest
+		want      models.Task
+	}{
+		"template defaults": {
+			want: models.Task{
+				Title:       "Report 2024-05-06",
+				Description: "Collect numbers",
+				Metadata:    map[string]interface{}{"team": "ops", "kind": "report"},
+				Assignee:    "alice",
+				Priority:    "high",
+				DueDate:     dueDate("2024-05-08"),
+			},
+		},
+		"overrides win": {
+			overrides: models.CreateTaskRequest{
+				Title:       "Special report",
+				Description: "Just this once",
+				Metadata:    map[string]interface{}{"team": "sales", "urgent": true},
+				Assignee:    "bob",
+				Priority:    "low",
+				DueInDays:   &five,
+			},
+			want: models.Task{
+				Title:       "Special report",
+				Description: "Just this once",
+				Metadata:    map[string]interface{}{"team": "sales", "kind": "report", "urgent": true},
+				Assignee:    "bob",
+				Priority:    "low",
+				DueDate:     dueDate("2024-05-11"),
+			},
+		},
+		"due date override": {
+			overrides: models.CreateTaskRequest{DueDate: &override},
+			want: models.Task{
+				Title:       "Report 2024-05-06",
+				Description: "Collect numbers",
+				Metadata:    map[string]interface{}{"team": "ops", "kind": "report"},
+				Assignee:    "alice",
+				Priority:    "high",
+				DueDate:     &override,
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			svc := NewTaskService(mockRepo).(*taskService)
+			svc.now = func() time.Time { return time.Date(2024, 5, 6, 23, 30, 0, 0, time.UTC) }
+			mockRepo.On("GetTemplate", 3).Return(tmpl, nil)
+			mockRepo.On("Create", mock.AnythingOfType("*models.Task")).Return(nil)
+
+			// Act
+			task, err := svc.CreateTaskFromTemplate(3, &tc.overrides)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want.Title, task.Title)
+			assert.Equal(t, tc.want.Description, task.Description)
+			assert.Equal(t, tc.want.Metadata, task.Metadata)
+			assert.Equal(t, tc.want.Assignee, task.Assignee)
+			assert.Equal(t, tc.want.Priority, task.Priority)
+			assert.Equal(t, tc.want.DueDate, task.DueDate)
+			assert.Equal(t, "pending", task.Status)
+		})
+	}
+}
+
+func TestCreateTaskFromTemplate_NotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	mockRepo.On("GetTemplate", 9).Return(nil, repository.ErrTemplateNotFound)
+
+	// Act
+	task, err := service.CreateTaskFromTemplate(9, &models.CreateTaskRequest{})
+
+	// Assert
+	assert.Nil(t, task)
+	assert.True(t, errors.Is(err, repository.ErrTemplateNotFound))
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func dueDate(s string) *models.Date {
+	d := mustDate(s)
+	return &d
+}
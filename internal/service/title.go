@@ -0,0 +1,100 @@
+package service
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidTitle is returned (wrapped with the rule that failed) when a title breaks the
+// configured TitlePolicy
+var ErrInvalidTitle = errors.New("invalid title")
+
+// blocklistRegexPrefix marks a blocklist entry as a regular expression rather than a substring
+const blocklistRegexPrefix = "re:"
+
+// TitlePolicy holds the content rules a title must follow beyond being non-empty. The zero
+// value allows any title.
+type TitlePolicy struct {
+	MinLength int // minimum length in characters, ignoring surrounding space; 0 disables it
+
+	blocked []blockedTerm
+}
+
+// blockedTerm is one compiled blocklist entry, kept with its source for error messages
+type blockedTerm struct {
+	entry string
+	re    *regexp.Regexp
+}
+
+// NewTitlePolicy builds a TitlePolicy. Each blocklist entry is a case-insensitive substring,
+// or a regular expression when prefixed with "re:" (e.g. "re:^test\d*$").
+func NewTitlePolicy(minLength int, blocklist []string) (TitlePolicy, error) {
+	if minLength < 0 {
+		return TitlePolicy{}, fmt.Errorf("minimum title length must not be negative, got %d", minLength)
+	}
+	p := TitlePolicy{MinLength: minLength}
+	for _, entry := range blocklist {
+		var re *regexp.Regexp
+		var err error
+		if pattern, ok := strings.CutPrefix(entry, blocklistRegexPrefix); ok {
+			re, err = regexp.Compile(pattern)
+		} else {
+			re, err = regexp.Compile("(?i)" + regexp.QuoteMeta(entry))
+		}
+		if err != nil {
+			return TitlePolicy{}, fmt.Errorf("invalid blocklist entry %q: %w", entry, err)
+		}
+		p.blocked = append(p.blocked, blockedTerm{entry: entry, re: re})
+	}
+	return p, nil
+}
+
+// LoadTitleBlocklist reads blocklist entries from a file, one per line. Blank lines and lines
+// starting with # are skipped; surrounding space is trimmed.
+func LoadTitleBlocklist(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read title blocklist: %w", err)
+	}
+	defer f.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read title blocklist %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// WithTitlePolicy checks titles on create, update and patch against p. Tasks already stored are
+// not re-checked.
+func WithTitlePolicy(p TitlePolicy) Option {
+	return func(s *taskService) {
+		s.titlePolicy = p
+	}
+}
+
+// checkTitle validates a non-empty title the request is setting against the title policy
+func (s *taskService) checkTitle(title string) error {
+	if n := utf8.RuneCountInString(strings.TrimSpace(title)); n < s.titlePolicy.MinLength {
+		return fmt.Errorf("%w: title must be at least %d characters, got %d", ErrInvalidTitle, s.titlePolicy.MinLength, n)
+	}
+	for _, term := range s.titlePolicy.blocked {
+		if term.re.MatchString(title) {
+			return fmt.Errorf("%w: title matches blocked term %q", ErrInvalidTitle, term.entry)
+		}
+	}
+	return nil
+}
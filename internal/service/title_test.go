@@ -0,0 +1,108 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTitle_MinLength(t *testing.T) {
+	policy, err := NewTitlePolicy(5, nil)
+	require.NoError(t, err)
+	svc := NewTaskService(new(MockTaskRepository), WithTitlePolicy(policy)).(*taskService)
+
+	tests := map[string]struct {
+		title   string
+		wantErr string
+	}{
+		"one short":        {"Fix", "invalid title: title must be at least 5 characters, got 3"},
+		"just short":       {"Deps", "invalid title: title must be at least 5 characters, got 4"},
+		"exactly min":      {"Build", ""},
+		"spaces not count": {"  Docs  ", "invalid title: title must be at least 5 characters, got 4"},
+		"runes not bytes":  {"Café!", ""},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := svc.checkTitle(tc.title)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidTitle)
+			assert.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestCheckTitle_Blocklist(t *testing.T) {
+	policy, err := NewTitlePolicy(0, []string{"tbd", `re:^test\d*$`})
+	require.NoError(t, err)
+	svc := NewTaskService(new(MockTaskRepository), WithTitlePolicy(policy)).(*taskService)
+
+	tests := map[string]struct {
+		title   string
+		wantErr string
+	}{
+		"substring":         {"Write docs (TBD)", `invalid title: title matches blocked term "tbd"`},
+		"regex":             {"test42", `invalid title: title matches blocked term "re:^test\\d*$"`},
+		"regex is anchored": {"test the release", ""},
+		"no blocked terms":  {"Ship release", ""},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := svc.checkTitle(tc.title)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidTitle)
+			assert.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestNewTitlePolicy_Invalid(t *testing.T) {
+	_, err := NewTitlePolicy(-1, nil)
+	assert.Error(t, err)
+	_, err = NewTitlePolicy(0, []string{"re:(unclosed"})
+	assert.ErrorContains(t, err, `invalid blocklist entry "re:(unclosed"`)
+}
+
+func TestLoadTitleBlocklist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# placeholders\ntbd\n\n  re:^wip  \n"), 0o600))
+
+	entries, err := LoadTitleBlocklist(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tbd", "re:^wip"}, entries)
+	_, err = LoadTitleBlocklist(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
+
+func TestTitlePolicy_AppliesToWrites(t *testing.T) {
+	// Arrange
+	policy, err := NewTitlePolicy(3, []string{"tbd"})
+	require.NoError(t, err)
+	mockRepo := new(MockTaskRepository)
+	svc := NewTaskService(mockRepo, WithTitlePolicy(policy))
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "Existing", Status: "pending"}, nil)
+	blocked := "Docs TBD"
+
+	// Act
+	_, createErr := svc.CreateTask(&models.CreateTaskRequest{Title: "Go"})
+	_, updateErr := svc.UpdateTask(1, &models.UpdateTaskRequest{Title: blocked})
+	_, patchErr := svc.PatchTask(1, &models.PatchTaskRequest{Title: &blocked})
+
+	// Assert
+	assert.ErrorIs(t, createErr, ErrInvalidTitle)
+	assert.ErrorIs(t, updateErr, ErrInvalidTitle)
+	assert.ErrorIs(t, patchErr, ErrInvalidTitle)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/cliffdoyle/task-api/internal/repository"
+)
+
+// ErrInvalidTransition is returned when an update would move a task to a status its current
+// status can't transition to
+var ErrInvalidTransition = errors.New("status transition not allowed")
+
+// ErrInvalidTransitionRequest is returned by TransitionTask for a missing or unknown target
+// status, or a note or actor that is too long
+var ErrInvalidTransitionRequest = errors.New("invalid transition request")
+
+const (
+	// MaxTransitionNoteLength caps the note recorded with a transition
+	MaxTransitionNoteLength = 1000
+	// MaxActorLength caps the actor recorded in the audit log, matching the task_audit column
+	MaxActorLength = 255
+)
+
+// initialStatus is the status every task is created with
+const initialStatus = "pending"
+
+// Transitions maps each status to the statuses a task in it may move to. Staying in the same
+// status is always allowed and needn't be listed.
+type Transitions map[string][]string
+
+// DefaultTransitions is the built-in workflow: any status may move to any other
+func DefaultTransitions() Transitions {
+	return Transitions{
+		"pending":     {"in_progress", "completed"},
+		"in_progress": {"pending", "completed"},
+		"completed":   {"pending", "in_progress"},
+	}
+}
+
+// LoadTransitions reads a Transitions table from a JSON file such as
+// {"pending": ["in_progress"], "in_progress": ["completed"], "completed": []}
+// and validates it
+func LoadTransitions(path string) (Transitions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transitions file: %w", err)
+	}
+	var t Transitions
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("failed to parse transitions file %s: %w", path, err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid transitions file %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// Validate checks that the table only names known statuses, lists each target once, has an
+// entry for every status, and that every status can be reached from the one tasks are created in
+func (t Transitions) Validate() error {
+	for from, targets := range t {
+		if !models.IsValidStatus(from) {
+			return fmt.Errorf("unknown status %q", from)
+		}
+		seen := map[string]bool{}
+		for _, to := range targets {
+			if !models.IsValidStatus(to) {
+				return fmt.Errorf("unknown status %q in transitions from %q", to, from)
+			}
+			if seen[to] {
+				return fmt.Errorf("duplicate status %q in transitions from %q", to, from)
+			}
+			seen[to] = true
+		}
+	}
+
+	reached := map[string]bool{initialStatus: true}
+	queue := []string{initialStatus}
+	for len(queue) > 0 {
+		from := queue[0]
+		queue = queue[1:]
+		for _, to := range t[from] {
+			if !reached[to] {
+				reached[to] = true
+				queue = append(queue, to)
+			}
+		}
+	}
+	var unreachable []string
+	for _, status := range models.Statuses {
+		if _, ok := t[status]; !ok {
+			return fmt.Errorf("missing entry for status %q (use [] if it is final)", status)
+		}
+		if !reached[status] {
+			unreachable = append(unreachable, status)
+		}
+	}
+	if len(unreachable) > 0 {
+		sort.Strings(unreachable)
+		return fmt.Errorf("statuses %v can't be reached from %q", unreachable, initialStatus)
+	}
+	return nil
+}
+
+// Warnings describes parts of a valid table that are legal but often a mistake: a status with no
+// way out, so tasks in it can never change status, and one with no way in, so tasks that leave it
+// can't come back. Listing a status as its own target counts as neither.
+func (t Transitions) Warnings() []string {
+	incoming, outgoing := map[string]bool{}, map[string]bool{}
+	for from, targets := range t {
+		for _, to := range targets {
+			if to != from {
+				incoming[to] = true
+				outgoing[from] = true
+			}
+		}
+	}
+	var warnings []string
+	for _, status := range models.Statuses {
+		if !outgoing[status] {
+			warnings = append(warnings, fmt.Sprintf("status %q has no outgoing transitions; tasks in it can't change status", status))
+		}
+		if !incoming[status] {
+			warnings = append(warnings, fmt.Sprintf("status %q has no incoming transitions; tasks that leave it can't return", status))
+		}
+	}
+	return warnings
+}
+
+// Allowed reports whether a task may move from one status to another
+func (t Transitions) Allowed(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, s := range t[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the statuses a task may move to from status, sorted
+func (t Transitions) Next(status string) []string {
+	next := append([]string{}, t[status]...)
+	sort.Strings(next)
+	return next
+}
+
+// WithTransitions sets the workflow enforced when a task's status is changed through
+// UpdateTask or PatchTask. A nil table keeps DefaultTransitions.
+func WithTransitions(t Transitions) Option {
+	return func(s *taskService) {
+		if t != nil {
+			s.transitions = t
+		}
+	}
+}
+
+// checkTransition returns ErrInvalidTransition if from can't move to to
+func (s *taskService) checkTransition(from, to string) error {
+	if !s.transitions.Allowed(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+	return nil
+}
+
+// NextTransitions reports the statuses a task can move to under the enforced workflow
+func (s *taskService) NextTransitions(id int) (*models.NextTransitionsResponse, error) {
+	task, err := s.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+	return &models.NextTransitionsResponse{
+		TaskID:   task.ID,
+		TaskUUID: task.UUID,
+		Status:   task.Status,
+		Allowed:  s.transitions.Next(task.Status),
+		Blocked:  []string{},
+	}, nil
+}
+
+// TransitionTask moves a task to req.To under the enforced workflow and records the change,
+// with its note and actor, in the audit log. The status change, its started_at/completed_at
+// stamp and the audit entry are written in one transaction. Moving a task to the status it
+// already has is refused with ErrInvalidTransition, since nothing would be transitioned.
+func (s *taskService) TransitionTask(id int, req *models.TransitionTaskRequest) (*models.Task, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid task ID")
+	}
+	to := s.canonicalStatus(req.To)
+	if !models.IsValidStatus(to) {
+		return nil, fmt.Errorf("%w: invalid status value %q", ErrInvalidTransitionRequest, to)
+	}
+	note := strings.TrimSpace(req.Note)
+	if len(note) > MaxTransitionNoteLength {
+		return nil, fmt.Errorf("%w: note must not exceed %d characters", ErrInvalidTransitionRequest, MaxTransitionNoteLength)
+	}
+	actor := strings.TrimSpace(req.Actor)
+	if len(actor) > MaxActorLength {
+		return nil, fmt.Errorf("%w: actor must not exceed %d characters", ErrInvalidTransitionRequest, MaxActorLength)
+	}
+
+	var task, before *models.Task
+	err := s.withTx(func(repo repository.TaskRepository) (err error) {
+		task, err = repo.GetByID(id)
+		if err != nil {
+			return fmt.Errorf("task with ID %d not found: %w", id, err)
+		}
+		if task.Status == to {
+			return fmt.Errorf("%w: task is already %s", ErrInvalidTransition, to)
+		}
+		if err := s.checkTransition(task.Status, to); err != nil {
+			return err
+		}
+		before = snapshot(task)
+		task.Status = to
+
+		if err := repo.Update(task); err != nil {
+			return fmt.Errorf("failed to update task in repository: %w", err)
+		}
+		return repo.AddAuditEntry(&models.AuditEntry{
+			TaskID:     id,
+			Actor:      actor,
+			Action:     models.AuditActionTransition,
+			FromStatus: before.Status,
+			ToStatus:   to,
+			Note:       note,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publishUpdate(before, task)
+	return task, nil
+}
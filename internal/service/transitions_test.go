@@ -0,0 +1,235 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/cliffdoyle/task-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// --- Test Cases for Transitions ---
+func TestDefaultTransitions_AllowEverything(t *testing.T) {
+	transitions := DefaultTransitions()
+
+	assert.NoError(t, transitions.Validate())
+	for _, from := range models.Statuses {
+		for _, to := range models.Statuses {
+			assert.True(t, transitions.Allowed(from, to), "%s -> %s", from, to)
+		}
+	}
+}
+
+func TestTransitions_Validate(t *testing.T) {
+	cases := map[string]Transitions{
+		"unknown source status": {"pending": {"in_progress"}, "in_progress": {"completed"}, "completed": {}, "blocked": {}},
+		"unknown target status": {"pending": {"in_progress", "done"}, "in_progress": {"completed"}, "completed": {}},
+		"missing status":        {"pending": {"in_progress"}, "in_progress": {"completed"}},
+		"unreachable status":    {"pending": {"in_progress"}, "in_progress": {"pending"}, "completed": {"pending"}},
+		"duplicate target":      {"pending": {"in_progress", "in_progress"}, "in_progress": {"completed"}, "completed": {}},
+		"case mismatch":         {"pending": {"In_Progress"}, "in_progress": {"completed"}, "completed": {}},
+	}
+	for name, transitions := range cases {
+		assert.Error(t, transitions.Validate(), name)
+	}
+
+	linear := Transitions{"pending": {"in_progress"}, "in_progress": {"completed"}, "completed": {}}
+	assert.NoError(t, linear.Validate())
+	assert.True(t, linear.Allowed("pending", "pending"), "staying put is always allowed")
+	assert.False(t, linear.Allowed("pending", "completed"))
+	assert.False(t, linear.Allowed("completed", "pending"))
+}
+
+func TestTransitions_Warnings(t *testing.T) {
+	cases := map[string]struct {
+		transitions Transitions
+		want        []string
+	}{
+		"default": {transitions: DefaultTransitions()},
+		"final status": {
+			transitions: Transitions{"pending": {"in_progress"}, "in_progress": {"completed", "pending"}, "completed": {}},
+			want:        []string{`status "completed" has no outgoing transitions; tasks in it can't change status`},
+		},
+		"no way back to pending": {
+			transitions: Transitions{"pending": {"in_progress"}, "in_progress": {"completed"}, "completed": {"in_progress"}},
+			want:        []string{`status "pending" has no incoming transitions; tasks that leave it can't return`},
+		},
+		"self-loop only": {
+			transitions: Transitions{"pending": {"in_progress"}, "in_progress": {"completed", "pending"}, "completed": {"completed"}},
+			want:        []string{`status "completed" has no outgoing transitions; tasks in it can't change status`},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.NoError(t, tc.transitions.Validate())
+			assert.Equal(t, tc.want, tc.transitions.Warnings())
+		})
+	}
+}
+
+func TestLoadTransitions(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	transitions, err := LoadTransitions(write("ok.json", `{"pending": ["in_progress"], "in_progress": ["completed", "pending"], "completed": []}`))
+	assert.NoError(t, err)
+	assert.Equal(t, Transitions{"pending": {"in_progress"}, "in_progress": {"completed", "pending"}, "completed": {}}, transitions)
+
+	_, err = LoadTransitions(write("bad.json", `{"pending": "in_progress"}`))
+	assert.Error(t, err)
+	_, err = LoadTransitions(write("invalid.json", `{"pending": ["done"], "in_progress": [], "completed": []}`))
+	assert.ErrorContains(t, err, `unknown status "done"`)
+	_, err = LoadTransitions(write("duplicate.json", `{"pending": ["in_progress", "in_progress"], "in_progress": ["completed"], "completed": []}`))
+	assert.ErrorContains(t, err, `duplicate status "in_progress" in transitions from "pending"`)
+	_, err = LoadTransitions(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestUpdateTask_RejectsDisallowedTransition(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	linear := Transitions{"pending": {"in_progress"}, "in_progress": {"completed"}, "completed": {}}
+	service := NewTaskService(mockRepo, WithTransitions(linear))
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Status: "pending"}, nil)
+
+	// Act
+	task, err := service.UpdateTask(1, &models.UpdateTaskRequest{Status: "completed"})
+
+	// Assert
+	assert.Nil(t, task)
+	assert.True(t, errors.Is(err, ErrInvalidTransition))
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestPatchTask_AllowsConfiguredTransition(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	linear := Transitions{"pending": {"in_progress"}, "in_progress": {"completed"}, "completed": {}}
+	service := NewTaskService(mockRepo, WithTransitions(linear))
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Status: "pending"}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Task")).Return(nil)
+	status := "in_progress"
+
+	// Act
+	task, err := service.PatchTask(1, &models.PatchTaskRequest{Status: &status})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "in_progress", task.Status)
+}
+
+// --- Test Cases for NextTransitions ---
+func TestNextTransitions(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	workflow := Transitions{"pending": {"in_progress"}, "in_progress": {"pending", "completed"}, "completed": {}}
+	service := NewTaskService(mockRepo, WithTransitions(workflow))
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Status: "in_progress"}, nil)
+	mockRepo.On("GetByID", 2).Return(&models.Task{ID: 2, Status: "completed"}, nil)
+
+	// Act
+	inProgress, err1 := service.NextTransitions(1)
+	completed, err2 := service.NextTransitions(2)
+
+	// Assert
+	assert.NoError(t, err1)
+	assert.Equal(t, &models.NextTransitionsResponse{TaskID: 1, Status: "in_progress", Allowed: []string{"completed", "pending"}, Blocked: []string{}}, inProgress)
+	assert.NoError(t, err2)
+	assert.Equal(t, []string{}, completed.Allowed, "a final status is an empty list, not null")
+}
+
+func TestNextTransitions_NotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo)
+	mockRepo.On("GetByID", 9).Return(nil, repository.ErrTaskNotFound)
+
+	// Act
+	next, err := service.NextTransitions(9)
+
+	// Assert
+	assert.Nil(t, next)
+	assert.True(t, errors.Is(err, repository.ErrTaskNotFound))
+}
+
+// --- Test Cases for TransitionTask ---
+func TestTransitionTask_RecordsAudit(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	publisher := &recordingPublisher{}
+	service := NewTaskService(mockRepo, WithEventPublisher(publisher))
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Status: "pending"}, nil)
+	mockRepo.On("Update", mock.MatchedBy(func(task *models.Task) bool { return task.Status == "in_progress" })).Return(nil)
+	mockRepo.On("AddAuditEntry", &models.AuditEntry{
+		TaskID: 1, Actor: "alice", Action: models.AuditActionTransition,
+		FromStatus: "pending", ToStatus: "in_progress", Note: "picked up",
+	}).Return(nil)
+
+	// Act
+	task, err := service.TransitionTask(1, &models.TransitionTaskRequest{To: "in_progress", Note: " picked up ", Actor: "alice"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "in_progress", task.Status)
+	mockRepo.AssertExpectations(t)
+	assert.Len(t, publisher.events, 1)
+	assert.Equal(t, models.FieldChange{Old: "pending", New: "in_progress"}, publisher.events[0].Changes["status"])
+}
+
+func TestTransitionTask_CaseInsensitiveStatus(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTaskRepository)
+	service := NewTaskService(mockRepo, WithCaseInsensitiveStatus(true))
+	mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Status: "pending"}, nil)
+	mockRepo.On("Update", mock.MatchedBy(func(task *models.Task) bool { return task.Status == "in_progress" })).Return(nil)
+	mockRepo.On("AddAuditEntry", mock.MatchedBy(func(e *models.AuditEntry) bool { return e.ToStatus == "in_progress" })).Return(nil)
+
+	// Act
+	task, err := service.TransitionTask(1, &models.TransitionTaskRequest{To: "In_Progress"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "in_progress", task.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransitionTask_Rejected(t *testing.T) {
+	linear := Transitions{"pending": {"in_progress"}, "in_progress": {"completed"}, "completed": {}}
+	cases := map[string]struct {
+		req  models.TransitionTaskRequest
+		want error
+	}{
+		"unknown status":      {models.TransitionTaskRequest{To: "done"}, ErrInvalidTransitionRequest},
+		"missing status":      {models.TransitionTaskRequest{}, ErrInvalidTransitionRequest},
+		"note too long":       {models.TransitionTaskRequest{To: "in_progress", Note: strings.Repeat("n", MaxTransitionNoteLength+1)}, ErrInvalidTransitionRequest},
+		"actor too long":      {models.TransitionTaskRequest{To: "in_progress", Actor: strings.Repeat("a", MaxActorLength+1)}, ErrInvalidTransitionRequest},
+		"same status":         {models.TransitionTaskRequest{To: "pending"}, ErrInvalidTransition},
+		"workflow forbids it": {models.TransitionTaskRequest{To: "completed"}, ErrInvalidTransition},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTaskRepository)
+			service := NewTaskService(mockRepo, WithTransitions(linear))
+			mockRepo.On("GetByID", 1).Return(&models.Task{ID: 1, Title: "T", Status: "pending"}, nil)
+
+			// Act
+			task, err := service.TransitionTask(1, &tc.req)
+
+			// Assert
+			assert.Nil(t, task)
+			assert.ErrorIs(t, err, tc.want)
+			mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+			mockRepo.AssertNotCalled(t, "AddAuditEntry", mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"errors"
+	"expvar"
+	"time"
+)
+
+// ErrTooManyTransactions is returned when a write needing a transaction can't get a slot under
+// WithMaxConcurrentTransactions
+var ErrTooManyTransactions = errors.New("too many concurrent writes, retry later")
+
+// Transaction slot metrics, published through expvar
+var (
+	// transactionsInFlight is the number of limited operations currently holding a slot
+	transactionsInFlight = expvar.NewInt("service_transactions_in_flight")
+	// transactionsRejected counts operations refused because no slot freed up in time
+	transactionsRejected = expvar.NewInt("service_transactions_rejected_total")
+)
+
+// txLimiter is a counting semaphore over the service's transactional operations
+type txLimiter struct {
+	slots chan struct{}
+	wait  time.Duration // how long to queue for a slot; zero rejects straight away
+}
+
+// WithMaxConcurrentTransactions lets at most max transactional operations (updates, patches,
+// transitions, reassignments and the like) run at once, so a burst of them can't take every
+// connection in the pool from cheap reads. An operation arriving while all slots are taken
+// waits up to wait for one, then fails with ErrTooManyTransactions; a wait of zero fails it
+// straight away. A max of zero or less disables the limit.
+func WithMaxConcurrentTransactions(max int, wait time.Duration) Option {
+	return func(s *taskService) {
+		if max <= 0 {
+			s.txLimit = nil
+			return
+		}
+		if wait < 0 {
+			wait = 0
+		}
+		s.txLimit = &txLimiter{slots: make(chan struct{}, max), wait: wait}
+	}
+}
+
+// acquire takes a slot, returning the function that gives it back
+func (l *txLimiter) acquire() (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+	default:
+		if !l.queue() {
+			transactionsRejected.Add(1)
+			return nil, ErrTooManyTransactions
+		}
+	}
+	transactionsInFlight.Add(1)
+	return func() {
+		transactionsInFlight.Add(-1)
+		<-l.slots
+	}, nil
+}
+
+// queue waits up to l.wait for a slot, reporting whether it got one
+func (l *txLimiter) queue() bool {
+	if l.wait <= 0 {
+		return false
+	}
+	timer := time.NewTimer(l.wait)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cliffdoyle/task-api/internal/models"
+	"github.com/cliffdoyle/task-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingTxRepo holds every transaction open until release is closed, signalling entered as
+// each one starts
+type blockingTxRepo struct {
+	*MockTaskRepository
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newBlockingTxRepo() *blockingTxRepo {
+	return &blockingTxRepo{MockTaskRepository: new(MockTaskRepository), entered: make(chan struct{}, 10), release: make(chan struct{})}
+}
+
+func (r *blockingTxRepo) WithTransaction(ctx context.Context, fn func(repo repository.TaskRepository) error) error {
+	r.entered <- struct{}{}
+	<-r.release
+	return fn(r)
+}
+
+// holdTx starts a transaction on svc in the background and waits until it is running
+func holdTx(t *testing.T, svc *taskService, repo *blockingTxRepo) <-chan error {
+	done := make(chan error, 1)
+	go func() { done <- svc.withTx(noop) }()
+	select {
+	case <-repo.entered:
+	case <-time.After(time.Second):
+		t.Fatal("transaction never started")
+	}
+	return done
+}
+
+func noop(repository.TaskRepository) error { return nil }
+
+func TestMaxConcurrentTransactions_RejectsAtLimit(t *testing.T) {
+	// Arrange
+	repo := newBlockingTxRepo()
+	svc := NewTaskService(repo, WithMaxConcurrentTransactions(1, 0)).(*taskService)
+	inFlight, rejected := transactionsInFlight.Value(), transactionsRejected.Value()
+	done := holdTx(t, svc, repo)
+
+	// Act
+	err := svc.withTx(noop)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrTooManyTransactions)
+	assert.Equal(t, inFlight+1, transactionsInFlight.Value())
+	assert.Equal(t, rejected+1, transactionsRejected.Value())
+
+	close(repo.release)
+	require.NoError(t, <-done)
+	assert.Equal(t, inFlight, transactionsInFlight.Value(), "the slot is given back")
+	assert.NoError(t, svc.withTx(noop))
+}
+
+func TestMaxConcurrentTransactions_Queues(t *testing.T) {
+	// Arrange
+	repo := newBlockingTxRepo()
+	svc := NewTaskService(repo, WithMaxConcurrentTransactions(1, time.Second)).(*taskService)
+	done := holdTx(t, svc, repo)
+
+	// Act: the second transaction waits for the first to finish
+	queued := make(chan error, 1)
+	go func() { queued <- svc.withTx(noop) }()
+	time.Sleep(20 * time.Millisecond)
+	close(repo.release)
+
+	// Assert
+	assert.NoError(t, <-done)
+	assert.NoError(t, <-queued)
+}
+
+func TestMaxConcurrentTransactions_QueueTimesOut(t *testing.T) {
+	// Arrange
+	repo := newBlockingTxRepo()
+	svc := NewTaskService(repo, WithMaxConcurrentTransactions(1, 10*time.Millisecond)).(*taskService)
+	done := holdTx(t, svc, repo)
+	defer func() { close(repo.release); <-done }()
+
+	// Act
+	err := svc.withTx(noop)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrTooManyTransactions)
+}
+
+func TestMaxConcurrentTransactions_Disabled(t *testing.T) {
+	// Arrange
+	repo := newBlockingTxRepo()
+	svc := NewTaskService(repo, WithMaxConcurrentTransactions(0, 0)).(*taskService)
+
+	// Act: several transactions are open at once
+	var dones []<-chan error
+	for i := 0; i < 3; i++ {
+		dones = append(dones, holdTx(t, svc, repo))
+	}
+	close(repo.release)
+
+	// Assert
+	for _, done := range dones {
+		assert.NoError(t, <-done)
+	}
+}
+
+func TestReassignTasks_TakesTransactionSlot(t *testing.T) {
+	// Arrange
+	repo := newBlockingTxRepo()
+	svc := NewTaskService(repo, WithMaxConcurrentTransactions(1, 0)).(*taskService)
+	done := holdTx(t, svc, repo)
+	defer func() { close(repo.release); <-done }()
+
+	// Act
+	_, err := svc.ReassignTasks(&models.ReassignTasksRequest{From: "alice", To: "bob"})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrTooManyTransactions)
+	repo.AssertNotCalled(t, "Reassign", "alice", "bob")
+}
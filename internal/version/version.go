@@ -0,0 +1,13 @@
+// Package version holds build metadata injected at link time, e.g.
+//
+//	go build -ldflags "-X github.com/cliffdoyle/task-api/internal/version.Version=1.2.0 \
+//	  -X github.com/cliffdoyle/task-api/internal/version.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/cliffdoyle/task-api/internal/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+// Set via -ldflags; the defaults identify a local, non-release build
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
@@ -8,6 +8,31 @@ DB_USER=${POSTGRES_USER:-taskapi}
 DB_PASSWORD=${POSTGRES_PASSWORD:-taskapi123}
 DB_NAME=${POSTGRES_DB:-taskapi}
 DB_HOST=${DB_HOST:-localhost} # Use localhost if running psql from host, or 'postgres' if from another container
+# When true, two live (not soft-deleted) tasks may not share a title, compared case-insensitively
+UNIQUE_TASK_TITLES=${UNIQUE_TASK_TITLES:-false}
+# Table tasks are stored in; must match the API's TASKS_TABLE. Index and constraint names are
+# derived from it, so two deployments in one schema don't collide.
+TASKS_TABLE=${TASKS_TABLE:-tasks}
+
+# The name is pasted into SQL below, so only accept what the API accepts
+if ! [[ "$TASKS_TABLE" =~ ^[a-z_][a-z0-9_]{0,39}$ ]]; then
+  >&2 echo "TASKS_TABLE must be lowercase letters, digits and underscores, up to 40 characters"
+  exit 1
+fi
+if [[ "$TASKS_TABLE" == "task" || "$TASKS_TABLE" == *_audit || "$TASKS_TABLE" == *_templates ]]; then
+  >&2 echo "TASKS_TABLE must not be task or end in _audit or _templates; those names are taken by audit and templates tables"
+  exit 1
+fi
+
+# The audit log and templates belong to one deployment, so they are named after its table as
+# repository.AuditTableName and TemplatesTableName do. The default table keeps the old names.
+if [ "$TASKS_TABLE" = "tasks" ]; then
+  AUDIT_TABLE=task_audit
+  TEMPLATES_TABLE=task_templates
+else
+  AUDIT_TABLE=${TASKS_TABLE}_audit
+  TEMPLATES_TABLE=${TASKS_TABLE}_templates
+fi
 
 echo "Attempting to connect to PostgreSQL at $DB_HOST for database setup..."
 
@@ -21,17 +46,145 @@ done
 >&2 echo "Postgres is up - executing schema setup"
 
 PGPASSWORD=$DB_PASSWORD psql -h "$DB_HOST" -U "$DB_USER" -d "$DB_NAME" << EOF
-CREATE TABLE IF NOT EXISTS tasks (
+CREATE TABLE IF NOT EXISTS ${TASKS_TABLE} (
     id SERIAL PRIMARY KEY,
     title VARCHAR(255) NOT NULL,
     description TEXT,
     status VARCHAR(50) NOT NULL DEFAULT 'pending',
-    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
-    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+    metadata JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    claimed_by VARCHAR(255),
+    lease_expires_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    reopen_reason TEXT,
+    assignee VARCHAR(255),
+    due_date DATE,
+    uuid UUID,
+    priority VARCHAR(20) NOT NULL DEFAULT 'medium'
+);
+
+-- Columns added after the initial schema; safe to re-run against existing databases
+ALTER TABLE ${TASKS_TABLE} ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE ${TASKS_TABLE} ADD COLUMN IF NOT EXISTS claimed_by VARCHAR(255);
+ALTER TABLE ${TASKS_TABLE} ADD COLUMN IF NOT EXISTS lease_expires_at TIMESTAMPTZ;
+ALTER TABLE ${TASKS_TABLE} ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE ${TASKS_TABLE} ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ;
+ALTER TABLE ${TASKS_TABLE} ADD COLUMN IF NOT EXISTS reopen_reason TEXT;
+ALTER TABLE ${TASKS_TABLE} ADD COLUMN IF NOT EXISTS assignee VARCHAR(255);
+ALTER TABLE ${TASKS_TABLE} ADD COLUMN IF NOT EXISTS due_date DATE;
+ALTER TABLE ${TASKS_TABLE} ADD COLUMN IF NOT EXISTS uuid UUID;
+ALTER TABLE ${TASKS_TABLE} ADD COLUMN IF NOT EXISTS started_at TIMESTAMPTZ;
+ALTER TABLE ${TASKS_TABLE} ADD COLUMN IF NOT EXISTS priority VARCHAR(20) NOT NULL DEFAULT 'medium';
+
+-- One row per audited change to a task, written in the same transaction as the change.
+-- actor is whoever the X-Actor header named; it is NULL when the request didn't say.
+CREATE TABLE IF NOT EXISTS ${AUDIT_TABLE} (
+    id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES ${TASKS_TABLE}(id) ON DELETE CASCADE,
+    actor VARCHAR(255),
+    action VARCHAR(50) NOT NULL,
+    from_status VARCHAR(50),
+    to_status VARCHAR(50),
+    note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 );
+CREATE INDEX IF NOT EXISTS idx_${AUDIT_TABLE}_task ON ${AUDIT_TABLE} (task_id, created_at);
+-- Serves ?updated_by=: an actor's changes in a time range, with task_id for an index-only scan
+CREATE INDEX IF NOT EXISTS idx_${AUDIT_TABLE}_actor ON ${AUDIT_TABLE} (actor, created_at, task_id);
 
-CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
-CREATE INDEX IF NOT EXISTS idx_tasks_created_at ON tasks(created_at);
+-- Reusable defaults for POST /api/tasks/from-template/{templateId}. title may contain {date}.
+CREATE TABLE IF NOT EXISTS ${TEMPLATES_TABLE} (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    metadata JSONB NOT NULL DEFAULT '{}',
+    assignee VARCHAR(255),
+    due_in_days INTEGER,
+    priority VARCHAR(20),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+ALTER TABLE ${TEMPLATES_TABLE} ADD COLUMN IF NOT EXISTS priority VARCHAR(20);
+
+-- The repository assigns a UUID to every new task; give older rows one too (PostgreSQL 13+)
+UPDATE ${TASKS_TABLE} SET uuid = gen_random_uuid() WHERE uuid IS NULL;
+
+-- Older databases stored timestamps without a zone; they were written as UTC, so convert them
+-- to TIMESTAMPTZ on that basis. Guarded so re-running doesn't shift already converted columns.
+DO \$\$
+DECLARE
+    col TEXT;
+BEGIN
+    FOREACH col IN ARRAY ARRAY['created_at', 'updated_at', 'lease_expires_at'] LOOP
+        IF EXISTS (
+            SELECT 1 FROM information_schema.columns
+            WHERE table_name = '${TASKS_TABLE}' AND column_name = col AND data_type = 'timestamp without time zone'
+        ) THEN
+            EXECUTE format('ALTER TABLE ${TASKS_TABLE} ALTER COLUMN %I TYPE TIMESTAMPTZ USING %I AT TIME ZONE ''UTC''', col, col);
+        END IF;
+    END LOOP;
+END
+\$\$;
+
+-- Cap descriptions at 10000 characters; the repository maps violations to ErrDescriptionTooLong (400).
+-- NOT VALID enforces it on every write from now on without failing on existing long rows.
+DO \$\$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = '${TASKS_TABLE}_description_length') THEN
+        ALTER TABLE ${TASKS_TABLE} ADD CONSTRAINT ${TASKS_TABLE}_description_length CHECK (char_length(description) <= 10000) NOT VALID;
+    END IF;
+END
+\$\$;
+
+-- Indexes for list filters and ordering. Each one backs a query the API actually runs;
+-- tests/integration/indexes_test.go checks with EXPLAIN that the planner can use them.
+-- Equality filter on status (and the claim/reclaim queue scans)
+CREATE INDEX IF NOT EXISTS idx_${TASKS_TABLE}_status ON ${TASKS_TABLE}(status);
+-- ORDER BY created_at with id as tiebreaker, so keyset pagination can seek instead of sort.
+-- It supersedes the old single-column index, which is dropped to save the write cost.
+CREATE INDEX IF NOT EXISTS idx_${TASKS_TABLE}_created_at_id ON ${TASKS_TABLE}(created_at, id);
+DROP INDEX IF EXISTS idx_${TASKS_TABLE}_created_at;
+-- Supports the ? key-existence operator used by metadata filters
+CREATE INDEX IF NOT EXISTS idx_${TASKS_TABLE}_metadata ON ${TASKS_TABLE} USING GIN (metadata);
+-- Keyset pagination for GET /api/tasks/changes
+CREATE INDEX IF NOT EXISTS idx_${TASKS_TABLE}_updated_at ON ${TASKS_TABLE}(updated_at, id);
+-- Lets POST /api/tasks/claim find the oldest pending task without scanning
+CREATE INDEX IF NOT EXISTS idx_${TASKS_TABLE}_pending_queue ON ${TASKS_TABLE}(created_at, id) WHERE status = 'pending';
+-- Resolves /api/tasks/{uuid} when ID_MODE=uuid, and keeps UUIDs unique
+CREATE UNIQUE INDEX IF NOT EXISTS idx_${TASKS_TABLE}_uuid ON ${TASKS_TABLE}(uuid);
+-- Title lookups for POST /api/tasks?if_not_exists=true, which match trimmed and case-insensitively
+CREATE INDEX IF NOT EXISTS idx_${TASKS_TABLE}_title_normalized ON ${TASKS_TABLE} (lower(btrim(title))) WHERE deleted_at IS NULL;
+-- Equality filter on ?assignee=<name>
+CREATE INDEX IF NOT EXISTS idx_${TASKS_TABLE}_assignee ON ${TASKS_TABLE}(assignee);
+-- Range scan for the completed counts in GET /api/tasks/metrics/daily (created counts use
+-- idx_${TASKS_TABLE}_created_at_id)
+CREATE INDEX IF NOT EXISTS idx_${TASKS_TABLE}_completed_at ON ${TASKS_TABLE}(completed_at) WHERE completed_at IS NOT NULL;
+-- Due dates of open tasks, for the calendar feed (due_date IS NOT NULL) and priority escalation
+-- (due_date < today). Both only read live tasks that aren't completed, which over time are a small
+-- share of the table, so the index leaves the rest out. The queries state status <> 'completed'
+-- so the planner can match the predicate.
+CREATE INDEX IF NOT EXISTS idx_${TASKS_TABLE}_due_date_open ON ${TASKS_TABLE}(due_date) WHERE status <> 'completed' AND deleted_at IS NULL;
 EOF
 
+# Title uniqueness is a partial index so soft-deleted tasks don't block reusing their title.
+# The repository maps violations of idx_${TASKS_TABLE}_title_unique to ErrDuplicateTask (409).
+# Titles are compared as lower(btrim(title)), the same normalization if_not_exists looks them up
+# by, so "Foo " and "foo" are one title for both. Older setups indexed lower(title); that index
+# is dropped first so it is rebuilt on the trimmed expression.
+if [ "$UNIQUE_TASK_TITLES" = "true" ]; then
+  UNIQUE_TITLE_SQL="DO \$\$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = 'idx_${TASKS_TABLE}_title_unique' AND indexdef NOT LIKE '%btrim%') THEN
+        DROP INDEX idx_${TASKS_TABLE}_title_unique;
+    END IF;
+END
+\$\$;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_${TASKS_TABLE}_title_unique ON ${TASKS_TABLE} (lower(btrim(title))) WHERE deleted_at IS NULL;"
+else
+  UNIQUE_TITLE_SQL="DROP INDEX IF EXISTS idx_${TASKS_TABLE}_title_unique;"
+fi
+PGPASSWORD=$DB_PASSWORD psql -h "$DB_HOST" -U "$DB_USER" -d "$DB_NAME" -c "$UNIQUE_TITLE_SQL"
+
 echo "Database setup complete!"
\ No newline at end of file